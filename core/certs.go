@@ -0,0 +1,329 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// CertConfig is a client certificate (mutual TLS) plus an optional CA
+// bundle. It lives on a request's Settings, or in the host store where Host
+// picks which requests it applies to. The zero value means "none".
+type CertConfig struct {
+	Host       string `json:"Host,omitempty"`     // host store only: "api.example.com", "*.example.com", optional ":port"
+	CertFile   string `json:"CertFile,omitempty"` // PEM certificate; may also hold the key
+	KeyFile    string `json:"KeyFile,omitempty"`  // PEM key; empty → looked up in CertFile
+	PFXFile    string `json:"PFXFile,omitempty"`  // PKCS#12 bundle, used instead of CertFile/KeyFile
+	Passphrase string `json:"Passphrase,omitempty"`
+	CAFile     string `json:"CAFile,omitempty"` // extra trusted roots, PEM
+}
+
+// IsZero reports whether no file is configured. Host alone doesn't count.
+func (c CertConfig) IsZero() bool {
+	return c.CertFile == "" && c.KeyFile == "" && c.PFXFile == "" && c.CAFile == ""
+}
+
+// SetCertificate routes a certificate path by extension: .p12/.pfx are
+// PKCS#12 bundles, anything else is PEM.
+func (c *CertConfig) SetCertificate(path string) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".p12", ".pfx":
+		c.PFXFile, c.CertFile = path, ""
+	default:
+		c.CertFile, c.PFXFile = path, ""
+	}
+}
+
+// Certificate is the configured certificate path, whichever kind it is.
+func (c CertConfig) Certificate() string {
+	if c.PFXFile != "" {
+		return c.PFXFile
+	}
+	return c.CertFile
+}
+
+// CertStore is the per-host certificate list, persisted next to the
+// environments. The first matching entry wins.
+type CertStore struct {
+	Hosts []*CertConfig `json:"Hosts"`
+}
+
+var (
+	certMu    sync.RWMutex
+	hostCerts []*CertConfig
+)
+
+// SetHostCerts swaps the per-host certificates SendRequest falls back to
+// when a request has none of its own.
+func SetHostCerts(certs []*CertConfig) {
+	certMu.Lock()
+	hostCerts = certs
+	certMu.Unlock()
+}
+
+// hostCert returns the first host-store entry matching hostport.
+func hostCert(hostport string) *CertConfig {
+	certMu.RLock()
+	defer certMu.RUnlock()
+
+	for _, c := range hostCerts {
+		if c.Host != "" && hostMatches(c.Host, hostport) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// hostMatches compares a host pattern against a request's host[:port]. A
+// pattern without a port matches any port; "*." matches one or more labels.
+func hostMatches(pattern, hostport string) bool {
+	host, port := hostport, ""
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		host, port = h, p
+	}
+
+	patHost, patPort := pattern, ""
+	if h, p, err := net.SplitHostPort(pattern); err == nil {
+		patHost, patPort = h, p
+	}
+
+	if patPort != "" && patPort != port {
+		return false
+	}
+
+	host, patHost = strings.ToLower(host), strings.ToLower(patHost)
+	if rest, ok := strings.CutPrefix(patHost, "*."); ok {
+		return strings.HasSuffix(host, "."+rest)
+	}
+
+	return host == patHost
+}
+
+// certFor resolves the certificate config for a request to hostport: the
+// request's own settings win, then the host store.
+func certFor(s Settings, hostport string) CertConfig {
+	if !s.ClientCert.IsZero() {
+		return s.ClientCert
+	}
+	if c := hostCert(hostport); c != nil {
+		return *c
+	}
+	return CertConfig{}
+}
+
+// tlsConfig builds the client TLS config for hostport, nil when the
+// defaults apply (verify against system roots, no client certificate).
+func tlsConfig(s Settings, hostport string) (*tls.Config, error) {
+	c := certFor(s, hostport)
+	if c.IsZero() && !s.SkipTLSVerify {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: s.SkipTLSVerify}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(ApplyEnv(c.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("CA bundle: %w", err)
+		}
+
+		// system roots plus the bundle, so public hosts keep working
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA bundle: no PEM certificates found")
+		}
+		cfg.RootCAs = pool
+	}
+
+	cert, err := c.loadClientCert()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		cfg.Certificates = []tls.Certificate{*cert}
+	}
+
+	return cfg, nil
+}
+
+// loadClientCert reads the PKCS#12 bundle or PEM pair; nil when neither is
+// configured.
+func (c CertConfig) loadClientCert() (*tls.Certificate, error) {
+	passphrase := ApplyEnv(c.Passphrase)
+
+	if c.PFXFile != "" {
+		data, err := os.ReadFile(ApplyEnv(c.PFXFile))
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+
+		key, leaf, chain, err := pkcs12.DecodeChain(data, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+
+		cert := &tls.Certificate{PrivateKey: key, Leaf: leaf, Certificate: [][]byte{leaf.Raw}}
+		for _, ca := range chain {
+			cert.Certificate = append(cert.Certificate, ca.Raw)
+		}
+		return cert, nil
+	}
+
+	if c.CertFile == "" {
+		return nil, nil
+	}
+
+	certPath := ApplyEnv(c.CertFile)
+	keyPath := certPath // combined PEM files carry both blocks
+	if c.KeyFile != "" {
+		keyPath = ApplyEnv(c.KeyFile)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("client certificate: %w", err)
+	}
+
+	return &cert, nil
+}
+
+// TLSInfo describes the TLS session a response arrived over; nil on the
+// Response for plain http.
+type TLSInfo struct {
+	Version     string
+	CipherSuite string
+	ServerCerts []CertInfo // leaf first, as presented by the server
+	ClientCert  *CertInfo  // the certificate we offered; nil when none configured
+}
+
+// CertInfo is the human-relevant part of an x509 certificate.
+type CertInfo struct {
+	Subject   string
+	Issuer    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	DNSNames  []string
+}
+
+func certInfo(c *x509.Certificate) CertInfo {
+	return CertInfo{
+		Subject:   c.Subject.String(),
+		Issuer:    c.Issuer.String(),
+		NotBefore: c.NotBefore,
+		NotAfter:  c.NotAfter,
+		DNSNames:  c.DNSNames,
+	}
+}
+
+// newTLSInfo summarises a finished handshake. cfg is the config the
+// transport dialled with, so the offered client certificate can be named.
+func newTLSInfo(state *tls.ConnectionState, cfg *tls.Config) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	for _, c := range state.PeerCertificates {
+		info.ServerCerts = append(info.ServerCerts, certInfo(c))
+	}
+
+	if cfg != nil && len(cfg.Certificates) > 0 {
+		cert := cfg.Certificates[0]
+		leaf := cert.Leaf
+		if leaf == nil && len(cert.Certificate) > 0 {
+			leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+		}
+		if leaf != nil {
+			ci := certInfo(leaf)
+			info.ClientCert = &ci
+		}
+	}
+
+	return info
+}
+
+// LoadCertStore reads the per-host certificates, passphrases from the
+// vault; empty store on any error.
+func LoadCertStore() *CertStore {
+	store := &CertStore{}
+
+	file, err := configFile("certificates.json")
+	if err != nil {
+		return store
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return store
+	}
+
+	json.Unmarshal(content, store)
+	store.FillSecrets()
+
+	return store
+}
+
+// FillSecrets restores passphrases from the vault, keeping any typed while
+// it was locked. LoadCertStore already does this.
+func (s *CertStore) FillSecrets() {
+	for i, c := range s.Hosts {
+		if pass, ok := vaultGet(hostCertKey(i)); ok && c.Passphrase == "" {
+			c.Passphrase = pass
+		}
+	}
+}
+
+func hostCertKey(i int) string {
+	return "cert/" + strconv.Itoa(i)
+}
+
+// SaveCertStore persists the host store, PKCS#12 passphrases to the vault.
+// A locked vault still writes the file, then reports the passphrases
+// weren't stored.
+func SaveCertStore(store *CertStore) error {
+	file, err := configFile("certificates.json")
+
+	if err != nil {
+		return err
+	}
+
+	saved := &CertStore{Hosts: make([]*CertConfig, 0, len(store.Hosts))}
+	passes := map[string]string{}
+	for i, c := range store.Hosts {
+		sc := *c
+		passes[hostCertKey(i)] = sc.Passphrase
+		sc.Passphrase = ""
+		saved.Hosts = append(saved.Hosts, &sc)
+	}
+	vaultErr := vaultReplace("cert/", passes)
+
+	data, err := json.Marshal(saved)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+
+	return vaultErr
+}
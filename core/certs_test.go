@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostMatches(t *testing.T) {
+	cases := []struct {
+		pattern, hostport string
+		want              bool
+	}{
+		{"api.example.com", "api.example.com", true},
+		{"api.example.com", "API.example.com:443", true}, // no port in pattern → any port
+		{"api.example.com:8443", "api.example.com:443", false},
+		{"api.example.com:8443", "api.example.com:8443", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"example.com", "notexample.com", false},
+	}
+	for _, c := range cases {
+		if got := hostMatches(c.pattern, c.hostport); got != c.want {
+			t.Errorf("hostMatches(%q, %q) = %v, want %v", c.pattern, c.hostport, got, c.want)
+		}
+	}
+}
+
+// writeTestCert creates a self-signed ECDSA cert+key as PEM files in dir.
+func writeTestCert(t *testing.T, dir, cn string) (certPath, keyPath string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPath = filepath.Join(dir, cn+".crt")
+	keyPath = filepath.Join(dir, cn+".key")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	return certPath, keyPath, cert
+}
+
+func TestSendRequestClientCert(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, clientCert := writeTestCert(t, dir, "myapi-client")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+
+	var gotCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCN = r.TLS.PeerCertificates[0].Subject.CommonName
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	// The server's cert as a CA bundle instead of SkipTLSVerify
+	caPath := filepath.Join(dir, "server-ca.pem")
+	os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+
+	// No certificate: the handshake is refused
+	req := testRequest("mtlsnone", server.URL)
+	req.Settings.ClientCert = CertConfig{CAFile: caPath}
+	if _, err := req.SendRequest(context.Background()); err == nil {
+		t.Fatal("server requires a client cert; send without one should fail")
+	}
+
+	// Per-request certificate
+	req = testRequest("mtlsreq", server.URL)
+	req.Settings.ClientCert = CertConfig{CertFile: certPath, KeyFile: keyPath, CAFile: caPath}
+	defer DeleteHistory("mtlsreq")
+	res, err := req.SendRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotCN != "myapi-client" {
+		t.Fatalf("server saw client CN %q", gotCN)
+	}
+	if res.TLS == nil || res.TLS.ClientCert == nil || !strings.Contains(res.TLS.ClientCert.Subject, "myapi-client") {
+		t.Fatalf("response TLS info missing client cert: %+v", res.TLS)
+	}
+	if res.TLS.Version == "" || len(res.TLS.ServerCerts) == 0 {
+		t.Fatalf("response TLS info incomplete: %+v", res.TLS)
+	}
+
+	// Host store fallback when the request has none of its own
+	gotCN = ""
+	SetHostCerts([]*CertConfig{{Host: "127.0.0.1", CertFile: certPath, KeyFile: keyPath, CAFile: caPath}})
+	defer SetHostCerts(nil)
+
+	req = testRequest("mtlshost", server.URL)
+	defer DeleteHistory("mtlshost")
+	if _, err := req.SendRequest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gotCN != "myapi-client" {
+		t.Fatalf("host store cert not used, server saw %q", gotCN)
+	}
+}
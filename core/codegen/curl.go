@@ -1,6 +1,8 @@
 package codegen
 
 import (
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/vardanabhanot/myapi/core"
@@ -20,6 +22,86 @@ func (g CurlGenerator) Generate(request *core.Request) string {
 		parts = append(parts, "-k")
 	}
 
+	if p := request.Settings.Proxy; p.Direct {
+		parts = append(parts, "--noproxy '*'")
+	} else if p.URL != "" {
+		parts = append(parts, "-x "+shellQuote(p.URL))
+		if p.User != "" {
+			parts = append(parts, "-U "+shellQuote(p.User+":"+p.Pass))
+		}
+		if p.Bypass != "" {
+			parts = append(parts, "--noproxy "+shellQuote(p.Bypass))
+		}
+	}
+
+	for _, o := range request.Settings.DNS.Overrides {
+		parts = append(parts, curlHostOverride(o))
+	}
+	if request.Settings.DNS.Server != "" {
+		parts = append(parts, "--dns-servers "+shellQuote(request.Settings.DNS.Server))
+	}
+
+	// --location-trusted turns on -L as well
+	if request.Settings.KeepAuthOnRedirect {
+		parts = append(parts, "--location-trusted")
+	}
+	if request.Settings.MaxRedirects > 0 {
+		parts = append(parts, "--max-redirs "+strconv.Itoa(request.Settings.MaxRedirects))
+	}
+
+	if rp := request.Settings.Retry; rp.MaxAttempts > 1 {
+		parts = append(parts, "--retry "+strconv.Itoa(rp.MaxAttempts-1))
+		if rp.BaseDelayMs > 0 {
+			parts = append(parts, "--retry-delay "+strconv.Itoa((rp.BaseDelayMs+999)/1000))
+		}
+		if !rp.StatusOnly {
+			parts = append(parts, "--retry-connrefused")
+		}
+	}
+
+	switch request.Settings.Protocol {
+	case core.ProtoHTTP1:
+		parts = append(parts, "--http1.1")
+	case core.ProtoHTTP2:
+		// curl's --http2 upgrades plain http; ours is prior knowledge
+		if strings.HasPrefix(request.URL, "http://") {
+			parts = append(parts, "--http2-prior-knowledge")
+		} else {
+			parts = append(parts, "--http2")
+		}
+	case core.ProtoHTTP3:
+		parts = append(parts, "--http3-only")
+	}
+
+	if request.Settings.Download {
+		if request.Settings.DownloadPath != "" {
+			parts = append(parts, "-o "+shellQuote(request.Settings.DownloadPath))
+		} else {
+			parts = append(parts, "-O")
+		}
+		if request.Settings.ResumeDownload {
+			parts = append(parts, "-C -")
+		}
+	}
+
+	if cc := request.Settings.ClientCert; !cc.IsZero() {
+		switch {
+		case cc.PFXFile != "":
+			parts = append(parts, "--cert-type P12", "--cert "+shellQuote(cc.PFXFile))
+		case cc.CertFile != "":
+			parts = append(parts, "--cert "+shellQuote(cc.CertFile))
+		}
+		if cc.KeyFile != "" && cc.PFXFile == "" {
+			parts = append(parts, "--key "+shellQuote(cc.KeyFile))
+		}
+		if cc.Passphrase != "" {
+			parts = append(parts, "--pass "+shellQuote(cc.Passphrase))
+		}
+		if cc.CAFile != "" {
+			parts = append(parts, "--cacert "+shellQuote(cc.CAFile))
+		}
+	}
+
 	if request.Auth != nil {
 		switch request.AuthType {
 		case "Basic":
@@ -37,7 +119,7 @@ func (g CurlGenerator) Generate(request *core.Request) string {
 		}
 	}
 
-	hasContentType := false
+	hasContentType, hasEncoding := false, false
 	if request.Headers != nil {
 		for _, h := range *request.Headers {
 			if !h.Checked || h.Key == "" {
@@ -46,10 +128,18 @@ func (g CurlGenerator) Generate(request *core.Request) string {
 			if strings.EqualFold(h.Key, "content-type") {
 				hasContentType = true
 			}
+			if strings.EqualFold(h.Key, "accept-encoding") {
+				hasEncoding = true
+			}
 			parts = append(parts, "-H "+shellQuote(h.Key+": "+h.Value))
 		}
 	}
 
+	// Sends ask for compression and decode it; curl does both with this
+	if !hasEncoding {
+		parts = append(parts, "--compressed")
+	}
+
 	// contentType mirrors what SendRequest sets for each body type
 	addBody := func(contentType, data string) {
 		if !hasContentType {
@@ -71,15 +161,29 @@ func (g CurlGenerator) Generate(request *core.Request) string {
 		if request.Body.Text != "" {
 			addBody("text/plain", request.Body.Text)
 		}
+	case "Binary":
+		if request.Body.Binary != "" {
+			if !hasContentType {
+				parts = append(parts, "-H "+shellQuote("Content-Type: "+core.BinaryContentType(request.Body.Binary, request.Body.BinaryType)))
+			}
+			parts = append(parts, "--data-binary "+shellQuote("@"+request.Body.Binary))
+		}
 	case "Form":
 		if request.Body.Form != nil {
 			for _, f := range *request.Body.Form {
-				if f.Checked && f.Key != "" {
-					value := f.Value
-					if f.IsFile {
-						value = "@" + value
-					}
-					parts = append(parts, "-F "+shellQuote(f.Key+"="+value))
+				if !f.Checked || f.Key == "" {
+					continue
+				}
+				typ := ""
+				if f.ContentType != "" {
+					typ = ";type=" + f.ContentType
+				}
+				if !f.IsFile {
+					parts = append(parts, "-F "+shellQuote(f.Key+"="+f.Value+typ))
+					continue
+				}
+				for _, path := range f.Files() {
+					parts = append(parts, "-F "+shellQuote(f.Key+"=@"+path+typ))
 				}
 			}
 		}
@@ -104,3 +208,27 @@ func shellQuote(s string) string {
 func init() {
 	Register(CurlGenerator{})
 }
+
+// curlHostOverride emits --resolve for a host:port pinned to an IP, and
+// --connect-to for everything else (any port, another host or port).
+func curlHostOverride(o core.HostOverride) string {
+	split := func(s string) (string, string) {
+		if h, p, err := net.SplitHostPort(s); err == nil {
+			return h, p
+		}
+		return strings.Trim(s, "[]"), ""
+	}
+	bracket := func(h string) string {
+		if strings.Contains(h, ":") {
+			return "[" + h + "]"
+		}
+		return h
+	}
+
+	host, port := split(o.Host)
+	addr, addrPort := split(o.Addr)
+	if port != "" && addrPort == "" && net.ParseIP(addr) != nil {
+		return "--resolve " + shellQuote(bracket(host)+":"+port+":"+bracket(addr))
+	}
+	return "--connect-to " + shellQuote(bracket(host)+":"+port+":"+bracket(addr)+":"+addrPort)
+}
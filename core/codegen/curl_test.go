@@ -1,6 +1,8 @@
 package codegen
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/vardanabhanot/myapi/core"
@@ -17,7 +19,22 @@ func TestCurlGenerateRoundTrip(t *testing.T) {
 		Body:     core.Body{Json: `{"a":"b"}`},
 		AuthType: "Basic",
 		Auth:     &core.Auth{BasicUser: "alice", BasicPass: "pw"},
-		Settings: core.Settings{SkipTLSVerify: true},
+		Settings: core.Settings{
+			SkipTLSVerify: true,
+			ClientCert:    core.CertConfig{CertFile: "client.crt", KeyFile: "client.key", CAFile: "ca.pem"},
+			Download:      true, DownloadPath: "out dir/file.bin", ResumeDownload: true,
+			Protocol: core.ProtoHTTP2,
+			Proxy:    core.ProxyConfig{URL: "socks5://proxy:1080", User: "me", Pass: "p w", Bypass: "localhost,.corp"},
+			Retry:    core.RetryPolicy{MaxAttempts: 4, BaseDelayMs: 2000},
+
+			MaxRedirects: 3, KeepAuthOnRedirect: true,
+			DNS: core.DNSConfig{Server: "10.0.0.2", Overrides: []core.HostOverride{
+				{Host: "api.example.com:443", Addr: "10.1.2.3"},
+				{Host: "api.example.com:443", Addr: "::1"},
+				{Host: "cdn.example.com", Addr: "staging.example.com:8443"},
+				{Host: "old.example.com:80", Addr: ":8080"},
+			}},
+		},
 	}
 
 	out := CurlGenerator{}.Generate(req)
@@ -39,6 +56,27 @@ func TestCurlGenerateRoundTrip(t *testing.T) {
 	if !parsed.Settings.SkipTLSVerify {
 		t.Fatal("lost -k")
 	}
+	if parsed.Settings.ClientCert != req.Settings.ClientCert {
+		t.Fatalf("client cert: %+v", parsed.Settings.ClientCert)
+	}
+	if parsed.Settings.Proxy != req.Settings.Proxy {
+		t.Fatalf("proxy: %+v", parsed.Settings.Proxy)
+	}
+	if s := parsed.Settings; s.MaxRedirects != 3 || !s.KeepAuthOnRedirect {
+		t.Fatalf("redirects: max=%d keepAuth=%v", s.MaxRedirects, s.KeepAuthOnRedirect)
+	}
+	if parsed.Settings.Retry != req.Settings.Retry {
+		t.Fatalf("retry: %+v", parsed.Settings.Retry)
+	}
+	if !reflect.DeepEqual(parsed.Settings.DNS, req.Settings.DNS) {
+		t.Fatalf("dns: %+v", parsed.Settings.DNS)
+	}
+	if parsed.Settings.Protocol != core.ProtoHTTP2 {
+		t.Fatalf("protocol: %q", parsed.Settings.Protocol)
+	}
+	if s := parsed.Settings; !s.Download || s.DownloadPath != req.Settings.DownloadPath || !s.ResumeDownload {
+		t.Fatalf("download: %+v", s)
+	}
 
 	var token string
 	for _, h := range *parsed.Headers {
@@ -50,3 +88,25 @@ func TestCurlGenerateRoundTrip(t *testing.T) {
 		t.Fatalf("header with quote lost: %q", token)
 	}
 }
+
+func TestCurlGenerateMultipartRoundTrip(t *testing.T) {
+	form := []core.FormType{
+		{Checked: true, Key: "meta", Value: `{"a":1}`, ContentType: "application/json"},
+		{Checked: true, Key: "docs", Value: "a.pdf\nb.pdf", IsFile: true, ContentType: "application/pdf"},
+	}
+	req := &core.Request{Method: "POST", URL: "https://api.example.com/upload", BodyType: "Form", Body: core.Body{Form: &form}}
+
+	out := CurlGenerator{}.Generate(req)
+	if !strings.Contains(out, "-F 'docs=@a.pdf;type=application/pdf'") || !strings.Contains(out, "-F 'docs=@b.pdf;type=application/pdf'") {
+		t.Fatalf("want one -F per file:\n%s", out)
+	}
+
+	parsed, err := core.ParseCurl(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := *parsed.Body.Form
+	if len(got) != 2 || got[0] != form[0] || got[1] != form[1] {
+		t.Fatalf("form round trip:\n got %+v\nwant %+v", got, form)
+	}
+}
@@ -3,6 +3,7 @@ package codegen
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 
@@ -38,6 +39,7 @@ func GenerateCode(language string, request *core.Request) (string, error) {
 	// runnable snippets instead of raw placeholders.
 	resolved := request.ResolveEnv()
 	normalizeAuth(resolved)
+	normalizeEncoding(resolved)
 	return gen.Generate(resolved), nil
 }
 
@@ -70,7 +72,42 @@ func normalizeAuth(r *core.Request) {
 	case "OAuth2":
 		// The real token is fetched at send time; snippets get a placeholder.
 		*r.Headers = append(*r.Headers, core.FormType{Checked: true, Key: "Authorization", Value: "Bearer YOUR_ACCESS_TOKEN"})
+	case "JWT":
+		// Signing is local, so snippets carry a real token — valid until
+		// its exp claim, like the one SendRequest would mint right now.
+		token, err := r.Auth.SignJWT()
+		if err != nil {
+			token = "YOUR_JWT"
+		}
+		inQuery, name, prefix := r.Auth.JWTPlacement()
+		if inQuery {
+			if u, err := url.Parse(r.URL); err == nil {
+				q := u.Query()
+				q.Set(name, token)
+				u.RawQuery = q.Encode()
+				r.URL = u.String()
+			}
+			return
+		}
+		if prefix != "" {
+			token = prefix + " " + token
+		}
+		*r.Headers = append(*r.Headers, core.FormType{Checked: true, Key: name, Value: token})
+	}
+}
+
+// normalizeEncoding drops Accept-Encoding rows that ask for compression.
+// Sends negotiate it anyway, and each target has its own way to: a copied
+// header would hand the snippet compressed bytes instead (Go, for one,
+// stops decoding gzip once it's set). cURL adds --compressed for it;
+// "identity" stays as written.
+func normalizeEncoding(r *core.Request) {
+	if r.Headers == nil {
+		return
 	}
+	*r.Headers = slices.DeleteFunc(*r.Headers, func(h core.FormType) bool {
+		return h.Checked && strings.EqualFold(h.Key, "Accept-Encoding") && !strings.EqualFold(strings.TrimSpace(h.Value), "identity")
+	})
 }
 
 // scriptQuote single-quotes s for JavaScript and Python — the two share
@@ -188,6 +188,20 @@ func TestGenerateCodeNormalizesAuth(t *testing.T) {
 	if !strings.Contains(out, "Authorization: Bearer YOUR_ACCESS_TOKEN") {
 		t.Errorf("OAuth2 placeholder missing:\n%s", out)
 	}
+
+	jwt := &core.Request{
+		Method:   "GET",
+		URL:      "https://x.test/a",
+		AuthType: "JWT",
+		Auth:     &core.Auth{JWTKey: "k", JWTClaims: `{"sub":"me"}`},
+	}
+	out, err = GenerateCode("cURL", jwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Authorization: Bearer eyJ") {
+		t.Errorf("signed JWT missing:\n%s", out)
+	}
 }
 
 func TestGetSupportedLanguagesSorted(t *testing.T) {
@@ -201,3 +215,62 @@ func TestGetSupportedLanguagesSorted(t *testing.T) {
 		}
 	}
 }
+
+// Every generator reads a Binary body from the file, never inlines it.
+func TestGenerateBinaryBody(t *testing.T) {
+	req := &core.Request{
+		Method:   "PUT",
+		URL:      "https://x.test/blob",
+		BodyType: "Binary",
+		Body:     core.Body{Binary: "art/logo.png"},
+	}
+
+	tests := []struct {
+		gen  CodeGenerator
+		want []string
+	}{
+		{CurlGenerator{}, []string{"-H 'Content-Type: image/png'", "--data-binary '@art/logo.png'"}},
+		{PythonGenerator{}, []string{"'Content-Type': 'image/png',", "data=open('art/logo.png', 'rb'),"}},
+		{JSGenerator{}, []string{"'Content-Type': 'image/png',", "body: fs.readFileSync('art/logo.png'),"}},
+		{PHPGenerator{}, []string{"Content-Type: image/png", "file_get_contents('art/logo.png')"}},
+		{GoGenerator{}, []string{`os.Open("art/logo.png")`, `req.Header.Set("Content-Type", "image/png")`}},
+	}
+	for _, tt := range tests {
+		out := tt.gen.Generate(req)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: missing %q in:\n%s", tt.gen.Name(), want, out)
+			}
+		}
+	}
+}
+
+// Compression is each target's to negotiate: curl gets --compressed, the
+// rest no header that would leave them printing compressed bytes.
+func TestGenerateCodeAcceptEncoding(t *testing.T) {
+	req := &core.Request{
+		Method:  "GET",
+		URL:     "https://x.test/a",
+		Headers: &[]core.FormType{{Checked: true, Key: "Accept-Encoding", Value: core.AcceptEncoding}, {Checked: true, Key: "Accept", Value: "*/*"}},
+	}
+	for _, lang := range GetSupportedLanguages() {
+		out, err := GenerateCode(lang, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(out, "Accept-Encoding") || !strings.Contains(out, "*/*") {
+			t.Errorf("%s:\n%s", lang, out)
+		}
+		if got := strings.Contains(out, "--compressed"); got != (lang == "cURL") {
+			t.Errorf("%s: --compressed = %v", lang, got)
+		}
+	}
+	if len(*req.Headers) != 2 {
+		t.Fatal("GenerateCode dropped the request's own header row")
+	}
+
+	(*req.Headers)[0].Value = "identity"
+	if out, _ := GenerateCode("cURL", req); !strings.Contains(out, "Accept-Encoding: identity") || strings.Contains(out, "--compressed") {
+		t.Errorf("identity:\n%s", out)
+	}
+}
@@ -75,23 +75,60 @@ func (g GoGenerator) Generate(request *core.Request) string {
 		if request.Body.Text != "" {
 			rawBody("text/plain", request.Body.Text)
 		}
+	case "Binary":
+		if request.Body.Binary != "" {
+			imports["os"] = true
+			setup = append(setup,
+				"body, err := os.Open("+strconv.Quote(request.Body.Binary)+")",
+				"if err != nil {",
+				"\tpanic(err)",
+				"}",
+				"defer body.Close()")
+			bodyExpr = "body"
+			if !hasContentType {
+				after = append(after, "req.Header.Set(\"Content-Type\", "+strconv.Quote(core.BinaryContentType(request.Body.Binary, request.Body.BinaryType))+")")
+			}
+		}
 	case "Form":
 		if request.Body.Form != nil {
 			var fields []string
-			fileN := 0
+			partN := 0
 			for _, f := range *request.Body.Form {
 				if !f.Checked || f.Key == "" {
 					continue
 				}
+				// A custom Content-Type needs CreatePart with its own header
+				createPart := func(n, disposition, fallback string) string {
+					if f.ContentType == "" {
+						return fallback
+					}
+					imports["net/textproto"] = true
+					fields = append(fields,
+						"h"+n+" := textproto.MIMEHeader{}",
+						"h"+n+".Set(\"Content-Disposition\", "+disposition+")",
+						"h"+n+".Set(\"Content-Type\", "+strconv.Quote(f.ContentType)+")")
+					return "part" + n + ", _ := form.CreatePart(h" + n + ")"
+				}
 				if f.IsFile {
 					imports["os"] = true
-					n := strconv.Itoa(fileN)
-					fileN++
-					fields = append(fields,
-						"part"+n+", _ := form.CreateFormFile("+strconv.Quote(f.Key)+", "+strconv.Quote(filepath.Base(f.Value))+")",
-						"file"+n+", _ := os.Open("+strconv.Quote(f.Value)+")",
-						"io.Copy(part"+n+", file"+n+")",
-						"file"+n+".Close()")
+					for _, path := range f.Files() {
+						n := strconv.Itoa(partN)
+						partN++
+						key, name := strconv.Quote(f.Key), strconv.Quote(filepath.Base(path))
+						create := createPart(n, "multipart.FileContentDisposition("+key+", "+name+")", "part"+n+", _ := form.CreateFormFile("+key+", "+name+")")
+						fields = append(fields,
+							create,
+							"file"+n+", _ := os.Open("+strconv.Quote(path)+")",
+							"io.Copy(part"+n+", file"+n+")",
+							"file"+n+".Close()")
+					}
+					continue
+				}
+				if f.ContentType != "" {
+					n := strconv.Itoa(partN)
+					partN++
+					create := createPart(n, strconv.Quote(`form-data; name="`+f.Key+`"`), "")
+					fields = append(fields, create, "io.WriteString(part"+n+", "+strconv.Quote(f.Value)+")")
 					continue
 				}
 				fields = append(fields, "form.WriteField("+strconv.Quote(f.Key)+", "+strconv.Quote(f.Value)+")")
@@ -134,9 +171,20 @@ func (g GoGenerator) Generate(request *core.Request) string {
 		imports["time"] = true
 		clientFields = append(clientFields, fmt.Sprintf("Timeout: %d * time.Second", request.Settings.TimeoutSec))
 	}
+	var transportFields []string
 	if request.Settings.SkipTLSVerify {
 		imports["crypto/tls"] = true
-		clientFields = append(clientFields, "Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}")
+		transportFields = append(transportFields, "TLSClientConfig: &tls.Config{InsecureSkipVerify: true}")
+	}
+	if p := request.Settings.Proxy; p.Direct {
+		transportFields = append(transportFields, "Proxy: nil")
+	} else if u, err := p.ProxyURL(); p.URL != "" && err == nil {
+		imports["net/url"] = true
+		setup = append(setup, "proxyURL, _ := url.Parse("+strconv.Quote(u.String())+")")
+		transportFields = append(transportFields, "Proxy: http.ProxyURL(proxyURL)")
+	}
+	if len(transportFields) > 0 {
+		clientFields = append(clientFields, "Transport: &http.Transport{"+strings.Join(transportFields, ", ")+"}")
 	}
 	if len(clientFields) > 0 {
 		setup = append(setup, "client := &http.Client{"+strings.Join(clientFields, ", ")+"}")
@@ -75,15 +75,34 @@ func (g JSGenerator) Generate(request *core.Request) string {
 		if request.Body.Text != "" {
 			rawBody("text/plain", request.Body.Text)
 		}
+	case "Binary":
+		if request.Body.Binary != "" {
+			if !hasContentType {
+				headerLines = append(headerLines, "\t\t'Content-Type': "+scriptQuote(core.BinaryContentType(request.Body.Binary, request.Body.BinaryType))+",")
+			}
+			pre = append(pre, "const fs = require('node:fs'); // file bodies need Node")
+			bodyLine = "\tbody: fs.readFileSync(" + scriptQuote(request.Body.Binary) + "),"
+		}
 	case "Form":
 		if request.Body.Form != nil {
 			var appends []string
 			hasFile := false
 			for _, f := range *request.Body.Form {
 				if f.Checked && f.Key != "" {
+					opts := ""
+					if f.ContentType != "" {
+						opts = ", { type: " + scriptQuote(f.ContentType) + " }"
+					}
 					if f.IsFile {
 						hasFile = true
-						appends = append(appends, "form.append("+scriptQuote(f.Key)+", new Blob([fs.readFileSync("+scriptQuote(f.Value)+")]), "+scriptQuote(filepath.Base(f.Value))+");")
+						for _, path := range f.Files() {
+							appends = append(appends, "form.append("+scriptQuote(f.Key)+", new Blob([fs.readFileSync("+scriptQuote(path)+")]"+opts+"), "+scriptQuote(filepath.Base(path))+");")
+						}
+						continue
+					}
+					if opts != "" {
+						// Only a Blob carries a type; FormData then names it "blob"
+						appends = append(appends, "form.append("+scriptQuote(f.Key)+", new Blob(["+scriptQuote(f.Value)+"]"+opts+"));")
 						continue
 					}
 					appends = append(appends, "form.append("+scriptQuote(f.Key)+", "+scriptQuote(f.Value)+");")
@@ -123,6 +142,9 @@ func (g JSGenerator) Generate(request *core.Request) string {
 	if request.Settings.SkipTLSVerify {
 		out = append(out, "// note: fetch cannot skip TLS verification (curl -k)")
 	}
+	if request.Settings.Proxy.URL != "" && !request.Settings.Proxy.Direct {
+		out = append(out, "// note: fetch has no proxy option; Node reads HTTPS_PROXY with NODE_USE_ENV_PROXY=1")
+	}
 	out = append(out, pre...)
 
 	if len(opts) == 0 {
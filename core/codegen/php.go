@@ -1,6 +1,7 @@
 package codegen
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/vardanabhanot/myapi/core"
@@ -28,6 +29,18 @@ curl_setopt($ch, CURLOPT_RETURNTRANSFER, true);`)
 curl_setopt($ch, CURLOPT_SSL_VERIFYHOST, 0);`)
 	}
 
+	if p := request.Settings.Proxy; p.Direct {
+		parts = append(parts, "curl_setopt($ch, CURLOPT_NOPROXY, '*');")
+	} else if p.URL != "" {
+		parts = append(parts, "curl_setopt($ch, CURLOPT_PROXY, "+phpQuote(p.URL)+");")
+		if p.User != "" {
+			parts = append(parts, "curl_setopt($ch, CURLOPT_PROXYUSERPWD, "+phpQuote(p.User+":"+p.Pass)+");")
+		}
+		if p.Bypass != "" {
+			parts = append(parts, "curl_setopt($ch, CURLOPT_NOPROXY, "+phpQuote(p.Bypass)+");")
+		}
+	}
+
 	if request.Auth != nil && request.AuthType == "Basic" && request.Auth.BasicUser != "" {
 		parts = append(parts, "curl_setopt($ch, CURLOPT_USERPWD, "+phpQuote(request.Auth.BasicUser+":"+request.Auth.BasicPass)+");")
 	}
@@ -77,16 +90,38 @@ curl_setopt($ch, CURLOPT_SSL_VERIFYHOST, 0);`)
 		if request.Body.Text != "" {
 			rawBody("text/plain", request.Body.Text)
 		}
+	case "Binary":
+		if request.Body.Binary != "" {
+			if !hasContentType {
+				headerLines = append(headerLines, "Content-Type: "+core.BinaryContentType(request.Body.Binary, request.Body.BinaryType))
+			}
+			bodyPart = "curl_setopt($ch, CURLOPT_POSTFIELDS, file_get_contents(" + phpQuote(request.Body.Binary) + "));"
+		}
 	case "Form", "URL Encoded":
 		if request.Body.Form != nil {
 			var fields []string
 			for _, f := range *request.Body.Form {
 				if f.Checked && f.Key != "" {
 					if f.IsFile {
-						if request.BodyType == "Form" {
-							fields = append(fields, "\t"+phpQuote(f.Key)+" => new CURLFile("+phpQuote(f.Value)+"),")
+						if request.BodyType != "Form" {
+							continue // urlencoded can't carry files
+						}
+						mime := ""
+						if f.ContentType != "" {
+							mime = ", " + phpQuote(f.ContentType)
+						}
+						// PHP arrays can't repeat a key, so several files
+						// go as key[0], key[1]… which PHP-style servers read
+						// back as an array.
+						paths := f.Files()
+						for i, path := range paths {
+							key := f.Key
+							if len(paths) > 1 {
+								key += "[" + strconv.Itoa(i) + "]"
+							}
+							fields = append(fields, "\t"+phpQuote(key)+" => new CURLFile("+phpQuote(path)+mime+"),")
 						}
-						continue // urlencoded can't carry files
+						continue
 					}
 					fields = append(fields, "\t"+phpQuote(f.Key)+" => "+phpQuote(f.Value)+",")
 				}
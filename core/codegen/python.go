@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/vardanabhanot/myapi/core"
@@ -71,22 +72,38 @@ func (g PythonGenerator) Generate(request *core.Request) string {
 		if request.Body.Text != "" {
 			rawBody("text/plain", request.Body.Text)
 		}
+	case "Binary":
+		if request.Body.Binary != "" {
+			if !hasContentType {
+				headerLines = append(headerLines, "\t\t'Content-Type': "+scriptQuote(core.BinaryContentType(request.Body.Binary, request.Body.BinaryType))+",")
+			}
+			// A file object streams; requests sets Content-Length from it
+			bodyArg = "\tdata=open(" + scriptQuote(request.Body.Binary) + ", 'rb'),"
+		}
 	case "Form":
 		if request.Body.Form != nil {
 			var fields []string
 			for _, f := range *request.Body.Form {
-				if f.Checked && f.Key != "" {
-					if f.IsFile {
-						fields = append(fields, "\t\t"+scriptQuote(f.Key)+": open("+scriptQuote(f.Value)+", 'rb'),")
-						continue
+				if !f.Checked || f.Key == "" {
+					continue
+				}
+				typ := ""
+				if f.ContentType != "" {
+					typ = ", " + scriptQuote(f.ContentType)
+				}
+				if f.IsFile {
+					for _, path := range f.Files() {
+						fields = append(fields, "\t\t("+scriptQuote(f.Key)+", ("+scriptQuote(filepath.Base(path))+", open("+scriptQuote(path)+", 'rb')"+typ+")),")
 					}
-					// (None, value) is the requests idiom for a plain
-					// multipart field without a filename
-					fields = append(fields, "\t\t"+scriptQuote(f.Key)+": (None, "+scriptQuote(f.Value)+"),")
+					continue
 				}
+				// (None, value) is the requests idiom for a plain
+				// multipart field without a filename
+				fields = append(fields, "\t\t("+scriptQuote(f.Key)+", (None, "+scriptQuote(f.Value)+typ+")),")
 			}
 			if len(fields) > 0 {
-				bodyArg = "\tfiles={\n" + strings.Join(fields, "\n") + "\n\t},"
+				// A list, not a dict: one key can carry several files
+				bodyArg = "\tfiles=[\n" + strings.Join(fields, "\n") + "\n\t],"
 			}
 		}
 	case "URL Encoded":
@@ -118,6 +135,11 @@ func (g PythonGenerator) Generate(request *core.Request) string {
 	if request.Settings.TimeoutSec > 0 {
 		args = append(args, fmt.Sprintf("\ttimeout=%d,", request.Settings.TimeoutSec))
 	}
+	if p := request.Settings.Proxy; p.URL != "" && !p.Direct {
+		if u, err := p.ProxyURL(); err == nil {
+			args = append(args, "\tproxies={'http': "+scriptQuote(u.String())+", 'https': "+scriptQuote(u.String())+"},")
+		}
+	}
 
 	return "import requests\n\nresponse = requests." + strings.ToLower(request.Method) + "(\n" +
 		strings.Join(args, "\n") + "\n)\nprint(response.text)"
@@ -20,7 +20,18 @@ type Collection struct {
 func (c *Collection) UpdateRequest(entry *Request, from *Request) bool {
 	for _, r := range c.Requests {
 		if r == entry {
+			// Keep the entry's own vault slots; Clone clears the SecretRefs.
+			var ref string
+			if entry.Auth != nil {
+				ref = entry.Auth.SecretRef
+			}
+			settingsRef := entry.Settings.SecretRef
+
 			*entry = *from.Clone()
+			if entry.Auth != nil {
+				entry.Auth.SecretRef = ref
+			}
+			entry.Settings.SecretRef = settingsRef
 			return true
 		}
 	}
@@ -29,7 +40,8 @@ func (c *Collection) UpdateRequest(entry *Request, from *Request) bool {
 }
 
 // Clone deep-copies a request via its JSON form. The ID is cleared; callers
-// assign a fresh one when the copy becomes a tab or is sent.
+// assign a fresh one when the copy becomes a tab or is sent. So is the
+// vault SecretRefs: a copy stores its secrets separately.
 func (r *Request) Clone() *Request {
 	clone := &Request{}
 
@@ -40,6 +52,7 @@ func (r *Request) Clone() *Request {
 
 	json.Unmarshal(data, clone)
 	clone.ID = ""
+	clone.forgetSecretRefs()
 
 	return clone
 }
@@ -60,9 +73,17 @@ func LoadCollections() []*Collection {
 
 	json.Unmarshal(content, &collections)
 
+	for _, c := range collections {
+		for _, r := range c.Requests {
+			r.FillSecrets()
+		}
+	}
+
 	return collections
 }
 
+// SaveCollections writes the collections with secret credentials redacted,
+// reporting vault errors after the file is written.
 func SaveCollections(collections []*Collection) error {
 	file, err := configFile("collections.json")
 
@@ -70,11 +91,17 @@ func SaveCollections(collections []*Collection) error {
 		return err
 	}
 
-	data, err := json.Marshal(collections)
+	saved, vaultErr := redactCollections(collections)
+
+	data, err := json.Marshal(saved)
 
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(file, data, 0o644)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return err
+	}
+
+	return vaultErr
 }
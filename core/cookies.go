@@ -0,0 +1,349 @@
+package core
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// The cookie jar is opt-in and scoped per environment: each environment
+// (and "No Environment") has its own jar in cookies.json. The UI publishes
+// the active one with SetCookieJar and SendRequest attaches it to the
+// client, so Set-Cookie from one send comes back on the next.
+//
+// net/http/cookiejar can't list or edit its cookies, hence this small jar.
+// ponytail: RFC 6265 minus SameSite and the per-domain cookie limits.
+
+// Cookie is one stored cookie. HostOnly means it came without a Domain
+// attribute and only matches the exact host that set it.
+type Cookie struct {
+	Name     string    `json:"Name"`
+	Value    string    `json:"Value"`
+	Domain   string    `json:"Domain"`
+	Path     string    `json:"Path"`
+	Expires  time.Time `json:"Expires,omitzero"` // zero: session cookie, kept until deleted
+	Secure   bool      `json:"Secure,omitempty"`
+	HttpOnly bool      `json:"HttpOnly,omitempty"`
+	HostOnly bool      `json:"HostOnly,omitempty"`
+}
+
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// matches reports whether c goes out with a request to u.
+func (c *Cookie) matches(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+
+	if c.HostOnly {
+		if host != c.Domain {
+			return false
+		}
+	} else if !domainMatch(host, c.Domain) {
+		return false
+	}
+
+	if c.Secure && u.Scheme != "https" {
+		return false
+	}
+
+	return pathMatch(requestPath(u), c.Path)
+}
+
+// CookieJar implements http.CookieJar over an editable cookie list.
+type CookieJar struct {
+	mu      sync.Mutex
+	cookies []*Cookie
+}
+
+func (j *CookieJar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.All())
+}
+
+func (j *CookieJar) UnmarshalJSON(data []byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return json.Unmarshal(data, &j.cookies)
+}
+
+// SetCookies stores the cookies a response to u set. Max-Age/Expires in
+// the past delete; a Domain that doesn't cover u's host, or is a public
+// suffix like "com", is rejected.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, hc := range cookies {
+		c := &Cookie{
+			Name:     hc.Name,
+			Value:    hc.Value,
+			Path:     hc.Path,
+			Secure:   hc.Secure,
+			HttpOnly: hc.HttpOnly,
+		}
+
+		domain := strings.ToLower(strings.TrimPrefix(hc.Domain, "."))
+		switch {
+		case domain == "" || domain == host:
+			c.Domain, c.HostOnly = host, domain == ""
+		case net.ParseIP(host) != nil || !domainMatch(host, domain):
+			continue
+		default:
+			if ps, _ := publicsuffix.PublicSuffix(domain); ps == domain {
+				continue
+			}
+			c.Domain = domain
+		}
+
+		if c.Path == "" || c.Path[0] != '/' {
+			c.Path = defaultPath(u)
+		}
+
+		switch {
+		case hc.MaxAge < 0:
+			c.Expires = now
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		case !hc.Expires.IsZero():
+			c.Expires = hc.Expires
+		}
+
+		j.put(c, now)
+	}
+}
+
+// Cookies returns the cookies to send to u, longest path first.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+
+	now := time.Now()
+
+	j.mu.Lock()
+	var matched []*Cookie
+	for _, c := range j.cookies {
+		if !c.expired(now) && c.matches(u) {
+			matched = append(matched, c)
+		}
+	}
+	j.mu.Unlock()
+
+	slices.SortStableFunc(matched, func(a, b *Cookie) int {
+		return len(b.Path) - len(a.Path)
+	})
+
+	out := make([]*http.Cookie, 0, len(matched))
+	for _, c := range matched {
+		out = append(out, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return out
+}
+
+// All returns copies of the unexpired cookies, by domain then name.
+func (j *CookieJar) All() []Cookie {
+	if j == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	out := []Cookie{}
+	for _, c := range j.cookies {
+		if !c.expired(now) {
+			out = append(out, *c)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b Cookie) int {
+		if d := strings.Compare(a.Domain, b.Domain); d != 0 {
+			return d
+		}
+		if d := strings.Compare(a.Name, b.Name); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return out
+}
+
+// Put adds c, replacing the cookie with the same name, domain and path.
+// An already expired c deletes it.
+func (j *CookieJar) Put(c Cookie) {
+	c.Domain = strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if c.Path == "" {
+		c.Path = "/"
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.put(&c, time.Now())
+}
+
+// Delete removes the cookie with c's name, domain and path.
+func (j *CookieJar) Delete(c Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.cookies = slices.DeleteFunc(j.cookies, func(o *Cookie) bool {
+		return o.Name == c.Name && o.Domain == c.Domain && o.Path == c.Path
+	})
+}
+
+// Clear empties the jar.
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	j.cookies = nil
+	j.mu.Unlock()
+}
+
+// put replaces or appends c, dropping it (and any expired cookies) when
+// already expired. j.mu must be held.
+func (j *CookieJar) put(c *Cookie, now time.Time) {
+	j.cookies = slices.DeleteFunc(j.cookies, func(o *Cookie) bool {
+		return o.expired(now) || (o.Name == c.Name && o.Domain == c.Domain && o.Path == c.Path)
+	})
+
+	if !c.expired(now) {
+		j.cookies = append(j.cookies, c)
+	}
+}
+
+func domainMatch(host, domain string) bool {
+	return host == domain || (strings.HasSuffix(host, "."+domain) && net.ParseIP(host) == nil)
+}
+
+func requestPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+func pathMatch(reqPath, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+}
+
+// defaultPath is RFC 6265 5.1.4: the request path up to its last slash.
+func defaultPath(u *url.URL) string {
+	p := requestPath(u)
+	i := strings.LastIndex(p, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// CookieStore holds every environment's jar. Enabled is the opt-in; the
+// jars keep their cookies while it's off. The jars are safe for concurrent
+// use, the store itself (and so SaveCookieStore) is not.
+type CookieStore struct {
+	Enabled bool                  `json:"Enabled"`
+	Jars    map[string]*CookieJar `json:"Jars"` // by environment name; "" is No Environment
+}
+
+// Jar returns env's jar, creating it on first use.
+func (s *CookieStore) Jar(env string) *CookieJar {
+	if s.Jars == nil {
+		s.Jars = map[string]*CookieJar{}
+	}
+
+	j := s.Jars[env]
+	if j == nil {
+		j = &CookieJar{}
+		s.Jars[env] = j
+	}
+
+	return j
+}
+
+// RenameEnv keeps a renamed environment's cookies with it.
+func (s *CookieStore) RenameEnv(from, to string) {
+	if from == to || s.Jars[from] == nil {
+		return
+	}
+
+	s.Jars[to] = s.Jars[from]
+	delete(s.Jars, from)
+}
+
+var (
+	cookieMu  sync.RWMutex
+	activeJar *CookieJar
+)
+
+// SetCookieJar swaps the jar SendRequest attaches. Pass nil to send
+// without one.
+func SetCookieJar(j *CookieJar) {
+	cookieMu.Lock()
+	activeJar = j
+	cookieMu.Unlock()
+}
+
+func currentCookieJar() *CookieJar {
+	cookieMu.RLock()
+	defer cookieMu.RUnlock()
+
+	return activeJar
+}
+
+// LoadCookieStore reads saved cookies; empty store on any error.
+func LoadCookieStore() *CookieStore {
+	store := &CookieStore{}
+
+	file, err := configFile("cookies.json")
+	if err != nil {
+		return store
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return store
+	}
+
+	json.Unmarshal(content, store)
+
+	return store
+}
+
+// SaveCookieStore writes cookies owner-only: session cookies are
+// credentials.
+func SaveCookieStore(store *CookieStore) error {
+	file, err := configFile("cookies.json")
+
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(store)
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0o600)
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCookieJarMatching(t *testing.T) {
+	jar := &CookieJar{}
+	u, _ := url.Parse("https://api.example.com/v1/login")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1"},                                     // host-only, path /v1
+		{Name: "wide", Value: "2", Domain: ".example.com", Path: "/"},  // all subdomains
+		{Name: "secure", Value: "3", Path: "/", Secure: true},          // https only
+		{Name: "suffix", Value: "x", Domain: "com", Path: "/"},         // public suffix: rejected
+		{Name: "other", Value: "x", Domain: "other.com", Path: "/"},    // foreign domain: rejected
+		{Name: "gone", Value: "x", Path: "/", MaxAge: -1},              // deletes
+		{Name: "old", Value: "x", Path: "/", Expires: time.Unix(1, 0)}, // already expired
+	})
+
+	names := func(raw string) map[string]string {
+		u, _ := url.Parse(raw)
+		got := map[string]string{}
+		for _, c := range jar.Cookies(u) {
+			got[c.Name] = c.Value
+		}
+		return got
+	}
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://api.example.com/v1/users", []string{"host", "wide", "secure"}},
+		{"https://api.example.com/v2", []string{"wide", "secure"}},
+		{"http://api.example.com/v1", []string{"host", "wide"}},
+		{"https://www.example.com/", []string{"wide"}},
+		{"https://example.com.evil.io/", nil},
+	}
+	for _, tt := range tests {
+		got := names(tt.url)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.url, got, tt.want)
+			continue
+		}
+		for _, n := range tt.want {
+			if _, ok := got[n]; !ok {
+				t.Errorf("%s: missing %q in %v", tt.url, n, got)
+			}
+		}
+	}
+
+	if all := jar.All(); len(all) != 3 {
+		t.Fatalf("All() = %+v, want 3 cookies", all)
+	}
+
+	// Same name/domain/path replaces; editing keeps one copy.
+	jar.Put(Cookie{Name: "wide", Value: "edited", Domain: "example.com", Path: "/"})
+	if got := names("https://www.example.com/")["wide"]; got != "edited" || len(jar.All()) != 3 {
+		t.Fatalf("Put didn't replace: %q, %d cookies", got, len(jar.All()))
+	}
+
+	jar.Delete(Cookie{Name: "wide", Domain: "example.com", Path: "/"})
+	if _, ok := names("https://www.example.com/")["wide"]; ok {
+		t.Fatal("Delete left the cookie")
+	}
+
+	// Persisted form round-trips.
+	data, err := json.Marshal(&CookieStore{Enabled: true, Jars: map[string]*CookieJar{"dev": jar}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back CookieStore
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back.Enabled || len(back.Jar("dev").All()) != 2 {
+		t.Fatalf("round trip lost cookies: %s", data)
+	}
+}
+
+// End-to-end: a cookie set by one send goes back on the next, but only
+// while a jar is active.
+func TestSendRequestCookieJar(t *testing.T) {
+	var gotCookie string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			return
+		}
+		gotCookie = r.Header.Get("Cookie")
+	}))
+	defer server.Close()
+
+	send := func(path string) {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL+path)
+		defer DeleteHistory(r.ID)
+		if _, err := r.SendRequest(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jar := &CookieJar{}
+	SetCookieJar(jar)
+	defer SetCookieJar(nil)
+
+	send("/login")
+	send("/me")
+	if gotCookie != "session=abc" {
+		t.Fatalf("Cookie = %q, want session=abc", gotCookie)
+	}
+
+	SetCookieJar(nil)
+	send("/me")
+	if gotCookie != "" {
+		t.Fatalf("Cookie sent without a jar: %q", gotCookie)
+	}
+}
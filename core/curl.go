@@ -3,7 +3,9 @@ package core
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -26,12 +28,10 @@ func ParseCurl(cmd string) (*Request, error) {
 	// flags that take a value but mean nothing to us — the value must
 	// still be consumed or it would be mistaken for the URL
 	ignoredWithArg := map[string]bool{
-		"-o": true, "--output": true,
 		"-A": true, "--user-agent": true,
 		"-e": true, "--referer": true,
 		"-m": true, "--max-time": true,
-		"--connect-timeout": true, "--retry": true,
-		"--cacert": true, "--capath": true,
+		"--connect-timeout": true, "--capath": true, "--cert-type": true,
 	}
 
 	req := &Request{ID: NewRequestID(), Method: "GET", IsDirty: true}
@@ -39,7 +39,7 @@ func ParseCurl(cmd string) (*Request, error) {
 	var formRows []FormType
 	var cookies []string
 	var dataParts []string
-	var reqURL, contentType string
+	var reqURL, contentType, binaryPath string
 
 	next := func(i *int, flag string) (string, error) {
 		*i++
@@ -76,6 +76,11 @@ func ParseCurl(cmd string) (*Request, error) {
 			if err != nil {
 				return nil, err
 			}
+			// --data-binary @file sends the file's bytes as they are
+			if path, ok := strings.CutPrefix(v, "@"); ok && t == "--data-binary" {
+				binaryPath = path
+				continue
+			}
 			dataParts = append(dataParts, v)
 
 		case "-F", "--form":
@@ -85,8 +90,20 @@ func ParseCurl(cmd string) (*Request, error) {
 			}
 			key, val, _ := strings.Cut(v, "=")
 			// curl's -F key=@path means "upload the file at path"
-			isFile := strings.HasPrefix(val, "@")
-			formRows = append(formRows, FormType{Checked: true, Key: key, Value: strings.TrimPrefix(val, "@"), IsFile: isFile})
+			val, isFile := strings.CutPrefix(val, "@")
+			row := FormType{Checked: true, Key: key, Value: val, IsFile: isFile}
+			if at := strings.Index(val, ";type="); at >= 0 {
+				row.Value, row.ContentType = val[:at], val[at+len(";type="):]
+			}
+
+			// Repeated -F key=@file lines are one row with several files
+			if n := len(formRows); isFile && n > 0 {
+				if prev := &formRows[n-1]; prev.IsFile && prev.Key == key && prev.ContentType == row.ContentType {
+					prev.Value += "\n" + row.Value
+					continue
+				}
+			}
+			formRows = append(formRows, row)
 
 		case "-u", "--user":
 			v, err := next(&i, t)
@@ -107,6 +124,153 @@ func ParseCurl(cmd string) (*Request, error) {
 		case "-k", "--insecure":
 			req.Settings.SkipTLSVerify = true
 
+		case "-E", "--cert", "--key", "--cacert", "--pass":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			cc := &req.Settings.ClientCert
+			switch t {
+			case "--key":
+				cc.KeyFile = v
+			case "--cacert":
+				cc.CAFile = v
+			case "--pass":
+				cc.Passphrase = v
+			default:
+				// curl's --cert file:password form
+				path, pass, _ := strings.Cut(v, ":")
+				cc.SetCertificate(path)
+				if pass != "" {
+					cc.Passphrase = pass
+				}
+			}
+
+		case "-o", "--output":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			req.Settings.Download = true
+			req.Settings.DownloadPath = v
+
+		case "-x", "--proxy":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			req.Settings.Proxy.URL = v
+
+		case "-U", "--proxy-user":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			req.Settings.Proxy.User, req.Settings.Proxy.Pass, _ = strings.Cut(v, ":")
+
+		case "--noproxy":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if v == "*" {
+				req.Settings.Proxy.Direct = true
+			} else {
+				req.Settings.Proxy.Bypass = v
+			}
+
+		case "--resolve":
+			// [+]host:port:addr[,addr...] — the first address is kept
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if f := splitCurlHosts(strings.TrimPrefix(v, "+"), 3); len(f) == 3 && f[0] != "" && f[0] != "*" {
+				addr, _, _ := strings.Cut(f[2], ",")
+				req.Settings.DNS.Overrides = append(req.Settings.DNS.Overrides, HostOverride{
+					Host: net.JoinHostPort(f[0], f[1]),
+					Addr: strings.Trim(addr, "[]"),
+				})
+			}
+
+		case "--connect-to":
+			// host1:port1:host2:port2, either side's parts may be empty;
+			// an empty host1 (every host) has no equivalent here
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if f := splitCurlHosts(v, 4); len(f) == 4 && f[0] != "" && f[2]+f[3] != "" {
+				o := HostOverride{Host: f[0], Addr: f[2]}
+				if f[1] != "" {
+					o.Host = net.JoinHostPort(f[0], f[1])
+				}
+				if f[3] != "" {
+					o.Addr = net.JoinHostPort(f[2], f[3])
+				}
+				req.Settings.DNS.Overrides = append(req.Settings.DNS.Overrides, o)
+			}
+
+		case "--dns-servers":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			req.Settings.DNS.Server, _, _ = strings.Cut(v, ",")
+
+		case "--http1.1", "--http1.0", "-0":
+			req.Settings.Protocol = ProtoHTTP1
+		case "--http2", "--http2-prior-knowledge":
+			req.Settings.Protocol = ProtoHTTP2
+		case "--http3", "--http3-only":
+			req.Settings.Protocol = ProtoHTTP3
+
+		case "--max-redirs":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				req.Settings.MaxRedirects = n
+			}
+		case "--location-trusted":
+			req.Settings.KeepAuthOnRedirect = true
+
+		case "--retry":
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				req.Settings.Retry.MaxAttempts = n + 1 // curl counts retries, we count tries
+			}
+		case "--retry-delay":
+			// curl waits this long every time; here it seeds the backoff
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+				req.Settings.Retry.BaseDelayMs = secs * 1000
+			}
+		case "--retry-max-time":
+			if _, err := next(&i, t); err != nil {
+				return nil, err
+			}
+		case "--retry-connrefused", "--retry-all-errors":
+			// connection errors retry by default here
+
+		case "-O", "--remote-name":
+			req.Settings.Download = true // empty path: temp file named after the URL
+
+		case "-C", "--continue-at":
+			// only "-" (resume from the file's size) maps onto Range resume
+			v, err := next(&i, t)
+			if err != nil {
+				return nil, err
+			}
+			req.Settings.ResumeDownload = v == "-"
+
 		case "--url":
 			v, err := next(&i, t)
 			if err != nil {
@@ -163,6 +327,11 @@ func ParseCurl(cmd string) (*Request, error) {
 		req.BodyType = "Form"
 		req.Body.Form = &formRows
 
+	case binaryPath != "":
+		// A Content-Type header, if any, is kept as a header and wins
+		req.BodyType = "Binary"
+		req.Body.Binary = binaryPath
+
 	case data != "":
 		switch {
 		case strings.Contains(contentType, "json"):
@@ -187,7 +356,7 @@ func ParseCurl(cmd string) (*Request, error) {
 	}
 
 	// -d/-F without an explicit -X means POST, like curl itself
-	if req.Method == "GET" && (data != "" || len(formRows) > 0) {
+	if req.Method == "GET" && (data != "" || len(formRows) > 0 || binaryPath != "") {
 		req.Method = "POST"
 	}
 
@@ -213,6 +382,32 @@ func parseURLEncodedBody(data string) ([]FormType, bool) {
 	return rows, len(rows) > 0
 }
 
+// splitCurlHosts splits --resolve/--connect-to values on colons outside
+// IPv6 brackets, into at most n fields; brackets are stripped.
+func splitCurlHosts(s string, n int) []string {
+	var fields []string
+	start, inBrackets := 0, false
+	for i, c := range s {
+		switch {
+		case c == '[':
+			inBrackets = true
+		case c == ']':
+			inBrackets = false
+		case c == ':' && !inBrackets && len(fields) < n-1:
+			fields = append(fields, s[start:i])
+			start = i + 1
+		}
+	}
+	fields = append(fields, s[start:])
+
+	for i, f := range fields {
+		if !strings.Contains(f, ",") {
+			fields[i] = strings.Trim(f, "[]")
+		}
+	}
+	return fields
+}
+
 // tokenizeCurl is a shell-ish splitter: single/double quotes, $'...'
 // (treated like single quotes), backslash escapes, and both bash (\<newline>)
 // and cmd (^<newline>) line continuations.
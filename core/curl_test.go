@@ -1,6 +1,7 @@
 package core
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -76,17 +77,73 @@ func TestParseCurl(t *testing.T) {
 	})
 
 	t.Run("multipart form", func(t *testing.T) {
-		r, err := ParseCurl(`curl https://x.test/upload -F 'name=bob' -F 'file=@photo.png'`)
+		r, err := ParseCurl(`curl https://x.test/upload -F 'name=bob' -F 'file=@photo.png' -F 'meta={};type=application/json' -F 'docs=@a.pdf;type=application/pdf' -F 'docs=@b.pdf;type=application/pdf'`)
 		if err != nil {
 			t.Fatal(err)
 		}
 		rows := *r.Body.Form
-		if r.BodyType != "Form" || len(rows) != 2 || rows[0].IsFile {
+		if r.BodyType != "Form" || len(rows) != 4 || rows[0].IsFile {
 			t.Fatalf("bodytype=%q form=%+v", r.BodyType, rows)
 		}
 		if !rows[1].IsFile || rows[1].Value != "photo.png" {
 			t.Fatalf("-F file=@photo.png should be a file row: %+v", rows[1])
 		}
+		if rows[2].IsFile || rows[2].Value != "{}" || rows[2].ContentType != "application/json" {
+			t.Fatalf(";type= on a field: %+v", rows[2])
+		}
+		if files := rows[3].Files(); len(files) != 2 || files[1] != "b.pdf" || rows[3].ContentType != "application/pdf" {
+			t.Fatalf("repeated file key should be one row: %+v", rows[3])
+		}
+	})
+
+	t.Run("host overrides", func(t *testing.T) {
+		r, err := ParseCurl(`curl --resolve api.test:443:10.0.0.5,10.0.0.6 --resolve '[::1]:8443:[fe80::1]' --connect-to cdn.test::origin.test:8080 --connect-to ::ignored:1 --dns-servers 1.1.1.1,8.8.8.8 https://api.test/`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := DNSConfig{Server: "1.1.1.1", Overrides: []HostOverride{
+			{Host: "api.test:443", Addr: "10.0.0.5"},
+			{Host: "[::1]:8443", Addr: "fe80::1"},
+			{Host: "cdn.test", Addr: "origin.test:8080"},
+		}}
+		if !reflect.DeepEqual(r.Settings.DNS, want) {
+			t.Fatalf("dns=%+v", r.Settings.DNS)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		r, err := ParseCurl(`curl -x http://proxy:3128 -U 'me:pw' --noproxy localhost,.corp https://x.test/`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := ProxyConfig{URL: "http://proxy:3128", User: "me", Pass: "pw", Bypass: "localhost,.corp"}
+		if r.Settings.Proxy != want || r.URL != "https://x.test/" {
+			t.Fatalf("proxy=%+v url=%q", r.Settings.Proxy, r.URL)
+		}
+
+		r, err = ParseCurl(`curl --noproxy '*' https://x.test/`)
+		if err != nil || !r.Settings.Proxy.Direct {
+			t.Fatalf("--noproxy '*' should mean Direct: %+v %v", r.Settings.Proxy, err)
+		}
+	})
+
+	t.Run("binary file body", func(t *testing.T) {
+		r, err := ParseCurl(`curl https://x.test/blob -H 'Content-Type: application/zip' --data-binary @build/out.zip`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Method != "POST" || r.BodyType != "Binary" || r.Body.Binary != "build/out.zip" {
+			t.Fatalf("method=%q bodytype=%q binary=%q", r.Method, r.BodyType, r.Body.Binary)
+		}
+
+		// Without @ it's still inline data
+		r, err = ParseCurl(`curl https://x.test/ --data-binary 'raw'`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.BodyType == "Binary" {
+			t.Fatalf("inline --data-binary became a file body: %+v", r.Body)
+		}
 	})
 
 	t.Run("basic auth insecure cookie", func(t *testing.T) {
@@ -105,6 +162,43 @@ func TestParseCurl(t *testing.T) {
 		}
 	})
 
+	t.Run("client certificate", func(t *testing.T) {
+		r, err := ParseCurl(`curl --cert client.p12:pw --cacert ca.pem https://x.test/`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cc := r.Settings.ClientCert
+		if cc.PFXFile != "client.p12" || cc.Passphrase != "pw" || cc.CAFile != "ca.pem" || cc.CertFile != "" {
+			t.Fatalf("client cert: %+v", cc)
+		}
+
+		r, err = ParseCurl(`curl --cert client.crt --key client.key https://x.test/`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cc := r.Settings.ClientCert; cc.CertFile != "client.crt" || cc.KeyFile != "client.key" {
+			t.Fatalf("PEM client cert: %+v", cc)
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+		r, err := ParseCurl(`curl -C - -o 'big file.iso' https://x.test/big.iso`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := r.Settings; !s.Download || s.DownloadPath != "big file.iso" || !s.ResumeDownload {
+			t.Fatalf("settings: %+v", s)
+		}
+
+		r, err = ParseCurl(`curl -O https://x.test/big.iso`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := r.Settings; !s.Download || s.DownloadPath != "" || r.URL != "https://x.test/big.iso" {
+			t.Fatalf("-O: %+v url=%q", s, r.URL)
+		}
+	})
+
 	t.Run("explicit method wins", func(t *testing.T) {
 		r, err := ParseCurl(`curl -X PUT https://x.test/thing -d 'a=1'`)
 		if err != nil {
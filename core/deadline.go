@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// sendDeadline stands in for http.Client.Timeout on sends that stream:
+// that one runs from dial to the last body byte, so a slow multi-gigabyte
+// upload or download could never finish. This one only runs while
+// waiting on the server — armed once the request body is fully written,
+// stopped when a download's headers arrive — and restarts per attempt.
+// Dial and TLS handshake keep the transport's own timeouts.
+type sendDeadline struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel context.CancelFunc
+	fired  bool
+}
+
+// attempt derives the context for one try, its deadline not yet armed.
+// The previous try's context ends here.
+func (d *sendDeadline) attempt(ctx context.Context) context.Context {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopLocked()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.fired = false
+
+	// Written means handed to the connection, every hop of a redirect
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { d.start() },
+	})
+}
+
+// start arms the deadline, or re-arms it from now.
+func (d *sendDeadline) start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopLocked()
+	cancel := d.cancel
+	d.timer = time.AfterFunc(d.timeout, func() {
+		d.mu.Lock()
+		d.fired = true
+		d.mu.Unlock()
+		cancel()
+	})
+}
+
+// stop disarms the deadline; the body then streams until cancelled.
+func (d *sendDeadline) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopLocked()
+}
+
+func (d *sendDeadline) stopLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// release ends the last try's context once the send is done with it.
+func (d *sendDeadline) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopLocked()
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// err names a cancellation the deadline caused, which would otherwise read
+// as the user's own and be dropped silently.
+func (d *sendDeadline) err(err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil || !d.fired {
+		return err
+	}
+	return fmt.Errorf("timed out: no response within %s: %w", d.timeout, context.DeadlineExceeded)
+}
+
+// armAtEOF wraps a request body so the deadline starts once it's all read,
+// for transports that don't report WroteRequest (HTTP/3).
+func (d *sendDeadline) armAtEOF(body io.ReadCloser) io.ReadCloser {
+	return &eofBody{ReadCloser: body, onEOF: d.start}
+}
+
+type eofBody struct {
+	io.ReadCloser
+	onEOF func()
+	once  sync.Once
+}
+
+func (b *eofBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.onEOF)
+	}
+	return n, err
+}
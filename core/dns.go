@@ -0,0 +1,187 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// HostOverride pins a host to another address before DNS is consulted,
+// like curl's --resolve (Addr is an IP) and --connect-to (Addr carries a
+// port or another host). TLS still verifies against the original name.
+type HostOverride struct {
+	Host string `json:"Host"` // "api.example.com" for any port, or "api.example.com:443"
+	Addr string `json:"Addr"` // "10.0.0.5", "10.0.0.5:8443", "staging.example.com:443" or ":8443"
+}
+
+// DNSConfig customises name resolution. Environments set it for every send;
+// a request's own overrides win over the environment's, and its Server
+// replaces the environment's.
+type DNSConfig struct {
+	Overrides []HostOverride `json:"Overrides,omitempty"`
+	Server    string         `json:"Server,omitempty"` // "1.1.1.1" or "10.0.0.2:5353"; empty → system resolver
+}
+
+// IsZero reports whether d changes nothing.
+func (d DNSConfig) IsZero() bool {
+	return len(d.Overrides) == 0 && d.Server == ""
+}
+
+// resolved substitutes {{var}}s and drops half-filled rows.
+func (d DNSConfig) resolved() DNSConfig {
+	out := DNSConfig{Server: strings.TrimSpace(ApplyEnv(d.Server))}
+	for _, o := range d.Overrides {
+		o.Host, o.Addr = strings.TrimSpace(ApplyEnv(o.Host)), strings.TrimSpace(ApplyEnv(o.Addr))
+		if o.Host != "" && o.Addr != "" {
+			out.Overrides = append(out.Overrides, o)
+		}
+	}
+	return out
+}
+
+// poolKey flattens a resolved d for the transport pool key.
+func (d DNSConfig) poolKey() string {
+	var b strings.Builder
+	for _, o := range d.Overrides {
+		fmt.Fprintf(&b, "%s=%s;", o.Host, o.Addr)
+	}
+	b.WriteString(d.Server)
+	return b.String()
+}
+
+// splitAddr splits "host:port", "host", "[v6]" or bare "v6" into host and
+// an optional port.
+func splitAddr(s string) (host, port string) {
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		return h, p
+	}
+	return strings.Trim(s, "[]"), ""
+}
+
+// override maps a dial address through the matching override; a row with
+// a port beats a host-only one.
+func (d DNSConfig) override(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	var match *HostOverride
+	for i, o := range d.Overrides {
+		h, p := splitAddr(o.Host)
+		if !strings.EqualFold(h, host) || (p != "" && p != port) {
+			continue
+		}
+		if p != "" {
+			match = &d.Overrides[i]
+			break
+		}
+		if match == nil {
+			match = &d.Overrides[i]
+		}
+	}
+	if match == nil {
+		return addr
+	}
+
+	h, p := splitAddr(match.Addr)
+	return net.JoinHostPort(cmp.Or(h, host), cmp.Or(p, port))
+}
+
+// addrs resolves addr into the addresses to try: overrides first, then the
+// custom server when set. Without one the name is left to the dialer.
+func (d DNSConfig) addrs(ctx context.Context, addr string) ([]string, error) {
+	addr = d.override(addr)
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || d.Server == "" || net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+
+	ips, err := d.resolver().LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(ips))
+	for i, ip := range ips {
+		out[i] = net.JoinHostPort(ip, port)
+	}
+	return out, nil
+}
+
+// resolver asks d.Server (port 53 unless given) with Go's resolver, so the
+// system's resolv.conf plays no part.
+func (d DNSConfig) resolver() *net.Resolver {
+	server := d.Server
+	if h, p := splitAddr(server); p == "" {
+		server = net.JoinHostPort(h, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			// Dial on a context without the send's httptrace, which would
+			// time these lookups as Connect (from parallel A/AAAA queries)
+			var dialer net.Dialer
+			if deadline, ok := ctx.Deadline(); ok {
+				dialer.Deadline = deadline
+			}
+			bare, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			defer context.AfterFunc(ctx, cancel)()
+
+			return dialer.DialContext(bare, network, server)
+		},
+	}
+}
+
+// dialEach tries dial against every address addr resolves to, returning
+// the first success.
+func dialEach[C any](ctx context.Context, d DNSConfig, addr string, dial func(context.Context, string) (C, error)) (C, error) {
+	var zero C
+
+	addrs, err := d.addrs(ctx, addr)
+	if err != nil {
+		return zero, err
+	}
+
+	var errs []error
+	for _, a := range addrs {
+		conn, err := dial(ctx, a)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return zero, errors.Join(errs...)
+}
+
+var (
+	dnsMu     sync.RWMutex
+	activeDNS DNSConfig
+)
+
+// SetDNS sets the active environment's resolution settings.
+func SetDNS(d DNSConfig) {
+	dnsMu.Lock()
+	activeDNS = d
+	dnsMu.Unlock()
+}
+
+// effectiveDNS layers the request's settings over the active environment's.
+func effectiveDNS(s Settings) DNSConfig {
+	dnsMu.RLock()
+	env := activeDNS
+	dnsMu.RUnlock()
+
+	return DNSConfig{
+		Overrides: append(append([]HostOverride(nil), s.DNS.Overrides...), env.Overrides...),
+		Server:    cmp.Or(s.DNS.Server, env.Server),
+	}
+}
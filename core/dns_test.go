@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSOverride(t *testing.T) {
+	d := DNSConfig{Overrides: []HostOverride{
+		{Host: "api.test", Addr: "10.0.0.1"},
+		{Host: "api.test:8443", Addr: "10.0.0.2:9443"},
+		{Host: "cdn.test", Addr: "origin.test"},
+		{Host: "port.test", Addr: ":8080"},
+		{Host: "v6.test", Addr: "[::1]"},
+	}}
+	tests := []struct{ in, want string }{
+		{"api.test:443", "10.0.0.1:443"},
+		{"API.test:8443", "10.0.0.2:9443"}, // host:port row beats host-only
+		{"cdn.test:80", "origin.test:80"},
+		{"port.test:443", "port.test:8080"},
+		{"v6.test:443", "[::1]:443"},
+		{"other.test:443", "other.test:443"},
+	}
+	for _, tt := range tests {
+		if got := d.override(tt.in); got != tt.want {
+			t.Errorf("override(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// fakeDNS answers every A query with 127.0.0.1 and counts the queries.
+func fakeDNS(t *testing.T) (addr string, queries *atomic.Int32) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no UDP: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	queries = new(atomic.Int32)
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if msg.Unpack(buf[:n]) != nil || len(msg.Questions) == 0 {
+				continue
+			}
+
+			q := msg.Questions[0]
+			msg.Header.Response, msg.Header.Authoritative = true, true
+			if q.Type == dnsmessage.TypeA {
+				queries.Add(1)
+				msg.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: q.Class, TTL: 60},
+					Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+				}}
+			}
+			out, _ := msg.Pack()
+			conn.WriteTo(out, from)
+		}
+	}()
+
+	return conn.LocalAddr().String(), queries
+}
+
+func TestSendRequestDNS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Host)
+	}))
+	defer server.Close()
+	defer CloseIdleConnections()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	send := func(r *Request) *Response {
+		t.Helper()
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// Environment override: the Host header keeps the original name
+	SetDNS(DNSConfig{Overrides: []HostOverride{{Host: "pinned.test", Addr: "127.0.0.1"}}})
+	defer SetDNS(DNSConfig{})
+
+	res := send(testRequest(NewRequestID(), "http://pinned.test:"+port+"/"))
+	if res.Body != "pinned.test:"+port || res.Timings.RemoteAddr != "127.0.0.1:"+port {
+		t.Fatalf("body=%q remote=%q", res.Body, res.Timings.RemoteAddr)
+	}
+
+	// Request override redirecting host and port, --connect-to style
+	r := testRequest(NewRequestID(), "http://moved.test/")
+	r.Settings.DNS.Overrides = []HostOverride{{Host: "moved.test:80", Addr: "127.0.0.1:" + port}}
+	if res := send(r); res.Body != "moved.test" {
+		t.Fatalf("connect-to: body=%q", res.Body)
+	}
+
+	// Custom DNS server
+	dnsAddr, queries := fakeDNS(t)
+	r = testRequest(NewRequestID(), "http://resolved.test:"+port+"/")
+	r.Settings.DNS.Server = dnsAddr
+	if res := send(r); res.Body != "resolved.test:"+port || queries.Load() == 0 {
+		t.Fatalf("custom server: body=%q queries=%d", res.Body, queries.Load())
+	}
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ParseDotenv reads a .env file: KEY=value lines, optionally prefixed with
+// "export", # comments, 'literal' and "escaped" values — the latter may
+// span lines. Values aren't interpolated.
+func ParseDotenv(data []byte) ([]FormType, error) {
+	var vars []FormType
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		start := n + 1 // for errors, 1-based
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: want KEY=value", start)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated '", start)
+			}
+			value = value[1 : end+1]
+
+		case strings.HasPrefix(value, `"`):
+			// Join following lines until the closing quote
+			raw := value[1:]
+			for {
+				if end := closingQuote(raw); end >= 0 {
+					raw = raw[:end]
+					break
+				}
+				if n++; n == len(lines) {
+					return nil, fmt.Errorf(`line %d: unterminated "`, start)
+				}
+				raw += "\n" + lines[n]
+			}
+			value = unescapeDotenv(raw)
+
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		vars = append(vars, FormType{Checked: true, Key: key, Value: value})
+	}
+
+	return vars, nil
+}
+
+// closingQuote finds the first unescaped " in s, -1 when there's none.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotenv(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(s)
+}
+
+// ReadDotenv parses the .env file at path.
+func ReadDotenv(path string) ([]FormType, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := ParseDotenv(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return vars, nil
+}
+
+// ReloadDotenv rereads a linked environment's .env file into DotenvVars,
+// recording any failure in DotenvErr.
+func (e *Environment) ReloadDotenv() {
+	e.DotenvVars, e.DotenvErr = nil, nil
+	if e.Dotenv != "" {
+		e.DotenvVars, e.DotenvErr = ReadDotenv(e.Dotenv)
+	}
+}
+
+// WatchFiles calls onChange with the path whenever one of paths is written,
+// created or replaced — editors often save by renaming a temp file over
+// the original, so the parent directories are watched. onChange runs on
+// the watcher's goroutine. stop ends the watch.
+func WatchFiles(paths []string, onChange func(path string)) (stop func(), err error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	var watched []string
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		watched = append(watched, p)
+		if err := w.Add(filepath.Dir(p)); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Clean(ev.Name)
+				if ev.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) && slices.Contains(watched, name) {
+					onChange(name)
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { w.Close() }, nil
+}
+
+// OSVars picks the process environment variables named in patterns:
+// names, or prefixes ending in "*", separated by commas, spaces or lines.
+// Their values are read here and never saved.
+func OSVars(patterns string) map[string]string {
+	vars := make(map[string]string)
+
+	for _, pattern := range strings.FieldsFunc(patterns, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			for _, kv := range os.Environ() {
+				if k, v, _ := strings.Cut(kv, "="); k != "" && strings.HasPrefix(k, prefix) {
+					vars[k] = v
+				}
+			}
+			continue
+		}
+
+		if v, ok := os.LookupEnv(pattern); ok {
+			vars[pattern] = v
+		}
+	}
+
+	return vars
+}
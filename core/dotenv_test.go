@@ -0,0 +1,132 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDotenv(t *testing.T) {
+	data := "# service config\r\n" +
+		"HOST=api.example.com\r\n" +
+		"export PORT = 8443\n" +
+		"\n" +
+		"TOKEN=abc123 # rotated weekly\n" +
+		"HASH=a#b\n" +
+		"RAW='keep \\n as is'\n" +
+		"MSG=\"line one\\nsaid \\\"hi\\\"\"\n" +
+		"PEM=\"-----BEGIN-----\n" +
+		"MIIB\n" +
+		"-----END-----\"\n" +
+		"EMPTY=\n"
+
+	got, err := ParseDotenv([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FormType{
+		{Checked: true, Key: "HOST", Value: "api.example.com"},
+		{Checked: true, Key: "PORT", Value: "8443"},
+		{Checked: true, Key: "TOKEN", Value: "abc123"},
+		{Checked: true, Key: "HASH", Value: "a#b"},
+		{Checked: true, Key: "RAW", Value: `keep \n as is`},
+		{Checked: true, Key: "MSG", Value: "line one\nsaid \"hi\""},
+		{Checked: true, Key: "PEM", Value: "-----BEGIN-----\nMIIB\n-----END-----"},
+		{Checked: true, Key: "EMPTY", Value: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+
+	for _, bad := range []string{"JUST_A_NAME", "=value", "A='open", "B=\"open\nstill open"} {
+		if _, err := ParseDotenv([]byte(bad)); err == nil {
+			t.Errorf("ParseDotenv(%q) accepted", bad)
+		}
+	}
+}
+
+// A linked .env file sits under the environment's own rows, reloads on
+// change, and never reaches environments.json.
+func TestLinkedDotenv(t *testing.T) {
+	dir := tempConfigDir(t)
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("HOST=a.example.com\nKEY=from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &EnvStore{Envs: []*Environment{{Name: "dev", Dotenv: path, Variables: &[]FormType{
+		{Checked: true, Key: "KEY", Value: "override"},
+	}}}}
+	if err := SaveEnvStore(store); err != nil {
+		t.Fatal(err)
+	}
+	assertNotInFile(t, filepath.Join(dir, "environments.json"), "a.example.com")
+
+	env := LoadEnvStore().Envs[0]
+	if vars := env.VarMap(); vars["HOST"] != "a.example.com" || vars["KEY"] != "override" {
+		t.Fatalf("VarMap = %v", vars)
+	}
+
+	changed := make(chan string, 8)
+	stop, err := WatchFiles([]string{path}, func(p string) { changed <- p })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// Saved the way editors do: a temp file renamed over the original
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("HOST=b.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case p := <-changed:
+		if p != path {
+			t.Fatalf("change reported for %q", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+
+	env.ReloadDotenv()
+	if vars := env.VarMap(); vars["HOST"] != "b.example.com" {
+		t.Fatalf("after reload: %v", vars)
+	}
+
+	os.Remove(path)
+	env.ReloadDotenv()
+	if env.DotenvErr == nil || len(env.DotenvVars) != 0 {
+		t.Fatalf("missing file: err=%v vars=%v", env.DotenvErr, env.DotenvVars)
+	}
+}
+
+func TestOSVars(t *testing.T) {
+	t.Setenv("MYAPI_CI_TOKEN", "t0k")
+	t.Setenv("MYAPI_CI_JOB", "42")
+	t.Setenv("MYAPI_OTHER", "x")
+
+	got := OSVars("MYAPI_CI_*, MYAPI_UNSET\nMYAPI_OTHER")
+	want := map[string]string{"MYAPI_CI_TOKEN": "t0k", "MYAPI_CI_JOB": "42", "MYAPI_OTHER": "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	SetOSVars(got)
+	SetGlobalVars(map[string]string{"MYAPI_OTHER": "global"})
+	defer SetOSVars(nil)
+	defer SetGlobalVars(nil)
+
+	if got := ApplyEnv("{{MYAPI_CI_TOKEN}}/{{MYAPI_OTHER}}"); got != "t0k/global" {
+		t.Fatalf("got %q", got)
+	}
+	if info, _ := LookupVar("MYAPI_CI_JOB"); info.Scope != ScopeOS {
+		t.Fatalf("scope = %v", info.Scope)
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Download mode streams the response body straight to a file instead of
+// reading it into memory (capped at maxBodyRead), so multi-GB bodies work.
+// The Response then carries the file path and a preview of its head.
+
+// downloadPreview is how much of a downloaded file goes back as Body.
+const downloadPreview = 64 << 10
+
+// progressEvery throttles progress callbacks; the UI repaints on each.
+const progressEvery = 100 * time.Millisecond
+
+// Progress is a snapshot of a body transfer in flight.
+type Progress struct {
+	Upload  bool
+	Done    int64 // bytes so far, including Resumed
+	Total   int64 // -1 when unknown (no Content-Length)
+	Resumed int64 // bytes already on disk before this send (Range resume)
+	Elapsed time.Duration
+}
+
+// Rate is this send's throughput in bytes per second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Done-p.Resumed) / p.Elapsed.Seconds()
+}
+
+// ETA estimates the time left; 0 when the total or rate is unknown.
+func (p Progress) ETA() time.Duration {
+	rate := p.Rate()
+	if p.Total < 0 || rate <= 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second))
+}
+
+type progressKey struct{}
+
+// WithProgress returns a ctx under which SendRequest reports transfer
+// progress to fn, throttled, from the sending goroutine.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFunc(ctx context.Context) func(Progress) {
+	fn, _ := ctx.Value(progressKey{}).(func(Progress))
+	return fn
+}
+
+// progressReader reports through fn as r is read. The final call (on EOF
+// or error) is never throttled.
+type progressReader struct {
+	r     io.Reader
+	p     Progress
+	fn    func(Progress)
+	start time.Time
+	last  time.Time
+}
+
+func newProgressReader(r io.Reader, p Progress, fn func(Progress)) io.Reader {
+	if fn == nil {
+		return r
+	}
+	now := time.Now()
+	return &progressReader{r: r, p: p, fn: fn, start: now, last: now}
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.Done += int64(n)
+
+	if now := time.Now(); err != nil || now.Sub(pr.last) >= progressEvery {
+		pr.last = now
+		pr.p.Elapsed = now.Sub(pr.start)
+		pr.fn(pr.p)
+	}
+
+	return n, err
+}
+
+// download is one send's download target.
+type download struct {
+	path   string
+	offset int64 // bytes already on disk when resuming; 0 otherwise
+}
+
+// newDownload resolves where this send's body goes: DownloadPath, or a
+// stable temp path named after the URL so a re-send can resume it.
+func newDownload(s Settings, u *url.URL) (*download, error) {
+	p := ApplyEnv(s.DownloadPath)
+	if p == "" {
+		dir := filepath.Join(os.TempDir(), "myapi-downloads")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		p = filepath.Join(dir, downloadName(u))
+	}
+
+	d := &download{path: p}
+	if s.ResumeDownload {
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			d.offset = info.Size()
+		}
+	}
+
+	return d, nil
+}
+
+// downloadFor sets req up for download mode, nil when s doesn't ask for
+// it. Preview goes through here too, so it shows the same headers.
+func (s Settings) downloadFor(req *http.Request) (*download, error) {
+	if !s.Download {
+		return nil, nil
+	}
+	d, err := newDownload(s, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	d.prepare(req)
+	return d, nil
+}
+
+// downloadName is the URL's last path segment, or "download".
+func downloadName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." || name == "" {
+		return "download"
+	}
+	return name
+}
+
+// prepare asks for the file uncompressed, and for the rest of a partial
+// one.
+func (d *download) prepare(req *http.Request) {
+	// The file is saved as served: ranges count encoded bytes, so a
+	// compressed transfer couldn't resume
+	req.Header.Set("Accept-Encoding", "identity")
+	if d.offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(d.offset, 10)+"-")
+	}
+}
+
+// wanted reports whether this response's body belongs in the file: only
+// successes do, plus 416 when resuming. An error page or an unfollowed
+// redirect's body would overwrite, or corrupt, a partial file.
+func (d *download) wanted(response *http.Response) bool {
+	code := response.StatusCode
+	return code >= 200 && code < 300 || (d.offset > 0 && code == http.StatusRequestedRangeNotSatisfiable)
+}
+
+// save streams response's body into the file and returns a preview of the
+// file's head and its final size. A 206 appends; any other success
+// rewrites from the start (the server ignored Range); 416 means the file
+// was already complete.
+// ponytail: no If-Range validator, so a resource that changed between the
+// two sends is spliced. Send without Resume to start over.
+func (d *download) save(ctx context.Context, response *http.Response) ([]byte, int64, error) {
+	offset := int64(0)
+	total := response.ContentLength
+
+	switch response.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		return d.preview(d.offset)
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(response.Header.Get("Content-Range"))
+		if !ok || start != d.offset {
+			return nil, 0, fmt.Errorf("server resumed at byte %d, expected %d", start, d.offset)
+		}
+		offset, total = start, size
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(d.path, flags, 0o644)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	body := newProgressReader(response.Body, Progress{Done: offset, Total: total, Resumed: offset}, progressFunc(ctx))
+	n, err := io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return d.preview(offset + n)
+}
+
+func (d *download) preview(size int64) ([]byte, int64, error) {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, downloadPreview))
+	return head, size, err
+}
+
+// parseContentRange reads "bytes start-end/size"; size is -1 for "*".
+func parseContentRange(v string) (start, size int64, ok bool) {
+	v, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+
+	rng, total, found := strings.Cut(v, "/")
+	first, _, found2 := strings.Cut(rng, "-")
+	if !found || !found2 {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	size = -1
+	if total != "*" {
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return start, size, true
+}
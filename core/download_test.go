@@ -0,0 +1,157 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendRequestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 20_000) // 320 KB, above the preview
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, "nope", http.StatusNotFound)
+			return
+		case "/moved":
+			http.Redirect(w, r, "/blob", http.StatusFound)
+			return
+		}
+		gotRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "blob.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "blob.bin")
+
+	send := func(path string, resume bool) (*Response, Progress) {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL+path)
+		r.Settings = Settings{Download: true, DownloadPath: dest, ResumeDownload: resume, NoFollowRedirects: path == "/moved"}
+		defer DeleteHistory(r.ID)
+
+		var last Progress
+		ctx := WithProgress(context.Background(), func(p Progress) { last = p })
+		res, err := r.SendRequest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, last
+	}
+
+	checkFile := func(stage string) {
+		t.Helper()
+		got, err := os.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("%s: file has %d bytes, want the %d-byte body", stage, len(got), len(content))
+		}
+	}
+
+	res, last := send("/blob", false)
+	checkFile("full download")
+	if res.File != dest || len(res.Body) != downloadPreview || res.Body != string(content[:downloadPreview]) {
+		t.Fatalf("file=%q preview=%d bytes", res.File, len(res.Body))
+	}
+	if last.Done != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Fatalf("final progress %+v", last)
+	}
+
+	// Cut the file short and resume: only the tail is fetched.
+	half := int64(len(content) / 2)
+	if err := os.Truncate(dest, half); err != nil {
+		t.Fatal(err)
+	}
+	_, last = send("/blob", true)
+	checkFile("resumed download")
+	if gotRange != fmt.Sprintf("bytes=%d-", half) || last.Resumed != half || last.Total != int64(len(content)) {
+		t.Fatalf("range=%q progress=%+v", gotRange, last)
+	}
+
+	// Already complete: the server answers 416 and the file is left alone.
+	res, _ = send("/blob", true)
+	checkFile("complete file")
+	if res.BodySize != int64(len(content)) || res.WireSize != res.BodySize {
+		t.Fatalf("size: body=%d wire=%d", res.BodySize, res.WireSize)
+	}
+
+	// Error pages never land in the file.
+	res, _ = send("/missing", false)
+	checkFile("error response")
+	if res.File != "" || res.Body != "nope\n" {
+		t.Fatalf("error response file=%q body=%q", res.File, res.Body)
+	}
+
+	// Nor do unfollowed redirects, even mid-resume: the partial file stays.
+	if err := os.Truncate(dest, half); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = send("/moved", true)
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, content[:half]) {
+		t.Fatalf("redirect rewrote the partial file: %d bytes", len(got))
+	}
+	if res.File != "" || !strings.HasPrefix(res.Status, "302") {
+		t.Fatalf("redirect file=%q status=%q", res.File, res.Status)
+	}
+}
+
+func TestProgressRate(t *testing.T) {
+	p := Progress{Done: 300, Total: 1000, Resumed: 100, Elapsed: 2 * time.Second}
+	if p.Rate() != 100 {
+		t.Fatalf("rate=%v, want 100 B/s (resumed bytes don't count)", p.Rate())
+	}
+	if p.ETA() != 7*time.Second {
+		t.Fatalf("eta=%v, want 7s", p.ETA())
+	}
+	if (Progress{Done: 5, Total: -1, Elapsed: time.Second}).ETA() != 0 {
+		t.Fatal("unknown total must have no ETA")
+	}
+}
+
+// The timeout covers the wait for headers, not the file: a body trickling
+// in for longer than it still lands, a server slow to answer still fails.
+func TestDownloadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stalled" {
+			time.Sleep(1500 * time.Millisecond)
+		}
+		w.Header().Set("Content-Length", "5")
+		w.WriteHeader(http.StatusOK)
+		for _, b := range []byte("slow!") {
+			w.Write([]byte{b})
+			w.(http.Flusher).Flush()
+			time.Sleep(300 * time.Millisecond) // 1.5s in all
+		}
+	}))
+	defer server.Close()
+
+	send := func(path string) (*Response, error) {
+		r := testRequest(NewRequestID(), server.URL+path)
+		r.Settings = Settings{TimeoutSec: 1, Download: true, DownloadPath: filepath.Join(t.TempDir(), "slow.txt")}
+		defer DeleteHistory(r.ID)
+		return r.SendRequest(context.Background())
+	}
+
+	res, err := send("/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body != "slow!" {
+		t.Fatalf("body %q", res.Body)
+	}
+
+	if _, err := send("/stalled"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stalled headers: %v", err)
+	}
+}
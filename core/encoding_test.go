@@ -0,0 +1,112 @@
+package core
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func encodeWith(t *testing.T, coding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestSendRequestDecodesBody(t *testing.T) {
+	plain := []byte(strings.Repeat(`{"hello":"world"}`, 200))
+
+	var gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept-Encoding")
+		codings := r.URL.Query()["c"]
+
+		body := plain
+		for _, c := range codings {
+			body = encodeWith(t, c, body)
+		}
+		if len(codings) > 0 {
+			w.Header().Set("Content-Encoding", strings.ReplaceAll(strings.Join(codings, ", "), "raw-deflate", "deflate"))
+		}
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	send := func(query string, headers ...FormType) *Response {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL+"/?"+query)
+		*r.Headers = headers
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return res
+	}
+
+	for _, query := range []string{"c=gzip", "c=deflate", "c=raw-deflate", "c=br", "c=zstd", "c=gzip&c=br"} {
+		res := send(query)
+		if res.Body != string(plain) {
+			t.Errorf("%s: body not decoded (%d bytes)", query, len(res.Body))
+		}
+		if res.Encoding == "" || res.BodySize != int64(len(plain)) || res.WireSize >= res.BodySize || int64(len(res.Wire)) != res.WireSize {
+			t.Errorf("%s: encoding=%q body=%d wire=%d raw=%d", query, res.Encoding, res.BodySize, res.WireSize, len(res.Wire))
+		}
+	}
+	if gotAccept != AcceptEncoding {
+		t.Errorf("default Accept-Encoding = %q", gotAccept)
+	}
+
+	res := send("")
+	if res.Encoding != "" || res.Wire != nil || res.WireSize != res.BodySize || res.SizeText() != bytestoHuman(len(plain)) {
+		t.Errorf("identity: %q wire=%d body=%d", res.SizeText(), res.WireSize, res.BodySize)
+	}
+
+	// The request's own header wins
+	send("", FormType{Checked: true, Key: "Accept-Encoding", Value: "identity"})
+	if gotAccept != "identity" {
+		t.Errorf("explicit Accept-Encoding replaced with %q", gotAccept)
+	}
+
+	if res := send("c=br"); !strings.HasSuffix(res.SizeText(), " br)") {
+		t.Errorf("size text: %q", res.SizeText())
+	}
+
+	// HEAD keeps Content-Encoding but has no body to decode
+	r := testRequest(NewRequestID(), server.URL+"/?c=gzip")
+	r.Method = http.MethodHead
+	defer DeleteHistory(r.ID)
+	if _, err := r.SendRequest(context.Background()); err != nil {
+		t.Fatalf("HEAD with Content-Encoding: %v", err)
+	}
+}
@@ -2,8 +2,10 @@ package core
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -15,35 +17,143 @@ import (
 type Environment struct {
 	Name      string      `json:"Name"`
 	Variables *[]FormType `json:"Variables"`
+	Proxy     ProxyConfig `json:"Proxy,omitzero"` // unset: the global proxy
+	DNS       DNSConfig   `json:"DNS,omitzero"`
+
+	// Dotenv links a .env file whose variables sit under Variables. They're
+	// read into DotenvVars on load and on every change, never saved here.
+	Dotenv     string     `json:"Dotenv,omitempty"`
+	DotenvVars []FormType `json:"-"`
+	DotenvErr  error      `json:"-"`
 }
 
 type EnvStore struct {
-	Active string         `json:"Active"` // active env name; "" means none
-	Envs   []*Environment `json:"Envs"`
+	Active  string         `json:"Active"` // active env name; "" means none
+	Envs    []*Environment `json:"Envs"`
+	Globals *[]FormType    `json:"Globals,omitempty"` // shared by every environment, which overrides them
+	OSVars  string         `json:"OSVars,omitempty"`  // process env names to expose, see OSVars; below Globals
+}
+
+// Scope is the layer a variable's value comes from. Later scopes override
+// earlier ones: a session variable beats the environment's, which beats a
+// global, which beats an exposed OS variable.
+type Scope int
+
+const (
+	ScopeOS Scope = iota
+	ScopeGlobal
+	ScopeEnv
+	ScopeSession
+)
+
+func (s Scope) String() string {
+	return [...]string{"OS", "Global", "Environment", "Session"}[s]
 }
 
 var (
-	envMu      sync.RWMutex
+	envMu     sync.RWMutex
+	scopeVars [4]map[string]string
+
+	// activeVars is every scope merged, and varScopes where each key won
 	activeVars map[string]string
+	varScopes  map[string]Scope
 )
 
-// SetActiveVars swaps the variable set ApplyEnv substitutes from.
-// Pass nil to disable substitution.
+// SetActiveVars swaps the environment's layer of variables ApplyEnv
+// substitutes from. Pass nil to clear it.
 func SetActiveVars(vars map[string]string) {
+	setScopeVars(ScopeEnv, vars)
+}
+
+// SetOSVars swaps the layer of exposed process environment variables.
+func SetOSVars(vars map[string]string) {
+	setScopeVars(ScopeOS, vars)
+}
+
+// SetGlobalVars swaps the layer shared by every environment.
+func SetGlobalVars(vars map[string]string) {
+	setScopeVars(ScopeGlobal, vars)
+}
+
+// SetSessionVars swaps the transient layer over both; it's never saved.
+func SetSessionVars(vars map[string]string) {
+	setScopeVars(ScopeSession, vars)
+}
+
+func setScopeVars(scope Scope, vars map[string]string) {
 	envMu.Lock()
-	activeVars = vars
-	envMu.Unlock()
+	defer envMu.Unlock()
+
+	scopeVars[scope] = vars
+
+	activeVars = make(map[string]string)
+	varScopes = make(map[string]Scope)
+	for s, layer := range scopeVars {
+		for k, v := range layer {
+			activeVars[k] = v
+			varScopes[k] = Scope(s)
+		}
+	}
+}
+
+// VarInfo describes a defined variable for display.
+type VarInfo struct {
+	Value    string // its own placeholders resolved
+	Scope    Scope
+	Problems []string // nested placeholders left literal, e.g. a circular reference
+}
+
+// LookupVar describes key; ok is false when no scope defines it.
+func LookupVar(key string) (info VarInfo, ok bool) {
+	envMu.RLock()
+	defer envMu.RUnlock()
+
+	if _, ok = activeVars[key]; !ok {
+		return info, false
+	}
+
+	e := &expander{vars: activeVars, missing: &info.Problems}
+	info.Value, _ = e.variable(key)
+	info.Scope = varScopes[key]
+	return info, true
+}
+
+// VarNames lists every defined variable, sorted.
+func VarNames() []string {
+	envMu.RLock()
+	defer envMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(activeVars))
+}
+
+// UnresolvedIn describes the placeholders in s that ApplyEnv would leave
+// literal; nil when there are none.
+func UnresolvedIn(s string) []string {
+	var missing []string
+	applyEnv(s, &missing)
+	return missing
 }
 
-// VarMap returns the checked, non-empty-key variables. Nil-safe so callers
-// can chain store.ActiveEnv().VarMap().
+// VarMap returns the checked, non-empty-key variables, over the linked
+// .env file's. Nil-safe so callers can chain store.ActiveEnv().VarMap().
 func (e *Environment) VarMap() map[string]string {
+	if e == nil {
+		return VarsOf(nil)
+	}
+
+	vars := VarsOf(&e.DotenvVars)
+	maps.Copy(vars, VarsOf(e.Variables))
+	return vars
+}
+
+// VarsOf returns rows' checked, non-empty-key variables.
+func VarsOf(rows *[]FormType) map[string]string {
 	vars := make(map[string]string)
-	if e == nil || e.Variables == nil {
+	if rows == nil {
 		return vars
 	}
 
-	for _, v := range *e.Variables {
+	for _, v := range *rows {
 		if v.Checked && v.Key != "" {
 			vars[v.Key] = v.Value
 		}
@@ -67,10 +177,16 @@ func (s *EnvStore) ActiveEnv() *Environment {
 	return nil
 }
 
-// ApplyEnv replaces every {{key}} with the active environment's value.
-// Unknown keys stay literal, like Postman.
-// ponytail: exact {{key}} only — no {{ key }} whitespace trimming.
+// ApplyEnv substitutes {{key}} (whitespace inside the braces is ignored)
+// with the active environment's value and {{$func args}} with a built-in's
+// result — see templateFuncs. Anything unresolved stays literal, like
+// Postman.
 func ApplyEnv(s string) string {
+	return applyEnv(s, nil)
+}
+
+// applyEnv is ApplyEnv, describing unresolved placeholders in *missing.
+func applyEnv(s string, missing *[]string) string {
 	if !strings.Contains(s, "{{") {
 		return s
 	}
@@ -78,44 +194,142 @@ func ApplyEnv(s string) string {
 	envMu.RLock()
 	defer envMu.RUnlock()
 
-	for k, v := range activeVars {
-		s = strings.ReplaceAll(s, "{{"+k+"}}", v)
-	}
-
-	return s
+	return expand(s, activeVars, missing)
 }
 
 // ResolveEnv returns a deep copy with {{var}} placeholders substituted in
 // the same fields SendRequest substitutes at send time. Used for codegen so
 // the emitted snippet is runnable as-is.
 func (r *Request) ResolveEnv() *Request {
+	return r.resolveWith(ApplyEnv)
+}
+
+// Unresolved describes the placeholders a send would leave literal:
+// undefined variables and failing {{$func}} calls.
+func (r *Request) Unresolved() []string {
+	var missing []string
+	r.sent().resolveWith(func(s string) string { return applyEnv(s, &missing) })
+	return missing
+}
+
+// sent is a copy holding only what SendRequest would send: checked rows,
+// the chosen body and the chosen auth's fields.
+func (r *Request) sent() *Request {
+	c := r.Clone()
+	c.QueryParams = nil // already in the URL
+
+	checked := func(rows *[]FormType) *[]FormType {
+		if rows == nil {
+			return nil
+		}
+		var out []FormType
+		for _, row := range *rows {
+			if row.Checked {
+				out = append(out, row)
+			}
+		}
+		return &out
+	}
+	c.Headers = checked(c.Headers)
+
+	body := Body{}
+	switch c.BodyType {
+	case "JSON":
+		body.Json = c.Body.Json
+	case "XML":
+		body.Xml = c.Body.Xml
+	case "Text":
+		body.Text = c.Body.Text
+	case "Form", "URL Encoded":
+		body.Form = checked(c.Body.Form)
+	case "Binary":
+		body.Binary, body.BinaryType = c.Body.Binary, c.Body.BinaryType
+	}
+	c.Body = body
+
+	if a := c.Auth; a != nil {
+		c.Auth = &Auth{}
+		switch c.AuthType {
+		case "Basic":
+			c.Auth.BasicUser, c.Auth.BasicPass = a.BasicUser, a.BasicPass
+		case "Bearer":
+			c.Auth.BearerAuth = a.BearerAuth
+		case "API Key":
+			c.Auth.APIKeyName, c.Auth.APIKeyValue = a.APIKeyName, a.APIKeyValue
+		case "OAuth2":
+			c.Auth.OAuthTokenURL, c.Auth.OAuthClientID = a.OAuthTokenURL, a.OAuthClientID
+			c.Auth.OAuthClientSecret, c.Auth.OAuthScope = a.OAuthClientSecret, a.OAuthScope
+		case "JWT":
+			c.Auth.JWTAlgorithm, c.Auth.JWTKey = a.JWTAlgorithm, a.JWTKey
+			c.Auth.JWTHeader, c.Auth.JWTClaims, c.Auth.JWTTimeClaims = a.JWTHeader, a.JWTClaims, a.JWTTimeClaims
+			c.Auth.JWTIn, c.Auth.JWTName, c.Auth.JWTPrefix = a.JWTIn, a.JWTName, a.JWTPrefix
+		}
+	}
+
+	if !c.Settings.Download {
+		c.Settings.DownloadPath = ""
+	}
+	return c
+}
+
+func (r *Request) resolveWith(apply func(string) string) *Request {
 	c := r.Clone()
-	c.URL = ApplyEnv(c.URL)
+	c.URL = apply(c.URL)
 
 	applyRows := func(rows *[]FormType) {
 		if rows == nil {
 			return
 		}
 		for i, row := range *rows {
-			(*rows)[i].Key = ApplyEnv(row.Key)
-			(*rows)[i].Value = ApplyEnv(row.Value)
+			(*rows)[i].Key = apply(row.Key)
+			(*rows)[i].Value = apply(row.Value)
 		}
 	}
 	applyRows(c.Headers)
 	applyRows(c.QueryParams)
 	applyRows(c.Body.Form)
 
-	c.Body.Json = ApplyEnv(c.Body.Json)
-	c.Body.Xml = ApplyEnv(c.Body.Xml)
-	c.Body.Text = ApplyEnv(c.Body.Text)
+	c.Body.Json = apply(c.Body.Json)
+	c.Body.Xml = apply(c.Body.Xml)
+	c.Body.Text = apply(c.Body.Text)
+	c.Body.Binary = apply(c.Body.Binary)
+	c.Body.BinaryType = apply(c.Body.BinaryType)
 
 	if c.Auth != nil {
-		c.Auth.BasicUser = ApplyEnv(c.Auth.BasicUser)
-		c.Auth.BasicPass = ApplyEnv(c.Auth.BasicPass)
-		c.Auth.BearerAuth = ApplyEnv(c.Auth.BearerAuth)
-		c.Auth.APIKeyName = ApplyEnv(c.Auth.APIKeyName)
-		c.Auth.APIKeyValue = ApplyEnv(c.Auth.APIKeyValue)
+		c.Auth.BasicUser = apply(c.Auth.BasicUser)
+		c.Auth.BasicPass = apply(c.Auth.BasicPass)
+		c.Auth.BearerAuth = apply(c.Auth.BearerAuth)
+		c.Auth.APIKeyName = apply(c.Auth.APIKeyName)
+		c.Auth.APIKeyValue = apply(c.Auth.APIKeyValue)
+		c.Auth.OAuthTokenURL = apply(c.Auth.OAuthTokenURL)
+		c.Auth.OAuthClientID = apply(c.Auth.OAuthClientID)
+		c.Auth.OAuthClientSecret = apply(c.Auth.OAuthClientSecret)
+		c.Auth.OAuthScope = apply(c.Auth.OAuthScope)
+		c.Auth.JWTKey = apply(c.Auth.JWTKey)
+		c.Auth.JWTHeader = apply(c.Auth.JWTHeader)
+		c.Auth.JWTClaims = apply(c.Auth.JWTClaims)
+		c.Auth.JWTName = apply(c.Auth.JWTName)
+	}
+
+	c.Settings.DownloadPath = apply(c.Settings.DownloadPath)
+
+	cc := &c.Settings.ClientCert
+	cc.CertFile = apply(cc.CertFile)
+	cc.KeyFile = apply(cc.KeyFile)
+	cc.PFXFile = apply(cc.PFXFile)
+	cc.Passphrase = apply(cc.Passphrase)
+	cc.CAFile = apply(cc.CAFile)
+
+	// The proxy the send would use, so the snippet takes the same route
+	p := effectiveProxy(c.Settings)
+	p.URL, p.User, p.Pass, p.Bypass = apply(p.URL), apply(p.User), apply(p.Pass), apply(p.Bypass)
+	c.Settings.Proxy = p
+	d := effectiveDNS(c.Settings)
+	d.Server = apply(d.Server)
+	for i, o := range d.Overrides {
+		d.Overrides[i] = HostOverride{apply(o.Host), apply(o.Addr)}
 	}
+	c.Settings.DNS = d.resolved()
 
 	return c
 }
@@ -153,10 +367,16 @@ func LoadEnvStore() *EnvStore {
 	}
 
 	json.Unmarshal(content, store)
+	store.FillSecrets()
+	for _, e := range store.Envs {
+		e.ReloadDotenv()
+	}
 
 	return store
 }
 
+// SaveEnvStore writes the store with secret values redacted. A locked vault
+// still writes the file, then reports that new secrets weren't stored.
 func SaveEnvStore(store *EnvStore) error {
 	file, err := configFile("environments.json")
 
@@ -164,11 +384,17 @@ func SaveEnvStore(store *EnvStore) error {
 		return err
 	}
 
-	data, err := json.Marshal(store)
+	saved, vaultErr := store.redacted()
+
+	data, err := json.Marshal(saved)
 
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(file, data, 0o644)
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+
+	return vaultErr
 }
@@ -35,6 +35,35 @@ func TestApplyEnv(t *testing.T) {
 	}
 }
 
+// Session beats environment beats global, and each value knows its scope.
+func TestVarScopes(t *testing.T) {
+	SetGlobalVars(map[string]string{"host": "global.example.com", "org": "acme", "user": "g"})
+	SetActiveVars(map[string]string{"host": "dev.example.com", "user": "e"})
+	SetSessionVars(map[string]string{"user": "s"})
+	defer SetGlobalVars(nil)
+	defer SetActiveVars(nil)
+	defer SetSessionVars(nil)
+
+	if got := ApplyEnv("{{host}}/{{org}}/{{user}}"); got != "dev.example.com/acme/s" {
+		t.Fatalf("got %q", got)
+	}
+
+	for key, want := range map[string]Scope{"org": ScopeGlobal, "host": ScopeEnv, "user": ScopeSession} {
+		if info, ok := LookupVar(key); !ok || info.Scope != want {
+			t.Errorf("LookupVar(%q) scope = %v, %v; want %v", key, info.Scope, ok, want)
+		}
+	}
+
+	// Clearing a layer uncovers the one beneath
+	SetSessionVars(nil)
+	if info, _ := LookupVar("user"); info.Value != "e" || info.Scope != ScopeEnv {
+		t.Fatalf("after clearing the session: %q from %v", info.Value, info.Scope)
+	}
+	if _, ok := LookupVar("missing"); ok {
+		t.Fatal("undefined variable found")
+	}
+}
+
 // End-to-end: {{var}} must reach the wire substituted, while the saved
 // request keeps its placeholders.
 func TestSendRequestAppliesEnv(t *testing.T) {
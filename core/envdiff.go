@@ -0,0 +1,197 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// KeyDiff is one variable across the compared environments.
+type KeyDiff struct {
+	Key     string
+	Values  []string // per environment, in the order compared; "" where missing
+	Present []bool
+	Secret  bool // secret in any of them
+}
+
+// Same reports whether every environment has the key with one value.
+func (d KeyDiff) Same() bool {
+	for i := range d.Values {
+		if !d.Present[i] || d.Values[i] != d.Values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffEnvs lines up the effective variables (linked .env included) of envs
+// by key, sorted.
+func DiffEnvs(envs []*Environment) []KeyDiff {
+	vars := make([]map[string]string, len(envs))
+	secret := map[string]bool{}
+	keys := map[string]bool{}
+
+	for i, e := range envs {
+		vars[i] = e.VarMap()
+		for k := range vars[i] {
+			keys[k] = true
+		}
+		if e.Variables != nil {
+			for _, v := range *e.Variables {
+				secret[v.Key] = secret[v.Key] || v.Secret
+			}
+		}
+	}
+
+	var diffs []KeyDiff
+	for _, k := range slices.Sorted(maps.Keys(keys)) {
+		d := KeyDiff{Key: k, Values: make([]string, len(envs)), Present: make([]bool, len(envs)), Secret: secret[k]}
+		for i := range envs {
+			d.Values[i], d.Present[i] = vars[i][k]
+		}
+		diffs = append(diffs, d)
+	}
+
+	return diffs
+}
+
+// RenameKey renames a variable in every environment that has it, returning
+// how many changed. An environment already holding to keeps both rows.
+func (s *EnvStore) RenameKey(from, to string) int {
+	n := 0
+	for _, e := range s.Envs {
+		if e.Variables == nil {
+			continue
+		}
+		for i, v := range *e.Variables {
+			if v.Key == from {
+				(*e.Variables)[i].Key = to
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// AddKey adds key = value to every environment without it, before the
+// trailing empty row, returning how many changed.
+func (s *EnvStore) AddKey(key, value string) int {
+	n := 0
+	for _, e := range s.Envs {
+		if e.Variables == nil {
+			e.Variables = &[]FormType{}
+		}
+		rows := *e.Variables
+		if slices.ContainsFunc(rows, func(v FormType) bool { return v.Key == key }) {
+			continue
+		}
+
+		at := len(rows)
+		if at > 0 && rows[at-1].Key == "" && rows[at-1].Value == "" {
+			at--
+		}
+		*e.Variables = slices.Insert(rows, at, FormType{Checked: true, Key: key, Value: value})
+		n++
+	}
+	return n
+}
+
+// Duplicate copies e — variables, secrets, proxy, DNS and .env link — under
+// an unused "<name> copy" name, right after it.
+func (s *EnvStore) Duplicate(e *Environment) *Environment {
+	c := *e
+	c.DNS.Overrides = slices.Clone(e.DNS.Overrides)
+	c.DotenvVars = slices.Clone(e.DotenvVars)
+	if e.Variables != nil {
+		vars := slices.Clone(*e.Variables)
+		c.Variables = &vars
+	}
+
+	taken := func(name string) bool {
+		return slices.ContainsFunc(s.Envs, func(o *Environment) bool { return o.Name == name })
+	}
+	c.Name = e.Name + " copy"
+	for n := 2; taken(c.Name); n++ {
+		c.Name = fmt.Sprintf("%s copy %d", e.Name, n)
+	}
+
+	at := slices.Index(s.Envs, e) + 1
+	if at == 0 {
+		at = len(s.Envs)
+	}
+	s.Envs = slices.Insert(s.Envs, at, &c)
+	return &c
+}
+
+// SecretMask stands in for secret values in FormatVars text; ParseVars
+// keeps the old value for a key still showing it.
+const SecretMask = "••••••"
+
+// FormatVars writes rows as .env lines for raw editing: disabled rows
+// become "# KEY=value" and secret values SecretMask.
+func FormatVars(rows []FormType) string {
+	var b strings.Builder
+	for _, v := range rows {
+		if v.Key == "" && v.Value == "" {
+			continue
+		}
+		if !v.Checked {
+			b.WriteString("# ")
+		}
+		value := v.Value
+		if v.Secret {
+			value = SecretMask
+		}
+		b.WriteString(v.Key + "=" + quoteDotenv(value) + "\n")
+	}
+	return b.String()
+}
+
+// quoteDotenv double-quotes a value ParseDotenv wouldn't read back as-is.
+func quoteDotenv(v string) string {
+	if v == strings.TrimSpace(v) && !strings.ContainsAny(v, "\n\r\"'#\\") {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(v) + `"`
+}
+
+// ParseVars reads FormatVars text back into rows. Secret flags carry over
+// from prev by key, as do secret values left as SecretMask. A commented
+// line that parses as KEY=value is a disabled row; other comments drop.
+func ParseVars(text string, prev []FormType) ([]FormType, error) {
+	var b strings.Builder
+	disabled := map[string]bool{}
+	for line := range strings.Lines(text) {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "#"); ok {
+			vars, err := ParseDotenv([]byte(rest))
+			if err != nil || len(vars) != 1 {
+				b.WriteString("\n") // a plain comment; keeps error line numbers right
+				continue
+			}
+			disabled[vars[0].Key] = true
+			line = rest + "\n"
+		}
+		b.WriteString(line)
+	}
+
+	rows, err := ParseDotenv([]byte(b.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range rows {
+		rows[i].Checked = !disabled[v.Key]
+
+		j := slices.IndexFunc(prev, func(p FormType) bool { return p.Key == v.Key })
+		if j < 0 {
+			continue
+		}
+		rows[i].Secret = prev[j].Secret
+		if prev[j].Secret && v.Value == SecretMask {
+			rows[i].Value = prev[j].Value
+		}
+	}
+
+	return append(rows, FormType{Checked: true}), nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffEnvs(t *testing.T) {
+	dev := &Environment{Name: "dev", Variables: &[]FormType{
+		{Checked: true, Key: "host", Value: "dev.example.com"},
+		{Checked: true, Key: "token", Value: "d", Secret: true},
+		{Checked: true, Key: "debug", Value: "1"},
+		{Checked: true, Key: "region", Value: "eu"},
+	}}
+	prod := &Environment{Name: "prod", Variables: &[]FormType{
+		{Checked: true, Key: "host", Value: "api.example.com"},
+		{Checked: true, Key: "token", Value: "p"},
+		{Checked: false, Key: "debug", Value: "1"}, // disabled counts as missing
+		{Checked: true, Key: "region", Value: "eu"},
+	}}
+
+	got := DiffEnvs([]*Environment{dev, prod})
+	want := []KeyDiff{
+		{Key: "debug", Values: []string{"1", ""}, Present: []bool{true, false}},
+		{Key: "host", Values: []string{"dev.example.com", "api.example.com"}, Present: []bool{true, true}},
+		{Key: "region", Values: []string{"eu", "eu"}, Present: []bool{true, true}},
+		{Key: "token", Values: []string{"d", "p"}, Present: []bool{true, true}, Secret: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+
+	for i, same := range []bool{false, false, true, false} {
+		if got[i].Same() != same {
+			t.Errorf("%s: Same() = %v", got[i].Key, !same)
+		}
+	}
+}
+
+func TestBulkEdit(t *testing.T) {
+	dev := &Environment{Name: "dev", Variables: &[]FormType{{Checked: true, Key: "url", Value: "a"}, {Checked: true}}}
+	prod := &Environment{Name: "prod", Variables: &[]FormType{{Checked: true, Key: "url", Value: "b"}, {Checked: true, Key: "tenant", Value: "acme"}}}
+	store := &EnvStore{Envs: []*Environment{dev, prod}}
+
+	if n := store.RenameKey("url", "baseUrl"); n != 2 {
+		t.Fatalf("renamed %d", n)
+	}
+	if n := store.AddKey("tenant", "default"); n != 1 {
+		t.Fatalf("added to %d", n)
+	}
+
+	// Added before dev's trailing empty row; prod's tenant untouched
+	wantDev := []FormType{{Checked: true, Key: "baseUrl", Value: "a"}, {Checked: true, Key: "tenant", Value: "default"}, {Checked: true}}
+	if !reflect.DeepEqual(*dev.Variables, wantDev) {
+		t.Fatalf("dev = %+v", *dev.Variables)
+	}
+	if v := prod.VarMap(); v["baseUrl"] != "b" || v["tenant"] != "acme" {
+		t.Fatalf("prod = %v", v)
+	}
+
+	c1 := store.Duplicate(dev)
+	c2 := store.Duplicate(dev)
+	if c1.Name != "dev copy" || c2.Name != "dev copy 2" {
+		t.Fatalf("names %q, %q", c1.Name, c2.Name)
+	}
+	if names := []string{store.Envs[0].Name, store.Envs[1].Name, store.Envs[2].Name, store.Envs[3].Name}; !reflect.DeepEqual(names, []string{"dev", "dev copy 2", "dev copy", "prod"}) {
+		t.Fatalf("order %q", names)
+	}
+
+	// A deep copy: editing the duplicate leaves the original alone
+	(*c1.Variables)[0].Value = "changed"
+	if (*dev.Variables)[0].Value != "a" {
+		t.Fatal("duplicate shares rows with the original")
+	}
+}
+
+func TestRawVars(t *testing.T) {
+	rows := []FormType{
+		{Checked: true, Key: "host", Value: "api.example.com"},
+		{Checked: false, Key: "debug", Value: "1"},
+		{Checked: true, Key: "token", Value: "s3cret", Secret: true},
+		{Checked: true, Key: "note", Value: ` two words # "quoted" \ back` + "\nnext line"},
+		{Checked: true},
+	}
+
+	text := FormatVars(rows)
+	want := "host=api.example.com\n# debug=1\ntoken=" + SecretMask + "\nnote=\" two words # \\\"quoted\\\" \\\\ back\\nnext line\"\n"
+	if text != want {
+		t.Fatalf("FormatVars:\n%s\nwant\n%s", text, want)
+	}
+
+	got, err := ParseVars(text, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("round trip:\n%+v\nwant\n%+v", got, rows)
+	}
+
+	// Edited text: a new secret value sticks, a plain comment drops
+	got, err = ParseVars("# just a note\ntoken=rotated\nnew=1\n", rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want2 := []FormType{
+		{Checked: true, Key: "token", Value: "rotated", Secret: true},
+		{Checked: true, Key: "new", Value: "1"},
+		{Checked: true},
+	}
+	if !reflect.DeepEqual(got, want2) {
+		t.Fatalf("edited:\n%+v\nwant\n%+v", got, want2)
+	}
+
+	if _, err := ParseVars("ok=1\n\nbroken", rows); err == nil || err.Error() != "line 3: want KEY=value" {
+		t.Fatalf("err = %v", err)
+	}
+}
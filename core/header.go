@@ -0,0 +1,75 @@
+package core
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Header is a response's header fields, one per value, so every
+// Set-Cookie, Link and Vary line survives. They're in the order received
+// when the wire log captured the head (see wireLog.header); otherwise
+// names are sorted, as Go's header map doesn't keep the order between
+// them, and a repeated name's values stay in order.
+type Header []HeaderField
+
+type HeaderField struct {
+	Name  string // canonical form, "Set-Cookie"
+	Value string
+}
+
+// newHeader lists h's fields as a Header.
+func newHeader(h http.Header) Header {
+	var fields Header
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		for _, v := range h[name] {
+			fields = append(fields, HeaderField{name, v})
+		}
+	}
+	return fields
+}
+
+// orderedHeader lists h's fields in the order of names, the field names of
+// the head as received, one per line. Values come from h, which Go parsed
+// from the same lines; any the names don't account for follow sorted.
+func orderedHeader(h http.Header, names []string) Header {
+	var fields Header
+	used := map[string]int{}
+	for _, name := range names {
+		if i := used[name]; i < len(h[name]) {
+			fields = append(fields, HeaderField{name, h[name][i]})
+			used[name]++
+		}
+	}
+	for _, f := range newHeader(h) {
+		if used[f.Name] > 0 {
+			used[f.Name]--
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Get is the first value of name, matched case-insensitively; "" when
+// absent.
+func (h Header) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values is every value of name, in order.
+func (h Header) Values(name string) []string {
+	var values []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			values = append(values, f.Value)
+		}
+	}
+	return values
+}
@@ -188,7 +188,14 @@ func saveRequestData(request *Request) (bool, error) {
 		return false, err
 	}
 
-	jsondata, err := json.Marshal(request)
+	// A locked vault mustn't fail the send: the credentials are still
+	// blanked on disk, just not remembered.
+	saved, err := redactRequest(request)
+	if err != nil && !errors.Is(err, ErrVaultLocked) {
+		return false, err
+	}
+
+	jsondata, err := json.Marshal(saved)
 
 	if err != nil {
 		return false, err
@@ -219,6 +226,10 @@ func ClearHistory() error {
 	}
 
 	for _, entry := range entries {
+		if request, err := LoadRequest(strings.TrimSuffix(entry.Name(), ".json")); err == nil {
+			forgetRequest(request)
+		}
+
 		if err := os.Remove(filepath.Join(myapiPath, entry.Name())); err != nil {
 			return err
 		}
@@ -234,6 +245,10 @@ func DeleteHistory(id string) error {
 		return err
 	}
 
+	if request, err := LoadRequest(id); err == nil {
+		forgetRequest(request)
+	}
+
 	return os.Remove(file)
 }
 
@@ -257,6 +272,8 @@ func LoadRequest(id string) (*Request, error) {
 		return nil, err
 	}
 
+	request.FillSecrets()
+
 	return request, nil
 
 }
@@ -281,6 +298,10 @@ func CloneHistory(id string) error {
 
 	request.ID = "" // emptying the old ID so it regenerates
 
+	// The copy gets its own vault entry so editing one doesn't change both.
+	request.FillSecrets()
+	request.forgetSecretRefs()
+
 	_, err = saveRequestData(&request)
 
 	if err != nil {
@@ -0,0 +1,471 @@
+package core
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JWTAlgorithms are the signing algorithms the "JWT" auth type offers.
+var JWTAlgorithms = []string{"HS256", "RS256", "ES256"}
+
+// SignJWT mints a fresh token from the auth's JWT settings. Header and
+// claims are JSON with {{var}} substitution. A time claim (exp, nbf, iat,
+// auth_time and any named in JWTTimeClaims) of the form now, now+5m or
+// now-1h becomes a Unix timestamp, so it stays relative to the send; other
+// claims are signed as written.
+func (a *Auth) SignJWT() (string, error) {
+	alg := a.JWTAlgorithm
+	if alg == "" {
+		alg = "HS256"
+	}
+
+	header := map[string]any{}
+	if h := strings.TrimSpace(ApplyEnv(a.JWTHeader)); h != "" {
+		if err := json.Unmarshal([]byte(h), &header); err != nil {
+			return "", fmt.Errorf("jwt header: %w", err)
+		}
+	}
+	header["alg"] = alg // the chosen algorithm always wins over the JSON
+	if _, ok := header["typ"]; !ok {
+		header["typ"] = "JWT"
+	}
+
+	claims := map[string]any{}
+	if c := strings.TrimSpace(ApplyEnv(a.JWTClaims)); c != "" {
+		if err := json.Unmarshal([]byte(c), &claims); err != nil {
+			return "", fmt.Errorf("jwt claims: %w", err)
+		}
+	}
+	now := time.Now()
+	for _, k := range a.timeClaims() {
+		if v, ok := claims[k]; ok {
+			claims[k] = relativeTime(v, now)
+		}
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	b64 := base64.RawURLEncoding
+	signingInput := b64.EncodeToString(headerJSON) + "." + b64.EncodeToString(claimsJSON)
+
+	sig, err := jwtSign(alg, ApplyEnv(a.JWTKey), []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + b64.EncodeToString(sig), nil
+}
+
+// jwtTimeClaims are the registered claims holding a NumericDate.
+var jwtTimeClaims = []string{"exp", "nbf", "iat", "auth_time"}
+
+// timeClaims is jwtTimeClaims plus the names listed in JWTTimeClaims.
+func (a *Auth) timeClaims() []string {
+	names := slices.Clone(jwtTimeClaims)
+	for name := range strings.SplitSeq(a.JWTTimeClaims, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+var relativeTimeRe = regexp.MustCompile(`^now\s*(?:([+-])\s*(\d+)\s*([smhd]))?$`)
+
+// relativeTime turns "now", "now+5m", "now-1d" into Unix seconds relative
+// to now. Anything else is returned unchanged.
+func relativeTime(v any, now time.Time) any {
+	t, ok := v.(string)
+	if !ok {
+		return v
+	}
+	m := relativeTimeRe.FindStringSubmatch(strings.TrimSpace(t))
+	if m == nil {
+		return v
+	}
+	ts := now
+	if m[1] != "" {
+		n, _ := strconv.Atoi(m[2])
+		unit := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}[m[3]]
+		d := time.Duration(n) * unit
+		if m[1] == "-" {
+			d = -d
+		}
+		ts = now.Add(d)
+	}
+	return ts.Unix()
+}
+
+// jwtSign signs input with key: the shared secret for HS256, a PEM private
+// key (pasted, or a path to one) for RS256/ES256.
+func jwtSign(alg, key string, input []byte) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("jwt: no signing key")
+	}
+
+	if alg == "HS256" {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+
+	priv, err := parsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(input)
+
+	switch alg {
+	case "RS256":
+		k, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("jwt: RS256 needs an RSA private key")
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+
+	case "ES256":
+		k, ok := priv.(*ecdsa.PrivateKey)
+		if !ok || k.Curve.Params().BitSize != 256 {
+			return nil, errors.New("jwt: ES256 needs a P-256 EC private key")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS wants fixed-width r||s, not the ASN.1 form
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	}
+
+	return nil, fmt.Errorf("jwt: unsupported algorithm %s", alg)
+}
+
+// parsePrivateKey reads a PEM private key given inline or as a file path,
+// in PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) form.
+func parsePrivateKey(key string) (crypto.Signer, error) {
+	data := []byte(key)
+	if !strings.Contains(key, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(strings.TrimSpace(key)); err != nil {
+			return nil, fmt.Errorf("jwt key: %w", err)
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt key: no PEM block found")
+	}
+
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := k.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+
+	return nil, errors.New("jwt key: unsupported private key format")
+}
+
+// DecodedJWT is a token split into its readable parts. Nothing is verified
+// by decoding; see VerifyJWT.
+type DecodedJWT struct {
+	Raw       string
+	Header    map[string]any
+	Claims    map[string]any
+	Signature []byte
+
+	// Registered time claims, zero when absent.
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiresAt time.Time
+}
+
+// Expired reports whether exp is set and has passed at now.
+func (d *DecodedJWT) Expired(now time.Time) bool {
+	return !d.ExpiresAt.IsZero() && now.After(d.ExpiresAt)
+}
+
+// DecodeJWT parses a compact JWS. A "Bearer " prefix is tolerated so
+// header values can be passed as-is.
+func DecodeJWT(token string) (*DecodedJWT, error) {
+	token = strings.TrimSpace(token)
+	if prefix, rest, ok := strings.Cut(token, " "); ok && strings.EqualFold(prefix, "bearer") {
+		token = strings.TrimSpace(rest)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt: expected three dot-separated parts")
+	}
+
+	b64 := base64.RawURLEncoding
+	d := &DecodedJWT{Raw: token}
+
+	h, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("jwt header: %w", err)
+	}
+	if err := json.Unmarshal(h, &d.Header); err != nil {
+		return nil, fmt.Errorf("jwt header: %w", err)
+	}
+
+	c, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("jwt claims: %w", err)
+	}
+	// UseNumber keeps large ids and timestamps exact
+	dec := json.NewDecoder(strings.NewReader(string(c)))
+	dec.UseNumber()
+	if err := dec.Decode(&d.Claims); err != nil {
+		return nil, fmt.Errorf("jwt claims: %w", err)
+	}
+
+	if d.Signature, err = b64.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("jwt signature: %w", err)
+	}
+
+	d.IssuedAt = claimTime(d.Claims["iat"])
+	d.NotBefore = claimTime(d.Claims["nbf"])
+	d.ExpiresAt = claimTime(d.Claims["exp"])
+
+	return d, nil
+}
+
+func claimTime(v any) time.Time {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(f), 0)
+}
+
+var jwtRe = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// FindJWTs returns the distinct JWT-looking substrings of s, in order, up to
+// max. The header must start "eyJ" (base64 for `{"`) and every candidate
+// must decode, which keeps false positives rare.
+func FindJWTs(s string, max int) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, m := range jwtRe.FindAllString(s, -1) {
+		if seen[m] {
+			continue
+		}
+		if _, err := DecodeJWT(m); err != nil {
+			continue
+		}
+		seen[m] = true
+		found = append(found, m)
+		if len(found) == max {
+			break
+		}
+	}
+	return found
+}
+
+// VerifyJWT checks the token's signature. key is an HMAC secret, a PEM
+// public key or certificate, or a JWKS document — each given inline or as
+// a file path. Only the signature is checked; exp/nbf are for the caller.
+func VerifyJWT(token, key string) error {
+	d, err := DecodeJWT(token)
+	if err != nil {
+		return err
+	}
+
+	alg, _ := d.Header["alg"].(string)
+	hash, ok := map[string]crypto.Hash{
+		"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+		"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+		"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+		"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	}[alg]
+	if !ok {
+		return fmt.Errorf("jwt: cannot verify alg %q", alg)
+	}
+
+	input := d.Raw[:strings.LastIndexByte(d.Raw, '.')]
+
+	if strings.HasPrefix(alg, "HS") {
+		if key == "" {
+			return errors.New("jwt: no secret given")
+		}
+		mac := hmac.New(hash.New, []byte(key))
+		mac.Write([]byte(input))
+		if !hmac.Equal(mac.Sum(nil), d.Signature) {
+			return errors.New("jwt: signature does not match")
+		}
+		return nil
+	}
+
+	kid, _ := d.Header["kid"].(string)
+	pubs, err := parsePublicKeys(key, kid)
+	if err != nil {
+		return err
+	}
+
+	h := hash.New()
+	h.Write([]byte(input))
+	digest := h.Sum(nil)
+
+	for _, pub := range pubs {
+		switch k := pub.(type) {
+		case *rsa.PublicKey:
+			if strings.HasPrefix(alg, "PS") {
+				if rsa.VerifyPSS(k, hash, digest, d.Signature, nil) == nil {
+					return nil
+				}
+			} else if strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(k, hash, digest, d.Signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			size := (k.Curve.Params().BitSize + 7) / 8
+			if strings.HasPrefix(alg, "ES") && len(d.Signature) == 2*size {
+				r := new(big.Int).SetBytes(d.Signature[:size])
+				s := new(big.Int).SetBytes(d.Signature[size:])
+				if ecdsa.Verify(k, digest, r, s) {
+					return nil
+				}
+			}
+		}
+	}
+
+	return errors.New("jwt: signature does not match")
+}
+
+// parsePublicKeys reads PEM (public key or certificate) or a JWKS document,
+// inline or from a file. For a JWKS, kid narrows the set when it matches.
+func parsePublicKeys(key, kid string) ([]crypto.PublicKey, error) {
+	data := []byte(strings.TrimSpace(key))
+	if len(data) == 0 {
+		return nil, errors.New("jwt: no public key given")
+	}
+	if data[0] != '{' && !strings.Contains(key, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(strings.TrimSpace(key)); err != nil {
+			return nil, fmt.Errorf("jwt key: %w", err)
+		}
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return parseJWKS(data, kid)
+	}
+
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			keys = append(keys, cert.PublicKey)
+		} else if k, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+			keys = append(keys, k)
+		} else if k, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwt key: no public key found in PEM")
+	}
+	return keys, nil
+}
+
+// parseJWKS reads RSA and EC keys from a JWKS ({"keys":[...]}) or a single
+// JWK object.
+func parseJWKS(data []byte, kid string) ([]crypto.PublicKey, error) {
+	type jwk struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		var single jwk
+		if json.Unmarshal(data, &single) == nil && single.Kty != "" {
+			set.Keys = []jwk{single}
+		}
+	}
+
+	b64 := base64.RawURLEncoding
+	var keys, matched []crypto.PublicKey
+	for _, k := range set.Keys {
+		var pub crypto.PublicKey
+		switch k.Kty {
+		case "RSA":
+			n, errN := b64.DecodeString(k.N)
+			e, errE := b64.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			curve := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}[k.Crv]
+			x, errX := b64.DecodeString(k.X)
+			y, errY := b64.DecodeString(k.Y)
+			if curve == nil || errX != nil || errY != nil {
+				continue
+			}
+			pub = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		default:
+			continue
+		}
+		keys = append(keys, pub)
+		if kid != "" && k.Kid == kid {
+			matched = append(matched, pub)
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched, nil
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no usable RSA or EC keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,215 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeJWT splits a token into its decoded header, claims and signature.
+func decodeJWT(t *testing.T, token string) (header, claims map[string]any, signingInput string, sig []byte) {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("not a JWT: %q", token)
+	}
+	b64 := base64.RawURLEncoding
+	h, _ := b64.DecodeString(parts[0])
+	c, _ := b64.DecodeString(parts[1])
+	sig, _ = b64.DecodeString(parts[2])
+	if err := json.Unmarshal(h, &header); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(c, &claims); err != nil {
+		t.Fatal(err)
+	}
+	return header, claims, parts[0] + "." + parts[1], sig
+}
+
+func TestSignJWTHS256(t *testing.T) {
+	SetActiveVars(map[string]string{"user": "alice"})
+	defer SetActiveVars(nil)
+
+	a := &Auth{
+		JWTKey:    "shh",
+		JWTHeader: `{"kid":"k1","alg":"none"}`,
+		JWTClaims: `{"sub":"{{user}}","iat":"now","exp":"now+5m","nbf":"now - 1h","note":"nowhere",` +
+			`"status":"now","ctx":{"mode":"now+1d"},"refresh_at":"now+1h"}`,
+		JWTTimeClaims: "refresh_at",
+	}
+	token, err := a.SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, claims, input, sig := decodeJWT(t, token)
+	if header["alg"] != "HS256" || header["typ"] != "JWT" || header["kid"] != "k1" {
+		t.Fatalf("header: %v", header)
+	}
+
+	now := float64(time.Now().Unix())
+	if claims["sub"] != "alice" || claims["note"] != "nowhere" {
+		t.Fatalf("claims: %v", claims)
+	}
+	// Only time claims are rewritten
+	if claims["status"] != "now" || claims["ctx"].(map[string]any)["mode"] != "now+1d" {
+		t.Fatalf("non-time claims rewritten: %v", claims)
+	}
+	if at := claims["refresh_at"].(float64); at < now+3590 || at > now+3610 {
+		t.Fatalf("named time claim not now+1h: %v", at)
+	}
+	if exp := claims["exp"].(float64); exp < now+290 || exp > now+310 {
+		t.Fatalf("exp not now+5m: %v (now %v)", exp, now)
+	}
+	if nbf := claims["nbf"].(float64); nbf < now-3610 || nbf > now-3590 {
+		t.Fatalf("nbf not now-1h: %v", nbf)
+	}
+
+	mac := hmac.New(sha256.New, []byte("shh"))
+	mac.Write([]byte(input))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		t.Fatal("HS256 signature does not verify")
+	}
+}
+
+func TestSignJWTAsymmetric(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	a := &Auth{JWTAlgorithm: "RS256", JWTKey: string(rsaPEM), JWTClaims: `{"sub":"x"}`}
+	token, err := a.SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, input, sig := decodeJWT(t, token)
+	digest := sha256.Sum256([]byte(input))
+	if err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("RS256 signature: %v", err)
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	a = &Auth{JWTAlgorithm: "ES256", JWTKey: string(ecPEM)}
+	token, err = a.SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, input, sig = decodeJWT(t, token)
+	digest = sha256.Sum256([]byte(input))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if len(sig) != 64 || !ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s) {
+		t.Fatal("ES256 signature does not verify")
+	}
+
+	// wrong key type for the algorithm is an error, not a bad token
+	a = &Auth{JWTAlgorithm: "RS256", JWTKey: string(ecPEM)}
+	if _, err := a.SignJWT(); err == nil {
+		t.Fatal("RS256 with an EC key should error")
+	}
+}
+
+func TestSendRequestJWT(t *testing.T) {
+	var gotAuthz, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthz = r.Header.Get("Authorization")
+		gotQuery = r.URL.Query().Get("jwt")
+	}))
+	defer server.Close()
+
+	req := testRequest("jwtheader", server.URL)
+	req.AuthType = "JWT"
+	req.Auth = &Auth{JWTKey: "k", JWTClaims: `{"exp":"now+1m"}`}
+	defer DeleteHistory("jwtheader")
+	if _, err := req.SendRequest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(gotAuthz, "Bearer ey") {
+		t.Fatalf("authorization: %q", gotAuthz)
+	}
+
+	req = testRequest("jwtquery", server.URL)
+	req.AuthType = "JWT"
+	req.Auth = &Auth{JWTKey: "k", JWTIn: "Query", JWTName: "jwt"}
+	defer DeleteHistory("jwtquery")
+	if _, err := req.SendRequest(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(gotQuery, ".") != 2 {
+		t.Fatalf("query token: %q", gotQuery)
+	}
+}
+
+func TestDecodeAndVerifyJWT(t *testing.T) {
+	a := &Auth{JWTKey: "shh", JWTHeader: `{"kid":"k1"}`, JWTClaims: `{"sub":"bob","exp":"now-1m","iat":"now-2m"}`}
+	token, err := a.SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := DecodeJWT("Bearer " + token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Claims["sub"] != "bob" || d.Header["kid"] != "k1" {
+		t.Fatalf("decoded: %+v", d)
+	}
+	if !d.Expired(time.Now()) || d.IssuedAt.IsZero() {
+		t.Fatalf("exp/iat not parsed: exp=%v iat=%v", d.ExpiresAt, d.IssuedAt)
+	}
+
+	if err := VerifyJWT(token, "shh"); err != nil {
+		t.Fatalf("HS256 verify: %v", err)
+	}
+	if err := VerifyJWT(token, "wrong"); err == nil {
+		t.Fatal("wrong secret verified")
+	}
+
+	// ES256 against a JWKS document with a matching kid
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	a = &Auth{JWTAlgorithm: "ES256", JWTKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), JWTHeader: `{"kid":"ec1"}`}
+	token, err = a.SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := base64.RawURLEncoding
+	jwks := `{"keys":[{"kty":"EC","kid":"ec1","crv":"P-256","x":"` + b64.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))) +
+		`","y":"` + b64.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))) + `"}]}`
+	if err := VerifyJWT(token, jwks); err != nil {
+		t.Fatalf("ES256 JWKS verify: %v", err)
+	}
+
+	pubDER, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err := VerifyJWT(token, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))); err != nil {
+		t.Fatalf("ES256 PEM verify: %v", err)
+	}
+}
+
+func TestFindJWTs(t *testing.T) {
+	token, _ := (&Auth{JWTKey: "k", JWTClaims: `{"a":1}`}).SignJWT()
+	body := `{"access_token":"` + token + `","refresh":"` + token + `","id":"eyJnot.a.jwt"}`
+
+	found := FindJWTs(body, 10)
+	if len(found) != 1 || found[0] != token {
+		t.Fatalf("found %v", found)
+	}
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,20 +30,16 @@ var (
 // OAuth2 settings. Tokens are cached in memory per tokenURL+clientID+scope
 // until shortly before expiry, so repeated sends don't round-trip to the
 // token endpoint.
-func oauthToken(ctx context.Context, a *Auth, skipTLS bool) (string, error) {
+func oauthToken(ctx context.Context, a *Auth, s Settings) (string, error) {
 	tokenURL := ApplyEnv(a.OAuthTokenURL)
 	clientID := ApplyEnv(a.OAuthClientID)
 	secret := ApplyEnv(a.OAuthClientSecret)
 	scope := ApplyEnv(a.OAuthScope)
 
 	key := tokenURL + "\x00" + clientID + "\x00" + scope
-
-	oauthMu.Lock()
-	if e, ok := oauthCache[key]; ok && time.Now().Before(e.expiry) {
-		oauthMu.Unlock()
-		return e.token, nil
+	if token, ok := cachedToken(key); ok {
+		return token, nil
 	}
-	oauthMu.Unlock()
 
 	form := url.Values{"grant_type": {"client_credentials"}}
 	if scope != "" {
@@ -58,10 +53,13 @@ func oauthToken(ctx context.Context, a *Auth, skipTLS bool) (string, error) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.SetBasicAuth(clientID, secret)
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	if skipTLS {
-		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	// same TLS settings as the request itself: a token endpoint behind
+	// mutual TLS needs the client certificate too
+	client, _, err := newClient(s, req.URL.Host, 30*time.Second)
+	if err != nil {
+		return "", err
 	}
+	defer closeClient(client)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -95,3 +93,20 @@ func oauthToken(ctx context.Context, a *Auth, skipTLS bool) (string, error) {
 
 	return tr.AccessToken, nil
 }
+
+// cachedOAuthToken is oauthToken without the fetch: ok is false when a
+// send would have to ask the token endpoint.
+func cachedOAuthToken(a *Auth) (string, bool) {
+	return cachedToken(ApplyEnv(a.OAuthTokenURL) + "\x00" + ApplyEnv(a.OAuthClientID) + "\x00" + ApplyEnv(a.OAuthScope))
+}
+
+func cachedToken(key string) (string, bool) {
+	oauthMu.Lock()
+	defer oauthMu.Unlock()
+
+	e, ok := oauthCache[key]
+	if !ok || !time.Now().Before(e.expiry) {
+		return "", false
+	}
+	return e.token, true
+}
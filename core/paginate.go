@@ -0,0 +1,303 @@
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How FetchAll finds the next page.
+const (
+	PageLink    = "Link header" // the Link header's rel="next" URL
+	PageNextURL = "Next URL"    // a URL at NextPath in the JSON body
+	PageCursor  = "Cursor"      // a value at NextPath, sent back as CursorParam
+)
+
+const (
+	defaultMaxPages    = 20
+	defaultCursorParam = "cursor"
+)
+
+// Pagination tells FetchAll how to walk a list endpoint. The zero value
+// follows Link headers for up to 20 pages.
+type Pagination struct {
+	Mode        string `json:"Mode,omitempty"`        // PageLink, PageNextURL or PageCursor; empty → PageLink
+	NextPath    string `json:"NextPath,omitempty"`    // dotted JSON path to the next URL or cursor: "links.next", "meta.cursor"
+	CursorParam string `json:"CursorParam,omitempty"` // query parameter the cursor goes in; empty → "cursor"
+	ItemsPath   string `json:"ItemsPath,omitempty"`   // dotted JSON path to a page's items; empty → the body, or its only array field
+	MaxPages    int    `json:"MaxPages,omitempty"`    // 0 → 20
+}
+
+// Page is one request FetchAll made.
+type Page struct {
+	URL      string
+	Status   string // empty when the send failed
+	Items    int
+	Duration time.Duration
+	Err      string
+}
+
+// Pages is what FetchAll gathered.
+type Pages struct {
+	Pages []Page
+	Items string // every page's items in order, as one indented JSON array
+	Stop  string // why it stopped: no next page, the limit, or a page's failure
+}
+
+// FetchAll sends r, then follows its pagination settings from page to page,
+// merging each page's JSON items. onPage, when set, hears of each page as
+// it lands. A first page that fails is an error; a later one ends the walk
+// with what came before, as does cancelling ctx.
+// ponytail: the pages go out one after another and only the first is saved
+// to history; cursor and URL pages can't be fetched ahead anyway.
+func (r *Request) FetchAll(ctx context.Context, onPage func(Page)) (*Pages, error) {
+	p := r.Settings.Pagination
+	limit := p.MaxPages
+	if limit <= 0 {
+		limit = defaultMaxPages
+	}
+
+	// A shallow copy: only the URL changes between pages, and every page's
+	// body must come back in memory to be merged
+	page := *r
+	page.Settings.Download = false
+	first := ApplyEnv(r.URL)
+
+	out := &Pages{}
+	var items []json.RawMessage
+	seen := map[string]bool{}
+
+	for n := 1; ; n++ {
+		seen[ApplyEnv(page.URL)] = true
+		res, err := page.send(ctx)
+		if err != nil {
+			if n == 1 {
+				return nil, err
+			}
+			out.add(Page{URL: page.URL, Err: err.Error()}, onPage)
+			out.Stop = fmt.Sprintf("page %d failed: %v", n, err)
+			break
+		}
+		if n == 1 {
+			if _, err := saveRequestData(r); err != nil {
+				return nil, err
+			}
+		}
+
+		pg := Page{URL: res.URL, Status: res.Status, Duration: res.Duration}
+		next, pageItems, err := p.follow(res, first)
+		pg.Items = len(pageItems)
+		if err != nil {
+			pg.Err = err.Error()
+		}
+		out.add(pg, onPage)
+		items = append(items, pageItems...)
+
+		switch {
+		case err != nil:
+			out.Stop = fmt.Sprintf("page %d: %v", n, err)
+		case next == "":
+			out.Stop = "no next page"
+		case seen[next]:
+			out.Stop = fmt.Sprintf("page %d points back to an earlier page", n)
+		case n >= limit:
+			out.Stop = fmt.Sprintf("stopped at the %d-page limit", limit)
+		}
+		if out.Stop != "" {
+			break
+		}
+		page.URL = next
+	}
+
+	out.Items = mergeItems(items)
+	return out, nil
+}
+
+func (ps *Pages) add(p Page, onPage func(Page)) {
+	ps.Pages = append(ps.Pages, p)
+	if onPage != nil {
+		onPage(p)
+	}
+}
+
+// follow reads a page: its items and the URL of the next one, "" on the
+// last. first is the first page's URL, which cursors are set on.
+func (p Pagination) follow(res *Response, first string) (next string, items []json.RawMessage, err error) {
+	if !strings.HasPrefix(res.Status, "2") {
+		return "", nil, errors.New("answered " + res.Status)
+	}
+	if res.Truncated {
+		return "", nil, errors.New("body over the read cap, can't be merged")
+	}
+
+	body := json.RawMessage(res.Body)
+	if !json.Valid(body) {
+		return "", nil, errors.New("body isn't JSON")
+	}
+	if items, err = pageItems(body, p.ItemsPath); err != nil {
+		return "", nil, err
+	}
+
+	switch cmp.Or(p.Mode, PageLink) {
+	case PageLink:
+		next = linkNext(res.Headers.Values("Link"))
+	case PageNextURL:
+		next = jsonScalar(jsonAt(body, p.NextPath))
+	case PageCursor:
+		cursor := jsonScalar(jsonAt(body, p.NextPath))
+		if cursor == "" {
+			return "", items, nil
+		}
+		u, err := url.Parse(first)
+		if err != nil {
+			return "", items, err
+		}
+		q := u.Query()
+		q.Set(cmp.Or(p.CursorParam, defaultCursorParam), cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), items, nil
+	default:
+		return "", items, fmt.Errorf("unknown pagination mode %q", p.Mode)
+	}
+
+	if next == "" {
+		return "", items, nil
+	}
+	// Relative to the URL that answered, after redirects
+	base, err := url.Parse(res.URL)
+	if err != nil {
+		return "", items, err
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", items, fmt.Errorf("bad next URL %q: %w", next, err)
+	}
+	return base.ResolveReference(ref).String(), items, nil
+}
+
+// pageItems is the array at path, or with no path the body itself when it
+// is an array, else the body's only array field.
+func pageItems(body json.RawMessage, path string) ([]json.RawMessage, error) {
+	if path != "" {
+		v := jsonAt(body, path)
+		var items []json.RawMessage
+		if v == nil || json.Unmarshal(v, &items) != nil {
+			return nil, fmt.Errorf("no array at %q", path)
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(body, &items) == nil {
+		return items, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return nil, errors.New("body is neither an array nor an object")
+	}
+	var found []string
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if bytes.HasPrefix(bytes.TrimSpace(fields[k]), []byte("[")) {
+			found = append(found, k)
+		}
+	}
+	if len(found) != 1 {
+		return nil, errors.New("can't tell which field holds the items; set the items path")
+	}
+	json.Unmarshal(fields[found[0]], &items)
+	return items, nil
+}
+
+// jsonAt walks a dotted path — object keys, array indexes — into v; nil
+// when it leads nowhere.
+func jsonAt(v json.RawMessage, path string) json.RawMessage {
+	if path == "" {
+		return nil
+	}
+	for key := range strings.SplitSeq(path, ".") {
+		var fields map[string]json.RawMessage
+		var elems []json.RawMessage
+		switch {
+		case json.Unmarshal(v, &fields) == nil:
+			v = fields[key]
+		case json.Unmarshal(v, &elems) == nil:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(elems) {
+				return nil
+			}
+			v = elems[i]
+		default:
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// jsonScalar is a string or number value as text; "" for null, false and
+// anything else.
+func jsonScalar(v json.RawMessage) string {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(v, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// linkNext finds the rel="next" URL among Link header values (RFC 8288):
+// `<https://api.test/items?page=2>; rel="next", <…>; rel="last"`.
+func linkNext(values []string) string {
+	for _, v := range values {
+		for v != "" {
+			start := strings.IndexByte(v, '<')
+			end := strings.IndexByte(v, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := v[start+1 : end]
+			params, rest, _ := strings.Cut(v[end+1:], ",")
+			for param := range strings.SplitSeq(params, ";") {
+				name, value, _ := strings.Cut(param, "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for rel := range strings.FieldsSeq(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target
+					}
+				}
+			}
+			v = rest
+		}
+	}
+	return ""
+}
+
+// mergeItems joins items into one indented JSON array.
+func mergeItems(items []json.RawMessage) string {
+	merged, _ := json.Marshal(items) // already valid JSON, so this can't fail
+	if items == nil {
+		merged = []byte("[]")
+	}
+	var b bytes.Buffer
+	if json.Indent(&b, merged, "", "  ") != nil {
+		return string(merged)
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		switch r.URL.Path {
+		case "/link": // 3 pages of [n]
+			n := map[string]int{"": 1, "2": 2, "3": 3}[page]
+			if n < 3 {
+				w.Header().Add("Link", fmt.Sprintf(`</link?page=%d>; rel="next", </link?page=3>; rel="last"`, n+1))
+			}
+			fmt.Fprintf(w, "[%d]", n)
+		case "/cursor": // 2 pages under "data", the cursor at meta.next
+			if r.URL.Query().Get("after") == "" {
+				fmt.Fprint(w, `{"data":[{"id":1},{"id":2}],"meta":{"next":"c2"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"id":3}],"meta":{"next":null}}`)
+		case "/url": // relative next URLs, looping back on page 2
+			fmt.Fprintf(w, `{"items":["%s"],"next":"%s"}`, page, map[string]string{"": "url?page=2", "2": "url"}[page])
+		case "/fail":
+			if page == "" {
+				fmt.Fprint(w, `{"items":[1],"next":"/fail?page=2"}`)
+				return
+			}
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	fetch := func(path string, p Pagination) *Pages {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL+path)
+		r.Settings.Pagination = p
+		defer DeleteHistory(r.ID)
+
+		var heard int
+		pages, err := r.FetchAll(context.Background(), func(Page) { heard++ })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if heard != len(pages.Pages) {
+			t.Fatalf("onPage heard %d of %d pages", heard, len(pages.Pages))
+		}
+		return pages
+	}
+	items := func(p *Pages) string {
+		var v any
+		json.Unmarshal([]byte(p.Items), &v)
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+
+	p := fetch("/link", Pagination{})
+	if items(p) != "[1,2,3]" || len(p.Pages) != 3 || p.Stop != "no next page" {
+		t.Fatalf("link: %s %+v", p.Items, p)
+	}
+	if p.Pages[2].URL != server.URL+"/link?page=3" || p.Pages[2].Status != "200 OK" || p.Pages[2].Items != 1 {
+		t.Fatalf("third page: %+v", p.Pages[2])
+	}
+
+	if p = fetch("/link", Pagination{MaxPages: 2}); items(p) != "[1,2]" || p.Stop != "stopped at the 2-page limit" {
+		t.Fatalf("limit: %s %q", p.Items, p.Stop)
+	}
+
+	p = fetch("/cursor", Pagination{Mode: PageCursor, NextPath: "meta.next", CursorParam: "after"})
+	if items(p) != `[{"id":1},{"id":2},{"id":3}]` || p.Pages[1].URL != server.URL+"/cursor?after=c2" {
+		t.Fatalf("cursor: %s %+v", p.Items, p.Pages)
+	}
+
+	p = fetch("/url", Pagination{Mode: PageNextURL, NextPath: "next", ItemsPath: "items"})
+	if items(p) != `["","2"]` || !strings.Contains(p.Stop, "points back") {
+		t.Fatalf("next URL: %s %q", p.Items, p.Stop)
+	}
+
+	p = fetch("/fail", Pagination{Mode: PageNextURL, NextPath: "next"})
+	if items(p) != "[1]" || p.Pages[1].Status != "503 Service Unavailable" || !strings.Contains(p.Stop, "page 2: answered 503") {
+		t.Fatalf("failing page: %s %+v", p.Items, p)
+	}
+
+	// The first page failing is the walk failing
+	r := testRequest(NewRequestID(), "http://127.0.0.1:1/")
+	if _, err := r.FetchAll(context.Background(), nil); err == nil {
+		t.Fatal("unreachable first page gave no error")
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	for _, tc := range []struct {
+		values []string
+		want   string
+	}{
+		{[]string{`<https://a.test/?page=2>; rel="next", <https://a.test/?page=9>; rel="last"`}, "https://a.test/?page=2"},
+		{[]string{`<https://a.test/?page=1>; rel="prev"`, `<https://a.test/?page=3>; rel="last next"`}, "https://a.test/?page=3"},
+		{[]string{`<https://a.test/?a=1,2>; rel=next`}, "https://a.test/?a=1,2"},
+		{[]string{`<https://a.test/>; rel="last"`}, ""},
+		{nil, ""},
+	} {
+		if got := linkNext(tc.values); got != tc.want {
+			t.Errorf("linkNext(%q) = %q, want %q", tc.values, got, tc.want)
+		}
+	}
+
+	body := json.RawMessage(`{"meta":{"pages":[{"n":7}]},"list":[1],"total":2}`)
+	if got := jsonScalar(jsonAt(body, "meta.pages.0.n")); got != "7" {
+		t.Errorf("jsonAt = %q", got)
+	}
+	if jsonAt(body, "meta.pages.1") != nil || jsonAt(body, "total.x") != nil {
+		t.Error("jsonAt found a missing path")
+	}
+	if items, err := pageItems(body, ""); err != nil || len(items) != 1 {
+		t.Errorf("only array field: %v %v", items, err)
+	}
+	if _, err := pageItems(json.RawMessage(`{"a":[],"b":[]}`), ""); err == nil {
+		t.Error("two array fields should need an items path")
+	}
+	if got := mergeItems(nil); got != "[]" {
+		t.Errorf("no items: %q", got)
+	}
+}
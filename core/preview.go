@@ -0,0 +1,135 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPreviewBody caps the body text Preview shows.
+const maxPreviewBody = 64 << 10
+
+// Preview renders what SendRequest would send, as HTTP/1.1 text: the
+// request line with the final URL, every header — generated auth and
+// Content-Type, download mode's Accept-Encoding and Range, cookies from the
+// jar, the Host and User-Agent Go adds — and the body, files named rather
+// than read. HTTP/2 and 3 carry the same fields in their own framing.
+//
+// A JWT is signed fresh, as at send time; an OAuth2 token comes from the
+// cache, never the token endpoint. With mask set, the auth credentials and
+// any of secrets (see SecretValues) show as SecretMask.
+func (r *Request) Preview(mask bool, secrets []string) (string, error) {
+	req, body, err := r.buildRequest(context.Background(), func(_ context.Context, a *Auth, _ Settings) (string, error) {
+		if token, ok := cachedOAuthToken(a); ok {
+			return token, nil
+		}
+		return "<fetched from the token URL at send time>", nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.Settings.downloadFor(req); err != nil {
+		return "", err
+	}
+
+	if jar := currentCookieJar(); jar != nil {
+		for _, c := range jar.Cookies(req.URL) {
+			req.AddCookie(c)
+		}
+	}
+
+	var length int64
+	if body != nil {
+		length = body.length
+	}
+
+	var b strings.Builder
+	writeRequestHead(&b, req, "HTTP/1.1", length)
+	if body != nil {
+		body.preview(&b, maxPreviewBody)
+	}
+
+	text := b.String()
+	if mask {
+		text = MaskSecrets(text, append(r.credentialsSent(req), secrets...))
+	}
+	return text, nil
+}
+
+// credentialsSent picks the credentials r's auth put into req: whatever
+// follows an Authorization scheme, the API key, a JWT in the query.
+func (r *Request) credentialsSent(req *http.Request) []string {
+	var creds []string
+	for _, v := range req.Header.Values("Authorization") {
+		if _, cred, ok := strings.Cut(v, " "); ok {
+			v = strings.TrimSpace(cred)
+		}
+		creds = append(creds, v)
+	}
+
+	switch r.AuthType {
+	case "Basic":
+		creds = append(creds, ApplyEnv(r.Auth.BasicPass))
+	case "API Key":
+		creds = append(creds, ApplyEnv(r.Auth.APIKeyValue))
+	case "JWT":
+		inQuery, name, prefix := r.Auth.JWTPlacement()
+		if inQuery {
+			creds = append(creds, req.URL.Query().Get(ApplyEnv(name)))
+		} else {
+			creds = append(creds, strings.TrimSpace(strings.TrimPrefix(req.Header.Get(ApplyEnv(name)), prefix)))
+		}
+	}
+	return creds
+}
+
+// MaskSecrets replaces each secret in text, as is and URL-encoded, with
+// SecretMask. Longer ones go first, so one holding another is caught whole.
+func MaskSecrets(text string, secrets []string) string {
+	var forms []string
+	for _, s := range secrets {
+		if s != "" {
+			forms = append(forms, s, url.QueryEscape(s), url.PathEscape(s))
+		}
+	}
+	slices.SortFunc(forms, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+
+	for _, s := range slices.Compact(forms) {
+		text = strings.ReplaceAll(text, s, SecretMask)
+	}
+	return text
+}
+
+// preview writes the body's text, at most limit bytes of it, with each
+// file standing in as "<path, N bytes>".
+func (b *bodySource) preview(w *strings.Builder, limit int) {
+	shown, covered := 0, int64(0)
+	for _, seg := range b.segs {
+		if seg.path != "" {
+			fmt.Fprintf(w, "<%s, %d bytes>", seg.path, seg.size)
+			covered += seg.size
+			continue
+		}
+
+		if left := limit - shown; len(seg.data) > left {
+			cut := max(left, 0)
+			for cut > 0 && !utf8.RuneStart(seg.data[cut]) {
+				cut--
+			}
+			w.Write(seg.data[:cut])
+			fmt.Fprintf(w, "\n… [%d more bytes]", b.length-covered-int64(cut))
+			return
+		}
+		w.Write(seg.data)
+		shown += len(seg.data)
+		covered += int64(len(seg.data))
+	}
+}
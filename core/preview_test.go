@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	SetActiveVars(map[string]string{"host": "api.example.com", "tenant": "acme", "apiKey": "k-123", "token": "s3cret"})
+	defer SetActiveVars(nil)
+
+	body := `{"token":"{{token}}"}`
+	r := &Request{
+		Method:   "POST",
+		URL:      "https://{{host}}/v1/items?x=1",
+		Headers:  &[]FormType{{Checked: true, Key: "X-Tenant", Value: "{{tenant}}"}, {Checked: false, Key: "X-Off", Value: "1"}},
+		AuthType: "API Key",
+		Auth:     &Auth{APIKeyName: "key", APIKeyValue: "{{apiKey}}", APIKeyIn: "Query"},
+		BodyType: "JSON",
+		Body:     Body{Json: body},
+	}
+
+	got, err := r.Preview(false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POST /v1/items?key=k-123&x=1 HTTP/1.1\n" +
+		"Host: api.example.com\n" +
+		"Accept-Encoding: " + AcceptEncoding + "\n" +
+		"Content-Length: " + strconv.Itoa(len(`{"token":"s3cret"}`)) + "\n" +
+		"Content-Type: application/json\n" +
+		"User-Agent: Go-http-client/1.1\n" +
+		"X-Tenant: acme\n" +
+		"\n" +
+		`{"token":"s3cret"}`
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Masked: the API key as a credential, the token as a secret variable
+	got, _ = r.Preview(true, SecretValues(&[]FormType{{Checked: true, Key: "token", Value: "{{host}}", Secret: true}, {Checked: true, Value: "s3cret", Secret: true}}))
+	if strings.Contains(got, "k-123") || strings.Contains(got, "s3cret") || !strings.Contains(got, "key="+SecretMask+"&x=1") {
+		t.Fatalf("masked:\n%s", got)
+	}
+	if strings.Contains(got, "Host: api.example.com") {
+		t.Fatal("expanded secret value left unmasked")
+	}
+
+	r.AuthType = "Basic"
+	r.Auth = &Auth{BasicUser: "ada", BasicPass: "pw"}
+	if got, _ = r.Preview(true, nil); !strings.Contains(got, "Authorization: Basic "+SecretMask+"\n") {
+		t.Fatalf("basic auth:\n%s", got)
+	}
+}
+
+// Files are named, not read, and a long body is cut
+func TestPreviewBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, make([]byte, 1000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Request{Method: "POST", URL: "http://example.com/", Headers: &[]FormType{}, BodyType: "Form", Body: Body{Form: &[]FormType{
+		{Checked: true, Key: "name", Value: "ada"},
+		{Checked: true, Key: "photo", Value: path, IsFile: true},
+	}}}
+	got, err := r.Preview(false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<"+path+", 1000 bytes>") || !strings.Contains(got, "ada") || !strings.Contains(got, "Content-Type: multipart/form-data; boundary=") {
+		t.Fatalf("form:\n%s", got)
+	}
+
+	r.BodyType = "Text"
+	r.Body = Body{Text: strings.Repeat("é", maxPreviewBody)} // 2 bytes each
+	got, _ = r.Preview(false, nil)
+	if !strings.HasSuffix(got, "\n… ["+strconv.Itoa(maxPreviewBody)+" more bytes]") {
+		t.Fatalf("long body ends %q", got[len(got)-40:])
+	}
+
+	// Download mode asks for the file as stored, from where a partial one
+	// ends
+	partial := filepath.Join(t.TempDir(), "part.bin")
+	if err := os.WriteFile(partial, make([]byte, 300), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dl := &Request{Method: "GET", URL: "http://example.com/f", Headers: &[]FormType{}, Settings: Settings{Download: true, DownloadPath: partial, ResumeDownload: true}}
+	if got, _ = dl.Preview(false, nil); !strings.Contains(got, "Accept-Encoding: identity\n") || !strings.Contains(got, "Range: bytes=300-\n") {
+		t.Fatalf("download:\n%s", got)
+	}
+
+	r.BodyType = "Binary"
+	r.Body = Body{Binary: filepath.Join(t.TempDir(), "missing")}
+	if _, err := r.Preview(false, nil); err == nil {
+		t.Fatal("missing file previewed")
+	}
+
+	if got := SecretValues(nil, &[]FormType{{Checked: true, Value: "a", Secret: true}, {Checked: false, Value: "b", Secret: true}, {Checked: true, Value: "c"}}); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("SecretValues = %q", got)
+	}
+}
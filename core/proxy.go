@@ -0,0 +1,193 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ProxyConfig routes sends through an HTTP, HTTPS or SOCKS5 proxy. An unset
+// config (no URL, not Direct) defers to the next level: request, then
+// environment, then global, then the system's HTTP_PROXY/NO_PROXY.
+type ProxyConfig struct {
+	URL    string `json:"URL,omitempty"` // http://, https://, socks5:// or socks5h://host:port; no scheme means http
+	User   string `json:"User,omitempty"`
+	Pass   string `json:"Pass,omitempty"`
+	Bypass string `json:"Bypass,omitempty"` // comma-separated: "localhost, .corp.example.com, 10.0.0.0/8"
+	Direct bool   `json:"Direct,omitempty"` // no proxy at all, not even the system's
+}
+
+// IsSet reports whether p decides anything, rather than deferring.
+func (p ProxyConfig) IsSet() bool {
+	return p.Direct || p.URL != ""
+}
+
+// ProxyURL parses the configured proxy with its credentials attached;
+// net/http takes them from the userinfo for both Proxy-Authorization and
+// SOCKS5.
+func (p ProxyConfig) ProxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(ApplyEnv(p.URL))
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme %q (use http, https or socks5)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy: %q has no host", p.URL)
+	}
+
+	if user := ApplyEnv(p.User); user != "" {
+		u.User = url.UserPassword(user, ApplyEnv(p.Pass))
+	}
+
+	return u, nil
+}
+
+// proxyFunc is the http.Transport.Proxy for p: nil for Direct, the
+// environment's for an unset config.
+func (p ProxyConfig) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	switch {
+	case p.Direct:
+		return nil, nil
+	case p.URL == "":
+		return http.ProxyFromEnvironment, nil
+	}
+
+	u, err := p.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+
+	bypass := ApplyEnv(p.Bypass)
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassed(req.URL.Hostname(), bypass) {
+			return nil, nil
+		}
+		return u, nil
+	}, nil
+}
+
+// bypassed matches host against a NO_PROXY-style list: "*" for everything,
+// CIDRs, ".example.com" (subdomains only) or "example.com" (the host and
+// its subdomains). Unlike NO_PROXY, localhost is only skipped when listed,
+// so a local debugging proxy still sees local traffic.
+func bypassed(host, list string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for entry := range strings.SplitSeq(list, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entry = strings.TrimPrefix(entry, "*") // "*.example.com" → ".example.com"
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	proxyMu     sync.RWMutex
+	activeProxy ProxyConfig
+)
+
+// SetProxy sets the proxy for requests that have none of their own: the
+// active environment's when it has one, else the global one.
+func SetProxy(p ProxyConfig) {
+	proxyMu.Lock()
+	activeProxy = p
+	proxyMu.Unlock()
+}
+
+// effectiveProxy is the request's own proxy, else the active one.
+func effectiveProxy(s Settings) ProxyConfig {
+	if s.Proxy.IsSet() {
+		return s.Proxy
+	}
+
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+
+	return activeProxy
+}
+
+// LoadGlobalProxy reads the global proxy, its password from the vault;
+// unset on any error.
+func LoadGlobalProxy() ProxyConfig {
+	var p ProxyConfig
+
+	file, err := configFile("proxy.json")
+	if err != nil {
+		return p
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return p
+	}
+
+	json.Unmarshal(content, &p)
+	if pass, ok := vaultGet(globalProxyKey); ok && p.Pass == "" {
+		p.Pass = pass
+	}
+	return p
+}
+
+const globalProxyKey = "proxy/global"
+
+// SaveGlobalProxy writes the global proxy, its password to the vault. A
+// locked vault still writes the file, then reports the password wasn't
+// stored.
+func SaveGlobalProxy(p ProxyConfig) error {
+	file, err := configFile("proxy.json")
+	if err != nil {
+		return err
+	}
+
+	vaultErr := vaultSet(globalProxyKey, p.Pass)
+	p.Pass = ""
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+
+	return vaultErr
+}
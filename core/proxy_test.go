@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBypassed(t *testing.T) {
+	list := "localhost, .corp.example.com, example.org, 10.0.0.0/8, *.wild.test"
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost", true},
+		{"api.corp.example.com", true},
+		{"corp.example.com", false}, // leading dot: subdomains only
+		{"example.org", true},
+		{"www.example.org", true},
+		{"notexample.org", false},
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+		{"a.wild.test", true},
+		{"api.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := bypassed(tt.host, list); got != tt.want {
+			t.Errorf("bypassed(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if !bypassed("anything", "*") {
+		t.Error("* should bypass everything")
+	}
+}
+
+// The proxy answers for hosts that don't exist, so any response proves the
+// send went through it.
+func TestSendRequestProxy(t *testing.T) {
+	var gotTarget, gotAuth, gotBearer string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Proxy-Authorization")
+		if r.URL.Host == "auth.proxy.test" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"access_token":"via-proxy","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		gotTarget, gotBearer = r.URL.String(), r.Header.Get("Authorization")
+		io.WriteString(w, "proxied")
+	}))
+	defer proxy.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "direct")
+	}))
+	defer direct.Close()
+
+	send := func(r *Request) string {
+		t.Helper()
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Body
+	}
+
+	// Per-request proxy with credentials; the OAuth token fetch takes it too
+	r := testRequest(NewRequestID(), "http://api.proxy.test/items?x=1")
+	r.Settings.Proxy = ProxyConfig{URL: proxy.URL, User: "me", Pass: "pw"}
+	r.AuthType = "OAuth2"
+	r.Auth = &Auth{OAuthTokenURL: "http://auth.proxy.test/token", OAuthClientID: "cid"}
+	if body := send(r); body != "proxied" || gotTarget != "http://api.proxy.test/items?x=1" {
+		t.Fatalf("body=%q target=%q", body, gotTarget)
+	}
+	if gotAuth != "Basic bWU6cHc=" || gotBearer != "Bearer via-proxy" {
+		t.Fatalf("proxy auth=%q bearer=%q", gotAuth, gotBearer)
+	}
+
+	// Global/environment proxy applies to requests without their own, even
+	// for loopback targets; the bypass list and Direct skip it.
+	SetProxy(ProxyConfig{URL: proxy.URL})
+	defer SetProxy(ProxyConfig{})
+
+	if body := send(testRequest(NewRequestID(), direct.URL)); body != "proxied" {
+		t.Fatalf("active proxy not used: %q", body)
+	}
+
+	SetProxy(ProxyConfig{URL: proxy.URL, Bypass: "127.0.0.1"})
+	if body := send(testRequest(NewRequestID(), direct.URL)); body != "direct" {
+		t.Fatalf("bypass list ignored: %q", body)
+	}
+
+	SetProxy(ProxyConfig{URL: proxy.URL})
+	r = testRequest(NewRequestID(), direct.URL)
+	r.Settings = Settings{Proxy: ProxyConfig{Direct: true}, SkipTLSVerify: true}
+	if body := send(r); body != "direct" {
+		t.Fatalf("request Direct didn't override the active proxy: %q", body)
+	}
+
+	r = testRequest(NewRequestID(), direct.URL)
+	r.Settings.Proxy = ProxyConfig{URL: "ftp://proxy.test:21"}
+	if _, err := r.SendRequest(context.Background()); err == nil {
+		t.Fatal("unsupported proxy scheme should error")
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Hop is one redirect response a send followed on its way to the final
+// one; Response.Redirects lists them in order.
+type Hop struct {
+	URL     string // the URL that answered with the redirect
+	Status  string
+	Headers Header
+	Timings Timings // this hop alone; Total runs to its response headers
+}
+
+// defaultMaxRedirects matches net/http's own limit, counted the same way:
+// the chain stops once it has made that many requests.
+const defaultMaxRedirects = 10
+
+// redirectLimitError is checkRedirect refusing another hop. Every retry
+// would follow the same chain into it, so it isn't retried.
+type redirectLimitError int
+
+func (e redirectLimitError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects", int(e))
+}
+
+// checkRedirect enforces s's redirect settings on the next hop. net/http
+// has already dropped Authorization (and Cookie) when next leaves the
+// original host; KeepAuthOnRedirect puts Authorization back.
+func (s Settings) checkRedirect(next *http.Request, via []*http.Request) error {
+	if s.NoFollowRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	limit := defaultMaxRedirects
+	if s.MaxRedirects > 0 {
+		limit = s.MaxRedirects
+	}
+	if len(via) >= limit {
+		return redirectLimitError(limit)
+	}
+
+	if s.KeepAuthOnRedirect && next.Header.Get("Authorization") == "" {
+		if auth := via[0].Header.Values("Authorization"); len(auth) > 0 {
+			next.Header["Authorization"] = auth
+		}
+	}
+
+	return nil
+}
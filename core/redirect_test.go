@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSendRequestRedirects(t *testing.T) {
+	// "localhost" vs 127.0.0.1: a different host, so net/http drops
+	// Authorization on the way over
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer target.Close()
+	end := strings.Replace(target.URL, "127.0.0.1", "localhost", 1) + "/end"
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/mid", http.StatusFound)
+		case "/mid":
+			w.Header().Set("X-Hop", "mid")
+			http.Redirect(w, r, end, http.StatusMovedPermanently)
+		}
+	}))
+	defer origin.Close()
+
+	send := func(s Settings) (*Response, error) {
+		r := testRequest(NewRequestID(), origin.URL+"/start")
+		r.AuthType = "Bearer"
+		r.Auth = &Auth{BearerAuth: "tok", BearerPrefix: "Bearer"}
+		r.Settings = s
+		defer DeleteHistory(r.ID)
+		return r.SendRequest(context.Background())
+	}
+
+	res, err := send(Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.URL != end || res.Body != "" {
+		t.Fatalf("url=%q auth leaked to the other host: %q", res.URL, res.Body)
+	}
+	if len(res.Redirects) != 2 {
+		t.Fatalf("hops: %+v", res.Redirects)
+	}
+	first, second := res.Redirects[0], res.Redirects[1]
+	if first.URL != origin.URL+"/start" || !strings.HasPrefix(first.Status, "302") || first.Headers.Get("location") != "/mid" {
+		t.Fatalf("first hop: %+v", first)
+	}
+	if second.URL != origin.URL+"/mid" || !strings.HasPrefix(second.Status, "301") || second.Headers.Get("X-Hop") != "mid" || second.Timings.Total <= 0 {
+		t.Fatalf("second hop: %+v", second)
+	}
+	if res.Duration < first.Timings.Total+second.Timings.Total {
+		t.Fatalf("duration %v should span every hop", res.Duration)
+	}
+
+	if res, err = send(Settings{KeepAuthOnRedirect: true}); err != nil || res.Body != "Bearer tok" {
+		t.Fatalf("KeepAuthOnRedirect: body=%q err=%v", res.Body, err)
+	}
+
+	if _, err = send(Settings{MaxRedirects: 1}); err == nil || !strings.Contains(err.Error(), "stopped after 1 redirects") {
+		t.Fatalf("MaxRedirects: %v", err)
+	}
+
+	if res, err = send(Settings{NoFollowRedirects: true}); err != nil || !strings.HasPrefix(res.Status, "302") || res.Redirects != nil {
+		t.Fatalf("NoFollowRedirects: status=%q hops=%d err=%v", res.Status, len(res.Redirects), err)
+	}
+}
+
+// A limit of n stops an endless chain after n requests, as net/http's
+// default does after 10.
+func TestMaxRedirects(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+	defer server.Close()
+
+	for _, limit := range []int{1, 3, 0} {
+		hits = 0
+		r := testRequest(NewRequestID(), server.URL)
+		r.Settings.MaxRedirects = limit
+		_, err := r.SendRequest(context.Background())
+		DeleteHistory(r.ID)
+
+		want := limit
+		if want == 0 {
+			want = defaultMaxRedirects
+		}
+		if hits != want || err == nil || !strings.Contains(err.Error(), fmt.Sprintf("stopped after %d redirects", want)) {
+			t.Errorf("MaxRedirects %d: hits=%d err=%v, want %d hops", limit, hits, err, want)
+		}
+	}
+}
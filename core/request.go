@@ -1,18 +1,14 @@
 package core
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
 	"net/http/httptrace"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
@@ -36,9 +32,51 @@ type Request struct {
 // Settings holds per-request transport options. Fields are named so the Go
 // zero value means default behaviour — old saved requests unmarshal to zero.
 type Settings struct {
-	TimeoutSec        int  `json:"TimeoutSec"` // 0 → 30s default
-	NoFollowRedirects bool `json:"NoFollowRedirects"`
-	SkipTLSVerify     bool `json:"SkipTLSVerify"`
+	TimeoutSec         int        `json:"TimeoutSec"` // 0 → 30s default
+	NoFollowRedirects  bool       `json:"NoFollowRedirects"`
+	MaxRedirects       int        `json:"MaxRedirects,omitempty"`       // 0 → 10
+	KeepAuthOnRedirect bool       `json:"KeepAuthOnRedirect,omitempty"` // resend Authorization to other hosts
+	SkipTLSVerify      bool       `json:"SkipTLSVerify"`
+	ClientCert         CertConfig `json:"ClientCert"` // zero → host store, then none
+
+	// StrictVars fails the send on a placeholder that can't be resolved
+	// instead of sending it literally.
+	StrictVars bool `json:"StrictVars,omitempty"`
+
+	// Protocol forces ProtoHTTP1, ProtoHTTP2 or ProtoHTTP3; empty negotiates.
+	Protocol string `json:"Protocol,omitempty"`
+
+	// Proxy overrides the environment's and the global proxy when set.
+	Proxy ProxyConfig `json:"Proxy,omitzero"`
+
+	// Retry re-sends on transient failures; zero sends once.
+	Retry RetryPolicy `json:"Retry,omitzero"`
+
+	// DNS pins hosts to addresses and picks a resolver, layered over the
+	// environment's.
+	DNS DNSConfig `json:"DNS,omitzero"`
+
+	// Connections are pooled per TLS/proxy/protocol setup and reused across
+	// sends. FreshConnection dials a new one for this send and closes it
+	// after; the idle limits tune the pool (0 → Go's defaults: 2 idle
+	// connections per host, 90s).
+	FreshConnection bool `json:"FreshConnection,omitempty"`
+	MaxIdlePerHost  int  `json:"MaxIdlePerHost,omitempty"`
+	IdleTimeoutSec  int  `json:"IdleTimeoutSec,omitempty"`
+
+	// Download streams the body to DownloadPath (empty → a temp file named
+	// after the URL) instead of into memory; ResumeDownload continues a
+	// partial file there with a Range request.
+	Download       bool   `json:"Download,omitempty"`
+	DownloadPath   string `json:"DownloadPath,omitempty"`
+	ResumeDownload bool   `json:"ResumeDownload,omitempty"`
+
+	// Pagination is how "fetch all pages" walks from page to page.
+	Pagination Pagination `json:"Pagination,omitzero"`
+
+	// SecretRef is the vault entry holding Proxy.Pass and
+	// ClientCert.Passphrase, which history and collections store blank.
+	SecretRef string `json:"SecretRef,omitempty"`
 }
 
 type Body struct {
@@ -46,6 +84,11 @@ type Body struct {
 	Text string      `json:"Text"`
 	Xml  string      `json:"Xml"`
 	Form *[]FormType `json:"Form"`
+
+	// Binary sends one file as the whole body: Binary is its path,
+	// BinaryType overrides the Content-Type guessed from the extension.
+	Binary     string `json:"Binary,omitempty"`
+	BinaryType string `json:"BinaryType,omitempty"`
 }
 
 type Auth struct {
@@ -62,23 +105,74 @@ type Auth struct {
 	OAuthClientID     string `json:"OAuthClientID,omitempty"`
 	OAuthClientSecret string `json:"OAuthClientSecret,omitempty"`
 	OAuthScope        string `json:"OAuthScope,omitempty"`
+
+	JWTAlgorithm  string `json:"JWTAlgorithm,omitempty"` // "HS256" (default), "RS256", "ES256"
+	JWTKey        string `json:"JWTKey,omitempty"`       // HS secret, or PEM private key / path to one
+	JWTHeader     string `json:"JWTHeader,omitempty"`    // extra header JSON; alg and typ are filled in
+	JWTClaims     string `json:"JWTClaims,omitempty"`
+	JWTTimeClaims string `json:"JWTTimeClaims,omitempty"` // comma-separated claims that take "now+5m" besides exp/nbf/iat/auth_time
+	JWTIn         string `json:"JWTIn,omitempty"`         // "Query"; anything else means header
+	JWTName       string `json:"JWTName,omitempty"`       // header or query param name; empty → Authorization / access_token
+	JWTPrefix     string `json:"JWTPrefix,omitempty"`     // header only; empty → "Bearer"
+
+	// Secret keeps the credentials (passwords, tokens, keys, client secret)
+	// in the vault under SecretRef instead of in history and collections.
+	Secret    bool   `json:"Secret,omitempty"`
+	SecretRef string `json:"SecretRef,omitempty"`
+}
+
+// JWTPlacement resolves where a signed JWT goes: the header or query param
+// name, and the value prefix (always "" for query params).
+func (a *Auth) JWTPlacement() (inQuery bool, name, prefix string) {
+	if a.JWTIn == "Query" {
+		name = a.JWTName
+		if name == "" {
+			name = "access_token"
+		}
+		return true, name, ""
+	}
+
+	name, prefix = a.JWTName, a.JWTPrefix
+	if name == "" {
+		name = "Authorization"
+	}
+	if prefix == "" && strings.EqualFold(name, "Authorization") {
+		prefix = "Bearer"
+	}
+	return false, name, prefix
 }
 
 type FormType struct {
 	Checked bool   `json:"Checked"`
 	Key     string `json:"Key"`
 	Value   string `json:"Value"`
-	IsFile  bool   `json:"IsFile,omitempty"` // multipart Form rows only: Value is a file path
+	IsFile  bool   `json:"IsFile,omitempty"` // multipart Form rows only: Value is file paths, one per line
+	Secret  bool   `json:"Secret,omitempty"` // environment variables only: Value lives in the vault
+
+	ContentType string `json:"ContentType,omitempty"` // multipart Form rows only: the part's Content-Type
 }
 
 type Response struct {
-	Body     string
-	Headers  map[string]string
-	Cookies  []*http.Cookie
-	Status   string
-	Duration time.Duration
-	Size     string
-	Timings  Timings
+	Body      string // in download mode, a preview of the file's head
+	File      string // download mode: where the full body was saved
+	Headers   Header
+	Trailers  Header // sent after the body; only known once it was read to the end
+	Sorted    bool   // Headers are sorted by name: the order received wasn't captured (HTTP/2 and 3, proxied HTTPS)
+	Cookies   []*http.Cookie
+	Status    string
+	Duration  time.Duration
+	WireSize  int64  // bytes as transferred, before Content-Encoding is undone
+	BodySize  int64  // bytes after decoding; WireSize when not encoded
+	Encoding  string // the Content-Encoding that was decoded, "" for none
+	Wire      []byte // encoded bodies only: the head of the raw bytes as received
+	Truncated bool   // the body was cut at the read cap; an encoded BodySize is then a lower bound
+	Timings   Timings
+	TLS       *TLSInfo
+	Proto     string    // as negotiated: "HTTP/1.1", "HTTP/2.0", "HTTP/3.0"
+	Attempts  []Attempt // every try, the last being this response; nil when the first try stuck
+	URL       string    // the URL that answered, after redirects
+	Redirects []Hop     // the redirects followed to get here, in order
+	RawLog    string    // every round trip as sent and received, bodies capped; see wireLog
 }
 
 // Timings holds the phase breakdown of a request. DNS/Connect/TLS are zero
@@ -91,16 +185,27 @@ type Timings struct {
 	TTFB     time.Duration
 	Download time.Duration
 	Total    time.Duration
+
+	// Reused is set when the send went over a pooled keep-alive connection;
+	// DNS, Connect and TLS are then zero because none of them happened.
+	Reused bool
+
+	// RemoteAddr is the address the connection went to: the resolved or
+	// overridden IP, or the proxy's when proxied. Empty for HTTP/3.
+	RemoteAddr string
 }
 
-func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
+// buildRequest makes the request SendRequest sends: {{var}}s substituted,
+// auth and Content-Type set, the body laid out but not attached. oauth
+// supplies an OAuth2 token, so Preview can keep off the network.
+func (r *Request) buildRequest(ctx context.Context, oauth func(context.Context, *Auth, Settings) (string, error)) (*http.Request, *bodySource, error) {
 	// {{var}} substitution happens here at send time so the saved request
 	// keeps its placeholders.
 	req, err := http.NewRequest(r.Method, ApplyEnv(r.URL), nil)
 
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	req = req.WithContext(ctx)
@@ -113,6 +218,11 @@ func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
 		req.Header.Set(ApplyEnv(header.Key), ApplyEnv(header.Value))
 	}
 
+	// Ask for what decodeBody can undo, unless the request says otherwise
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", AcceptEncoding)
+	}
+
 	// Setting Basic Auth in the request
 	if r.AuthType == "Basic" && r.Auth.BasicPass != "" && r.Auth.BasicUser != "" {
 		req.SetBasicAuth(ApplyEnv(r.Auth.BasicUser), ApplyEnv(r.Auth.BasicPass))
@@ -134,63 +244,64 @@ func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
 	}
 
 	if r.AuthType == "OAuth2" && r.Auth.OAuthTokenURL != "" {
-		token, err := oauthToken(ctx, r.Auth, r.Settings.SkipTLSVerify)
+		token, err := oauth(ctx, r.Auth, r.Settings)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	if r.Body.Json != "" || r.Body.Xml != "" || r.Body.Text != "" || r.Body.Form != nil {
+	// A fresh token per send, so relative exp/iat claims track the clock
+	if r.AuthType == "JWT" {
+		token, err := r.Auth.SignJWT()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		inQuery, name, prefix := r.Auth.JWTPlacement()
+		if inQuery {
+			q := req.URL.Query()
+			q.Set(ApplyEnv(name), token)
+			req.URL.RawQuery = q.Encode()
+		} else {
+			if prefix != "" {
+				token = prefix + " " + token
+			}
+			req.Header.Set(ApplyEnv(name), token)
+		}
+	}
+
+	var reqBody *bodySource
+	if r.Body.Json != "" || r.Body.Xml != "" || r.Body.Text != "" || r.Body.Form != nil || r.Body.Binary != "" {
 		switch r.BodyType {
 		case "JSON":
 			req.Header.Set("Content-Type", "application/json")
-			req.Body = io.NopCloser(bytes.NewBufferString(ApplyEnv(r.Body.Json)))
+			reqBody = stringBody(ApplyEnv(r.Body.Json))
 
 		case "XML":
 			req.Header.Set("Content-Type", "application/xml")
-			req.Body = io.NopCloser(bytes.NewBufferString(ApplyEnv(r.Body.Xml)))
+			reqBody = stringBody(ApplyEnv(r.Body.Xml))
 
 		case "Text":
 			req.Header.Set("Content-Type", "text/plain")
-			req.Body = io.NopCloser(bytes.NewBufferString(ApplyEnv(r.Body.Text)))
+			reqBody = stringBody(ApplyEnv(r.Body.Text))
 
 		case "Form":
-			// ponytail: whole multipart body is buffered in RAM; io.Pipe
-			// streaming if huge uploads ever matter.
-			var b bytes.Buffer
-			writer := multipart.NewWriter(&b)
-
-			for _, v := range *r.Body.Form {
-				if !v.Checked {
-					continue
-				}
-
-				if v.IsFile {
-					path := ApplyEnv(v.Value)
-					f, err := os.Open(path)
-					if err != nil {
-						return nil, err
-					}
-
-					part, err := writer.CreateFormFile(ApplyEnv(v.Key), filepath.Base(path))
-					if err == nil {
-						_, err = io.Copy(part, f)
-					}
-					f.Close()
-					if err != nil {
-						return nil, err
-					}
-					continue
-				}
-
-				writer.WriteField(ApplyEnv(v.Key), ApplyEnv(v.Value))
+			var contentType string
+			if reqBody, contentType, err = multipartBody(*r.Body.Form); err != nil {
+				return nil, nil, err
 			}
+			req.Header.Set("Content-Type", contentType)
 
-			req.Header.Set("Content-Type", writer.FormDataContentType())
-			writer.Close()
-
-			req.Body = io.NopCloser(&b)
+		case "Binary":
+			path := ApplyEnv(r.Body.Binary)
+			if reqBody, err = fileBody(path); err != nil {
+				return nil, nil, err
+			}
+			// An explicit Content-Type header wins over the guess
+			if req.Header.Get("Content-Type") == "" {
+				req.Header.Set("Content-Type", BinaryContentType(path, ApplyEnv(r.Body.BinaryType)))
+			}
 
 		case "URL Encoded":
 			values := url.Values{}
@@ -201,9 +312,37 @@ func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
 			}
 
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			req.Body = io.NopCloser(strings.NewReader(values.Encode()))
+			reqBody = stringBody(values.Encode())
 		}
+	}
+
+	return req, reqBody, nil
+}
+
+func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
+	res, err := r.send(ctx)
+	if err != nil {
+		return nil, err
+	}
 
+	if _, err = saveRequestData(r); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// send is SendRequest without the history entry.
+func (r *Request) send(ctx context.Context) (*Response, error) {
+	if r.Settings.StrictVars {
+		if missing := r.Unresolved(); len(missing) > 0 {
+			return nil, fmt.Errorf("unresolved placeholders: %s", strings.Join(missing, "; "))
+		}
+	}
+
+	req, reqBody, err := r.buildRequest(ctx, oauthToken)
+	if err != nil {
+		return nil, err
 	}
 
 	// Zero-value settings keep the old defaults: 30s timeout, follow
@@ -213,66 +352,242 @@ func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
 		timeout = time.Duration(r.Settings.TimeoutSec) * time.Second
 	}
 
-	client := &http.Client{Timeout: timeout}
+	// Files streaming either way take as long as they take: the timeout
+	// then only covers waiting on the server, see sendDeadline
+	var deadline *sendDeadline
+	if r.Settings.Download || (reqBody != nil && reqBody.streamed()) {
+		deadline = &sendDeadline{timeout: timeout}
+		defer deadline.release()
+		timeout = 0
+	}
 
-	if r.Settings.NoFollowRedirects {
-		client.CheckRedirect = func(*http.Request, []*http.Request) error {
-			return http.ErrUseLastResponse
+	// An exact length and a replayable body, so 307/308 redirects resend it.
+	// Upload progress only wraps the first pass.
+	if reqBody != nil {
+		open := reqBody.open
+		if deadline != nil {
+			open = func() io.ReadCloser { return deadline.armAtEOF(reqBody.open()) }
+		}
+		req.ContentLength = reqBody.length
+		req.GetBody = func() (io.ReadCloser, error) { return open(), nil }
+		req.Body = open()
+		if fn := progressFunc(ctx); fn != nil {
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{newProgressReader(req.Body, Progress{Upload: true, Total: reqBody.length}, fn), req.Body}
 		}
 	}
 
-	if r.Settings.SkipTLSVerify {
-		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	client, tlsCfg, err := newClient(r.Settings, req.URL.Host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer closeClient(client)
+
+	// Every round trip as it went out and came back, for the Raw tab
+	wlog := &wireLog{rt: client.Transport}
+	client.Transport = wlog
+
+	// The active environment's cookie jar, when the user opted in. Not in
+	// newClient: the OAuth token fetch shouldn't pick up API cookies.
+	if jar := currentCookieJar(); jar != nil {
+		client.Jar = jar
+	}
+
+	dl, err := r.Settings.downloadFor(req)
+	if err != nil {
+		return nil, err
 	}
 
 	var timings Timings
-	startTime := time.Now()
+	var startTime time.Time
 	var dnsStart, connStart, tlsStart time.Time
 	trace := &httptrace.ClientTrace{
-		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
-		DNSDone:           func(httptrace.DNSDoneInfo) { timings.DNS = time.Since(dnsStart) },
-		ConnectStart:      func(string, string) { connStart = time.Now() },
-		ConnectDone:       func(string, string, error) { timings.Connect = time.Since(connStart) },
-		TLSHandshakeStart: func() { tlsStart = time.Now() },
-		TLSHandshakeDone:  func(tls.ConnectionState, error) { timings.TLS = time.Since(tlsStart) },
+		DNSStart:     func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:      func(httptrace.DNSDoneInfo) { timings.DNS = time.Since(dnsStart) },
+		ConnectStart: func(string, string) { connStart = time.Now() },
+		ConnectDone:  func(string, string, error) { timings.Connect = time.Since(connStart) },
+		// The TLS dialer may have started the handshake the transport
+		// traces again; see dialTLSTapped
+		TLSHandshakeStart: func() {
+			if tlsStart.IsZero() {
+				tlsStart = time.Now()
+			}
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			timings.TLS, tlsStart = time.Since(tlsStart), time.Time{}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			timings.Reused = info.Reused
+			timings.RemoteAddr = info.Conn.RemoteAddr().String()
+		},
 		GotFirstResponseByte: func() {
 			timings.TTFB = time.Since(startTime)
 		},
 	}
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
-	response, err := client.Do(req)
+	// Every followed redirect closes a hop: its timings move into the hop
+	// and restart for the next request in the chain.
+	var redirects []Hop
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if err := r.Settings.checkRedirect(next, via); err != nil {
+			return err
+		}
+
+		headers, _ := wlog.header(next.Response)
+		hop := Hop{
+			URL:     via[len(via)-1].URL.String(),
+			Status:  next.Response.Status,
+			Headers: headers,
+			Timings: timings,
+		}
+		hop.Timings.Total = time.Since(startTime)
+		redirects = append(redirects, hop)
+
+		timings, startTime = Timings{}, time.Now()
+		return nil
+	}
+
+	// Each retry resends a clone with a fresh body; timings restart so
+	// every attempt reports its own phases.
+	var response *http.Response
+	var attempts []Attempt
+	var sendStart time.Time // this attempt's first request; startTime moves on per redirect
+	for n := 1; ; n++ {
+		try := req
+		if n > 1 {
+			try = req.Clone(req.Context())
+			if req.GetBody != nil {
+				try.Body, _ = req.GetBody()
+			}
+		}
+
+		if deadline != nil {
+			try = try.WithContext(deadline.attempt(try.Context()))
+			if try.Body == nil || try.Body == http.NoBody {
+				deadline.start() // nothing to write first
+			}
+		}
+
+		timings, startTime, redirects = Timings{}, time.Now(), nil
+		sendStart = startTime
+		response, err = client.Do(try)
+		if deadline != nil {
+			err = deadline.err(err)
+		}
+
+		if n >= r.Settings.Retry.MaxAttempts || !r.Settings.Retry.retries(response, err) {
+			break
+		}
+
+		timings.Total = time.Since(startTime)
+		a := Attempt{Timings: timings, Delay: r.Settings.Retry.delay(n, response)}
+		if err != nil {
+			a.Err = err.Error()
+		} else {
+			a.Status = response.Status
+			// Drain a little so the connection goes back to the pool
+			io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+			response.Body.Close()
+		}
+		attempts = append(attempts, a)
+
+		if err := sleepCtx(ctx, a.Delay); err != nil {
+			return nil, err
+		}
+	}
 
 	if err != nil {
 		log.Println(err)
+		if len(attempts) > 0 {
+			return nil, fmt.Errorf("%w (after %d attempts)", err, len(attempts)+1)
+		}
 		return nil, err
 	}
 
 	res := &Response{}
 
-	res.Headers = make(map[string]string)
 	res.Cookies = response.Cookies()
+	res.TLS = newTLSInfo(response.TLS, tlsCfg)
+	res.Proto = response.Proto
 
-	// Convert response headers to a bindable map
-	for key, values := range response.Header {
-		res.Headers[key] = values[0] // Get the first value for simplicity
-	}
+	res.Headers, res.Sorted = wlog.header(response)
 
 	defer response.Body.Close()
 
-	// Cap the read: io.ReadAll grows unbounded, so a large response buffers
-	// entirely into RAM (twice, counting the string copy below), spiking RSS
-	// that Go only lazily returns to the OS. The UI keeps ~2MB anyway; read a
-	// touch more so truncation is detectable. True size still comes from
-	// Content-Length below.
-	const maxBodyRead = 4 << 20
-	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodyRead+1))
-	if err != nil {
-		log.Println("Error reading response body:", err)
-		return nil, err
+	var body []byte
+
+	// The headers are in: a file streams for as long as it takes, a body
+	// kept in memory gets the timeout afresh
+	toFile := dl != nil && dl.wanted(response)
+	if deadline != nil {
+		if toFile {
+			deadline.stop()
+		} else {
+			deadline.start()
+		}
 	}
-	if len(body) > maxBodyRead {
-		body = body[:maxBodyRead]
+
+	if toFile {
+		var size int64
+		if body, size, err = dl.save(ctx, response); err != nil {
+			if deadline != nil {
+				err = deadline.err(err)
+			}
+			return nil, err
+		}
+		res.File = dl.path
+		res.WireSize, res.BodySize = size, size
+	} else {
+		// Cap the read: io.ReadAll grows unbounded, so a large response buffers
+		// entirely into RAM (twice, counting the string copy below), spiking RSS
+		// that Go only lazily returns to the OS. The UI keeps ~2MB anyway; read a
+		// touch more so truncation is detectable. True size still comes from
+		// Content-Length below. Download mode is the way to get all of it.
+		const maxBodyRead = 4 << 20
+		wire := &countingReader{r: newProgressReader(response.Body, Progress{Total: response.ContentLength}, progressFunc(ctx))}
+
+		// Encoded bodies are decoded here, with the head of the wire bytes
+		// kept for the raw view
+		var reader io.Reader = wire
+		var raw *headBuffer
+		if enc := response.Header.Get("Content-Encoding"); enc != "" && !strings.EqualFold(enc, "identity") {
+			raw = &headBuffer{max: maxBodyRead}
+			decoded, err := decodeBody(io.TeeReader(wire, raw), enc)
+			if err != nil {
+				return nil, err
+			}
+			defer decoded.Close()
+			reader = decoded
+			res.Encoding = enc
+		}
+
+		body, err = io.ReadAll(io.LimitReader(reader, maxBodyRead+1))
+		if deadline != nil {
+			err = deadline.err(err)
+		}
+		if err != nil {
+			log.Println("Error reading response body:", err)
+			return nil, err
+		}
+		if len(body) > maxBodyRead {
+			body = body[:maxBodyRead]
+			res.Truncated = true
+		}
+
+		// Prefer the server's Content-Length so the reported size stays
+		// honest even when we stopped reading at maxBodyRead. It counts
+		// wire bytes, so a truncated encoded body's decoded size is only
+		// a lower bound.
+		res.WireSize = max(wire.n, response.ContentLength)
+		res.BodySize = int64(len(body))
+		if raw == nil {
+			res.BodySize = max(res.BodySize, response.ContentLength)
+		} else {
+			res.Wire = raw.buf
+		}
 	}
 
 	// Total now includes the body download, which the old headers-only
@@ -281,28 +596,36 @@ func (r *Request) SendRequest(ctx context.Context) (*Response, error) {
 	if timings.TTFB > 0 {
 		timings.Download = timings.Total - timings.TTFB
 	}
-	res.Duration = timings.Total
+	res.Duration = time.Since(sendStart)
 	res.Timings = timings
+	res.URL = response.Request.URL.String()
+	res.Redirects = redirects
+	if len(attempts) > 0 {
+		res.Attempts = append(attempts, Attempt{Status: response.Status, Timings: timings})
+	}
 
 	res.Body = string(body)
+	res.Trailers = newHeader(response.Trailer)
+	res.RawLog = wlog.String()
 
 	if response != nil && response.Status != "" {
 		res.Status = response.Status
 	}
 
-	// Prefer the server's Content-Length so the reported size stays honest
-	// even when we stopped reading at maxBodyRead.
-	size := len(body)
-	if response.ContentLength > int64(size) {
-		size = int(response.ContentLength)
-	}
-	res.Size = bytestoHuman(size)
+	return res, nil
+}
 
-	if _, err = saveRequestData(r); err != nil {
-		return nil, err
+// SizeText is the body size for display, with the wire size and coding
+// when the body arrived compressed: "48 KB (9 KB br)".
+func (r *Response) SizeText() string {
+	size := bytestoHuman(int(r.BodySize))
+	if r.Encoding == "" {
+		return size
 	}
-
-	return res, nil
+	if r.Truncated {
+		size = "≥ " + size
+	}
+	return size + " (" + bytestoHuman(int(r.WireSize)) + " " + r.Encoding + ")"
 }
 
 func bytestoHuman(byteLen int) string {
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy re-sends a request that failed transiently. The zero value
+// sends once.
+type RetryPolicy struct {
+	MaxAttempts int    `json:"MaxAttempts,omitempty"` // total tries, the first included; ≤1 → no retries
+	Statuses    string `json:"Statuses,omitempty"`    // "429, 500-504"; empty → 408, 429, 500, 502, 503, 504 (curl's set)
+	StatusOnly  bool   `json:"StatusOnly,omitempty"`  // don't retry connection errors and timeouts
+	BaseDelayMs int    `json:"BaseDelayMs,omitempty"` // first backoff, doubled per retry; 0 → 500ms
+	MaxDelayMs  int    `json:"MaxDelayMs,omitempty"`  // cap on backoff and Retry-After; 0 → 30s
+}
+
+// Attempt is one try of a retried send; Response.Attempts lists them all,
+// the final one included.
+type Attempt struct {
+	Status  string // empty when the try failed without a response
+	Err     string
+	Timings Timings
+	Delay   time.Duration // wait before the next try; zero on the last
+}
+
+const defaultRetryStatuses = "408, 429, 500, 502, 503, 504"
+
+// retries reports whether the try that gave res/err should be repeated.
+func (p RetryPolicy) retries(res *http.Response, err error) bool {
+	if err != nil {
+		// The user cancelling isn't transient, nor is the redirect policy
+		var limit redirectLimitError
+		return !p.StatusOnly && !errors.Is(err, context.Canceled) && !errors.As(err, &limit)
+	}
+	list := p.Statuses
+	if strings.TrimSpace(list) == "" {
+		list = defaultRetryStatuses
+	}
+	return statusListed(res.StatusCode, list)
+}
+
+// statusListed matches code against "429, 500-504".
+func statusListed(code int, list string) bool {
+	for entry := range strings.SplitSeq(list, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(entry), "-")
+		from, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			continue
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				continue
+			}
+		}
+		if code >= from && code <= to {
+			return true
+		}
+	}
+	return false
+}
+
+// delay is the wait after try n (1-based): the server's Retry-After when
+// it sent one, else exponential backoff with equal jitter (half fixed,
+// half random) so parallel clients spread out. Both are capped.
+func (p RetryPolicy) delay(n int, res *http.Response) time.Duration {
+	maxDelay := 30 * time.Second
+	if p.MaxDelayMs > 0 {
+		maxDelay = time.Duration(p.MaxDelayMs) * time.Millisecond
+	}
+
+	if res != nil {
+		if d, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return min(d, maxDelay)
+		}
+	}
+
+	base := 500 * time.Millisecond
+	if p.BaseDelayMs > 0 {
+		base = time.Duration(p.BaseDelayMs) * time.Millisecond
+	}
+
+	d := min(base<<min(n-1, 30), maxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses delay-seconds or an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleepCtx waits d unless ctx ends first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
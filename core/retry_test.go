@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		code int
+		list string
+		want bool
+	}{
+		{503, "", true},
+		{501, "", false},
+		{501, "500-504", true},
+		{429, "429, 503", true},
+		{404, "429, bogus, 500-", false},
+	} {
+		if got := (RetryPolicy{Statuses: tt.list}).retries(&http.Response{StatusCode: tt.code}, nil); got != tt.want {
+			t.Errorf("retries(%d, %q) = %v, want %v", tt.code, tt.list, got, tt.want)
+		}
+	}
+
+	p := RetryPolicy{BaseDelayMs: 100, MaxDelayMs: 1000}
+	for n, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		if d := p.delay(n, nil); d < want/2 || d > want {
+			t.Errorf("delay(%d) = %v, want within [%v, %v]", n, d, want/2, want)
+		}
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": {"120"}}}
+	if d := p.delay(1, res); d != time.Second {
+		t.Errorf("Retry-After should be capped at MaxDelay, got %v", d)
+	}
+	res.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if d := p.delay(1, res); d != 0 {
+		t.Errorf("past Retry-After date = %v, want 0", d)
+	}
+}
+
+func TestSendRequestRetry(t *testing.T) {
+	var hits int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if hits < 3 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	r := testRequest(NewRequestID(), server.URL)
+	r.Method, r.BodyType, r.Body.Json = "POST", "JSON", `{"n":1}`
+	r.Settings.Retry = RetryPolicy{MaxAttempts: 5}
+	defer DeleteHistory(r.ID)
+
+	res, err := r.SendRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body != "ok" || hits != 3 || len(res.Attempts) != 3 {
+		t.Fatalf("body=%q hits=%d attempts=%d", res.Body, hits, len(res.Attempts))
+	}
+	if a := res.Attempts[0]; !strings.HasPrefix(a.Status, "503") || a.Timings.Total <= 0 {
+		t.Fatalf("first attempt: %+v", a)
+	}
+	if last := res.Attempts[2]; !strings.HasPrefix(last.Status, "200") || last.Delay != 0 {
+		t.Fatalf("last attempt: %+v", last)
+	}
+	for _, b := range bodies {
+		if b != `{"n":1}` {
+			t.Fatalf("body not replayed on retry: %q", bodies)
+		}
+	}
+
+	// Out of attempts: the last response stands
+	hits = 0
+	r.Settings.Retry.MaxAttempts, r.Settings.Retry.BaseDelayMs = 2, 1
+	if res, err = r.SendRequest(context.Background()); err != nil || hits != 2 || !strings.HasPrefix(res.Status, "503") {
+		t.Fatalf("err=%v hits=%d status=%q", err, hits, res.Status)
+	}
+
+	// Connection errors retry unless StatusOnly
+	server.Close()
+	if _, err = r.SendRequest(context.Background()); err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("connection error: %v", err)
+	}
+	r.Settings.Retry.StatusOnly = true
+	if _, err = r.SendRequest(context.Background()); err == nil || strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("StatusOnly retried a connection error: %v", err)
+	}
+}
+
+// Hitting the redirect limit fails the same way every try.
+func TestRetryRedirectLimit(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+	defer server.Close()
+
+	r := testRequest(NewRequestID(), server.URL)
+	r.Settings.MaxRedirects = 1
+	r.Settings.Retry = RetryPolicy{MaxAttempts: 3, BaseDelayMs: 1}
+	defer DeleteHistory(r.ID)
+
+	_, err := r.SendRequest(context.Background())
+	if err == nil || strings.Contains(err.Error(), "attempts") || hits != 1 {
+		t.Fatalf("err=%v hits=%d, want a single try", err, hits)
+	}
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+)
+
+// Secret variables, secret auth credentials and the proxy and certificate
+// passwords live in the vault. Every file this app writes — environments,
+// history, collections, the global proxy and host certificates — gets a
+// redacted copy with those values blanked, and loading fills them back in.
+// In memory they stay plain, so ApplyEnv and SendRequest need no changes.
+//
+// A locked vault only fails a save that has a new secret to store; blank
+// values (never filled because the vault was locked) are written as-is.
+
+// credentials are the Auth fields that Secret moves into the vault.
+func (a *Auth) credentials() []*string {
+	return []*string{&a.BasicPass, &a.BearerAuth, &a.APIKeyValue, &a.OAuthClientSecret, &a.JWTKey}
+}
+
+// redactRequest returns the on-disk copy of r. Secret credentials go to
+// the vault under r's SecretRef, assigned on first save; the settings'
+// passwords always do, under the settings' own.
+func redactRequest(r *Request) (*Request, error) {
+	c := r.Clone()
+	c.ID = r.ID
+	c.Settings.SecretRef = r.Settings.SecretRef
+	err := redactSettings(r, c)
+
+	if r.Auth == nil {
+		return c, err
+	}
+	c.Auth.SecretRef = r.Auth.SecretRef
+	if !r.Auth.Secret {
+		return c, err
+	}
+
+	if r.Auth.SecretRef == "" {
+		r.Auth.SecretRef = NewRequestID()
+		c.Auth.SecretRef = r.Auth.SecretRef
+	}
+
+	aerr := vaultSetValues("auth/"+r.Auth.SecretRef, c.Auth.credentials())
+	return c, cmp.Or(aerr, err)
+}
+
+// secrets are the Settings fields kept in the vault whatever the auth's
+// Secret says: passwords for the route there, not the API's credentials.
+func (s *Settings) secrets() []*string {
+	return []*string{&s.Proxy.Pass, &s.ClientCert.Passphrase}
+}
+
+// redactSettings blanks c's settings passwords, storing r's under its
+// SecretRef, assigned the first time there's one to store.
+func redactSettings(r, c *Request) error {
+	if r.Settings.SecretRef == "" {
+		if !slices.ContainsFunc(r.Settings.secrets(), func(f *string) bool { return *f != "" }) {
+			return nil
+		}
+		r.Settings.SecretRef = NewRequestID()
+		c.Settings.SecretRef = r.Settings.SecretRef
+	}
+	return vaultSetValues("settings/"+r.Settings.SecretRef, c.Settings.secrets())
+}
+
+// vaultSetValues stores fields under key, blanking them; all blank drops
+// the entry.
+func vaultSetValues(key string, fields []*string) error {
+	var values []string
+	empty := true
+	for _, f := range fields {
+		values = append(values, *f)
+		empty = empty && *f == ""
+		*f = ""
+	}
+
+	if empty {
+		return vaultSet(key, "")
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return vaultSet(key, string(data))
+}
+
+// vaultFillValues is vaultSetValues' reverse; fields already set are kept.
+func vaultFillValues(key string, fields []*string) {
+	data, ok := vaultGet(key)
+	if !ok {
+		return
+	}
+
+	var values []string
+	json.Unmarshal([]byte(data), &values)
+
+	for i, f := range fields {
+		if i < len(values) && *f == "" {
+			*f = values[i]
+		}
+	}
+}
+
+// FillSecrets restores secret credentials and the settings' passwords from
+// the vault. A locked vault or a missing entry leaves them blank, and
+// values typed while the vault was locked are kept. LoadRequest and
+// LoadCollections already do this.
+func (r *Request) FillSecrets() {
+	if r.Settings.SecretRef != "" {
+		vaultFillValues("settings/"+r.Settings.SecretRef, r.Settings.secrets())
+	}
+	if r.Auth != nil && r.Auth.Secret && r.Auth.SecretRef != "" {
+		vaultFillValues("auth/"+r.Auth.SecretRef, r.Auth.credentials())
+	}
+}
+
+// forgetRequest drops r's vault entries once nothing on disk refers to them.
+func forgetRequest(r *Request) {
+	if r.Auth != nil && r.Auth.SecretRef != "" {
+		vaultSet("auth/"+r.Auth.SecretRef, "")
+	}
+	if r.Settings.SecretRef != "" {
+		vaultSet("settings/"+r.Settings.SecretRef, "")
+	}
+}
+
+// forgetSecretRefs detaches a copy of a request from the original's vault
+// entries, so it stores its secrets separately.
+func (r *Request) forgetSecretRefs() {
+	if r.Auth != nil {
+		r.Auth.SecretRef = ""
+	}
+	r.Settings.SecretRef = ""
+}
+
+func envSecretKey(env, key string) string {
+	return "env/" + env + "/" + key
+}
+
+func globalSecretKey(key string) string {
+	return "global/" + key
+}
+
+func envProxyKey(env string) string {
+	return "proxy/env/" + env
+}
+
+// redacted returns the on-disk copy of the store and syncs the vault's
+// env/ entries to exactly its secret variables, so renamed or deleted
+// variables don't linger there.
+func (s *EnvStore) redacted() (*EnvStore, error) {
+	c := &EnvStore{Active: s.Active, OSVars: s.OSVars}
+	secrets := map[string]string{}
+	globals := map[string]string{}
+	proxies := map[string]string{}
+
+	if s.Globals != nil {
+		vars := slices.Clone(*s.Globals)
+		for i, v := range vars {
+			if v.Secret && v.Key != "" {
+				globals[globalSecretKey(v.Key)] = v.Value
+				vars[i].Value = ""
+			}
+		}
+		c.Globals = &vars
+	}
+
+	for _, e := range s.Envs {
+		ce := &Environment{Name: e.Name, Proxy: e.Proxy, DNS: e.DNS, Dotenv: e.Dotenv}
+		proxies[envProxyKey(e.Name)] = e.Proxy.Pass
+		ce.Proxy.Pass = ""
+
+		if e.Variables != nil {
+			vars := slices.Clone(*e.Variables)
+			for i, v := range vars {
+				if v.Secret && v.Key != "" {
+					secrets[envSecretKey(e.Name, v.Key)] = v.Value
+					vars[i].Value = ""
+				}
+			}
+			ce.Variables = &vars
+		}
+
+		c.Envs = append(c.Envs, ce)
+	}
+
+	err := vaultReplace("env/", secrets)
+	if gerr := vaultReplace("global/", globals); err == nil {
+		err = gerr
+	}
+	if perr := vaultReplace("proxy/env/", proxies); err == nil {
+		err = perr
+	}
+	return c, err
+}
+
+// FillSecrets restores secret variable values and proxy passwords from the
+// vault. LoadEnvStore already does this; call it again after UnlockVault.
+// Values typed while the vault was locked are kept.
+func (s *EnvStore) FillSecrets() {
+	for _, e := range s.Envs {
+		fillSecretVars(e.Variables, func(key string) string { return envSecretKey(e.Name, key) })
+		if v, ok := vaultGet(envProxyKey(e.Name)); ok && e.Proxy.Pass == "" {
+			e.Proxy.Pass = v
+		}
+	}
+	fillSecretVars(s.Globals, globalSecretKey)
+}
+
+func fillSecretVars(vars *[]FormType, vaultKey func(key string) string) {
+	if vars == nil {
+		return
+	}
+
+	for i, v := range *vars {
+		if !v.Secret || v.Value != "" {
+			continue
+		}
+		if val, ok := vaultGet(vaultKey(v.Key)); ok {
+			(*vars)[i].Value = val
+		}
+	}
+}
+
+// redactCollections is redactRequest over every collection entry.
+func redactCollections(collections []*Collection) ([]*Collection, error) {
+	var firstErr error
+	out := make([]*Collection, 0, len(collections))
+
+	for _, col := range collections {
+		c := &Collection{Name: col.Name}
+		for _, r := range col.Requests {
+			saved, err := redactRequest(r)
+			if firstErr == nil {
+				firstErr = err
+			}
+			c.Requests = append(c.Requests, saved)
+		}
+		out = append(out, c)
+	}
+
+	return out, firstErr
+}
+
+// SecretValues lists the values of rows' checked secret variables, as
+// written and expanded, for Preview to mask.
+func SecretValues(rows ...*[]FormType) []string {
+	var values []string
+	for _, r := range rows {
+		if r == nil {
+			continue
+		}
+		for _, v := range *r {
+			if !v.Checked || !v.Secret || v.Value == "" {
+				continue
+			}
+			values = append(values, v.Value)
+			if expanded := ApplyEnv(v.Value); expanded != v.Value {
+				values = append(values, expanded)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempConfigDir points the config dir (and so the vault) at a fresh temp
+// dir, reopening the real vault afterwards.
+func tempConfigDir(t *testing.T) string {
+	t.Cleanup(func() { OpenVault() }) // runs after Setenv restores the env
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := OpenVault(); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "myapi")
+}
+
+func assertNotInFile(t *testing.T, file, secret string) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Fatalf("%s contains the secret in plaintext: %s", filepath.Base(file), data)
+	}
+}
+
+func TestSecretVariables(t *testing.T) {
+	dir := tempConfigDir(t)
+
+	store := &EnvStore{Active: "prod", Envs: []*Environment{{Name: "prod", Variables: &[]FormType{
+		{Checked: true, Key: "host", Value: "api.example.com"},
+		{Checked: true, Key: "apiKey", Value: "sk-live-123", Secret: true},
+	}}}, Globals: &[]FormType{
+		{Checked: true, Key: "orgToken", Value: "org-456", Secret: true},
+	}}
+	if err := SaveEnvStore(store); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"sk-live-123", "org-456"} {
+		assertNotInFile(t, filepath.Join(dir, "environments.json"), secret)
+		assertNotInFile(t, filepath.Join(dir, "vault.json"), secret)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "environments.json")); info.Mode().Perm() != 0o600 {
+		t.Fatalf("environments.json mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded := LoadEnvStore()
+	if got := loaded.ActiveEnv().VarMap()["apiKey"]; got != "sk-live-123" {
+		t.Fatalf("secret not restored from keyring vault: %q", got)
+	}
+	if got := VarsOf(loaded.Globals)["orgToken"]; got != "org-456" {
+		t.Fatalf("secret global not restored from keyring vault: %q", got)
+	}
+
+	// Master password: locked on open, wrong password refused.
+	if err := SetVaultPassword("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	OpenVault()
+	if !VaultLocked() || VaultMode() != VaultPassword {
+		t.Fatalf("locked=%v mode=%q after reopen", VaultLocked(), VaultMode())
+	}
+
+	locked := LoadEnvStore()
+	if got := locked.ActiveEnv().VarMap()["apiKey"]; got != "" {
+		t.Fatalf("secret readable while locked: %q", got)
+	}
+
+	// Saving while locked writes the blank without wiping the vault entry.
+	if err := SaveEnvStore(locked); err != nil {
+		t.Fatalf("save while locked with nothing new: %v", err)
+	}
+	(*locked.Envs[0].Variables)[0].Value = "changed"
+	(*locked.Envs[0].Variables)[1].Value = "new-key"
+	if err := SaveEnvStore(locked); err != ErrVaultLocked {
+		t.Fatalf("save of a new secret while locked: %v, want ErrVaultLocked", err)
+	}
+
+	if err := UnlockVault("wrong"); err == nil {
+		t.Fatal("wrong password unlocked the vault")
+	}
+	if err := UnlockVault("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	unlocked := LoadEnvStore()
+	if got := unlocked.ActiveEnv().VarMap()["apiKey"]; got != "sk-live-123" {
+		t.Fatalf("after unlock apiKey = %q", got)
+	}
+
+	// Deleting a secret variable drops it from the vault.
+	*unlocked.Envs[0].Variables = (*unlocked.Envs[0].Variables)[:1]
+	if err := SaveEnvStore(unlocked); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vaultGet(envSecretKey("prod", "apiKey")); ok {
+		t.Fatal("deleted secret variable still in the vault")
+	}
+}
+
+func TestSecretAuth(t *testing.T) {
+	dir := tempConfigDir(t)
+
+	r := testRequest(NewRequestID(), "http://example.com")
+	r.Auth = &Auth{BasicUser: "alice", BasicPass: "pa55word", Secret: true}
+
+	if _, err := saveRequestData(r); err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteHistory(r.ID)
+
+	assertNotInFile(t, filepath.Join(dir, "history", r.ID+".json"), "pa55word")
+	if r.Auth.BasicPass != "pa55word" {
+		t.Fatal("saving blanked the in-memory request")
+	}
+
+	loaded, err := LoadRequest(r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Auth.BasicPass != "pa55word" || loaded.Auth.BasicUser != "alice" {
+		t.Fatalf("loaded auth = %+v", loaded.Auth)
+	}
+
+	// Collections: the snapshot gets its own vault entry.
+	cols := []*Collection{{Name: "c", Requests: []*Request{r.Clone()}}}
+	if err := SaveCollections(cols); err != nil {
+		t.Fatal(err)
+	}
+	assertNotInFile(t, filepath.Join(dir, "collections.json"), "pa55word")
+
+	if ref := cols[0].Requests[0].Auth.SecretRef; ref == "" || ref == r.Auth.SecretRef {
+		t.Fatalf("collection entry ref %q shares or lacks a vault slot (history %q)", ref, r.Auth.SecretRef)
+	}
+	if got := LoadCollections()[0].Requests[0].Auth.BasicPass; got != "pa55word" {
+		t.Fatalf("collection BasicPass = %q", got)
+	}
+
+	ref := r.Auth.SecretRef
+	if err := DeleteHistory(r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vaultGet("auth/" + ref); ok {
+		t.Fatal("deleted history entry's secrets still in the vault")
+	}
+}
+
+// Proxy passwords and certificate passphrases go to the vault from every
+// file, whether or not the auth is marked secret.
+func TestSecretSettings(t *testing.T) {
+	dir := tempConfigDir(t)
+
+	r := testRequest(NewRequestID(), "http://example.com")
+	r.Settings.Proxy = ProxyConfig{URL: "http://proxy:3128", User: "u", Pass: "proxy-pw"}
+	r.Settings.ClientCert = CertConfig{PFXFile: "client.p12", Passphrase: "p12-pw"}
+	if _, err := saveRequestData(r); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"proxy-pw", "p12-pw"} {
+		assertNotInFile(t, filepath.Join(dir, "history", r.ID+".json"), secret)
+	}
+	loaded, err := LoadRequest(r.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Settings.Proxy.Pass != "proxy-pw" || loaded.Settings.ClientCert.Passphrase != "p12-pw" {
+		t.Fatalf("loaded settings = %+v", loaded.Settings)
+	}
+	ref := r.Settings.SecretRef
+	if err := DeleteHistory(r.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vaultGet("settings/" + ref); ok {
+		t.Fatal("deleted history entry's settings passwords still in the vault")
+	}
+
+	store := &EnvStore{Envs: []*Environment{{Name: "prod", Proxy: ProxyConfig{URL: "http://proxy:3128", Pass: "env-pw"}}}}
+	if err := SaveEnvStore(store); err != nil {
+		t.Fatal(err)
+	}
+	assertNotInFile(t, filepath.Join(dir, "environments.json"), "env-pw")
+	if got := LoadEnvStore().Envs[0].Proxy.Pass; got != "env-pw" {
+		t.Fatalf("env proxy pass = %q", got)
+	}
+
+	if err := SaveGlobalProxy(ProxyConfig{URL: "http://proxy:3128", Pass: "global-pw"}); err != nil {
+		t.Fatal(err)
+	}
+	assertNotInFile(t, filepath.Join(dir, "proxy.json"), "global-pw")
+	if got := LoadGlobalProxy().Pass; got != "global-pw" {
+		t.Fatalf("global proxy pass = %q", got)
+	}
+
+	if err := SaveCertStore(&CertStore{Hosts: []*CertConfig{{Host: "a.test", PFXFile: "a.p12", Passphrase: "host-pw"}}}); err != nil {
+		t.Fatal(err)
+	}
+	assertNotInFile(t, filepath.Join(dir, "certificates.json"), "host-pw")
+	if got := LoadCertStore().Hosts[0].Passphrase; got != "host-pw" {
+		t.Fatalf("host cert passphrase = %q", got)
+	}
+}
+
+// A lost or damaged keyring key locks the vault instead of being replaced
+// by a new one that can't decrypt what's already stored.
+func TestVaultKeyringLost(t *testing.T) {
+	dir := tempConfigDir(t)
+
+	if err := vaultSet("global/token", "t-123"); err != nil {
+		t.Fatal(err)
+	}
+	vaultFile := filepath.Join(dir, "vault.json")
+	before, err := os.ReadFile(vaultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(dir, "keyring.key")
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, damage := range map[string]func() error{
+		"missing":   func() error { return os.Remove(keyFile) },
+		"truncated": func() error { return os.WriteFile(keyFile, key[:16], 0o600) },
+	} {
+		if err := damage(); err != nil {
+			t.Fatal(err)
+		}
+		if err := OpenVault(); err == nil {
+			t.Fatalf("%s key: vault opened", name)
+		}
+		if !VaultLocked() || VaultError() == nil {
+			t.Fatalf("%s key: locked=%v err=%v", name, VaultLocked(), VaultError())
+		}
+		if err := vaultSet("global/other", "o-456"); err != ErrVaultLocked {
+			t.Fatalf("%s key: save = %v, want ErrVaultLocked", name, err)
+		}
+		if after, _ := os.ReadFile(vaultFile); string(after) != string(before) {
+			t.Fatalf("%s key: vault.json rewritten", name)
+		}
+		if got, _ := os.ReadFile(keyFile); len(got) == 32 {
+			t.Fatalf("%s key: replaced with a new key", name)
+		}
+	}
+
+	// Restoring the key and retrying unlocks it again.
+	if err := os.WriteFile(keyFile, key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnlockVault(""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := vaultGet("global/token"); got != "t-123" || VaultError() != nil {
+		t.Fatalf("after restoring the key: %q, err=%v", got, VaultError())
+	}
+}
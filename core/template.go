@@ -0,0 +1,268 @@
+package core
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateFuncs are the {{$name args}} built-ins. Arguments are
+// space-separated; "quoted" ones may hold spaces, and a bare word naming a
+// variable stands for its value (except for $env, which takes OS names).
+var templateFuncs = map[string]func(args []string) (string, error){
+	"uuid": func([]string) (string, error) { return newUUID(), nil },
+	"timestamp": func([]string) (string, error) {
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	},
+	"isoDate": func([]string) (string, error) {
+		return time.Now().UTC().Format(time.RFC3339), nil
+	},
+	"randomInt": randomInt,
+	"base64": func(a []string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(strings.Join(a, " "))), nil
+	},
+	"base64Decode": func(a []string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(strings.Join(a, " "))
+		return string(b), err
+	},
+	"urlEncode": func(a []string) (string, error) { return url.QueryEscape(strings.Join(a, " ")), nil },
+	"urlDecode": func(a []string) (string, error) { return url.QueryUnescape(strings.Join(a, " ")) },
+	"md5":       hashFunc(func(b []byte) []byte { s := md5.Sum(b); return s[:] }),
+	"sha1":      hashFunc(func(b []byte) []byte { s := sha1.Sum(b); return s[:] }),
+	"sha256":    hashFunc(func(b []byte) []byte { s := sha256.Sum256(b); return s[:] }),
+	"env": func(a []string) (string, error) {
+		if len(a) != 1 {
+			return "", errors.New("takes one variable name")
+		}
+		v, ok := os.LookupEnv(a[0])
+		if !ok {
+			return "", fmt.Errorf("%s is not set", a[0])
+		}
+		return v, nil
+	},
+}
+
+// FuncNames lists the built-ins as "$name", sorted, for autocompletion.
+func FuncNames() []string {
+	var names []string
+	for name := range templateFuncs {
+		names = append(names, "$"+name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// randomInt is {{$randomInt}} (0–1000) or {{$randomInt min max}}, both
+// ends included.
+func randomInt(a []string) (string, error) {
+	lo, hi := 0, 1000
+	if len(a) != 0 {
+		if len(a) != 2 {
+			return "", errors.New("takes a min and a max")
+		}
+		var err error
+		if lo, err = strconv.Atoi(a[0]); err != nil {
+			return "", err
+		}
+		if hi, err = strconv.Atoi(a[1]); err != nil {
+			return "", err
+		}
+		if hi < lo {
+			return "", errors.New("max is below min")
+		}
+	}
+	return strconv.Itoa(lo + mrand.IntN(hi-lo+1)), nil
+}
+
+// hashFunc hex-encodes sum over the joined arguments.
+func hashFunc(sum func([]byte) []byte) func([]string) (string, error) {
+	return func(a []string) (string, error) {
+		return hex.EncodeToString(sum([]byte(strings.Join(a, " ")))), nil
+	}
+}
+
+// expand substitutes every {{ key }} and {{$func args}} in s. A value
+// holding placeholders of its own is expanded in turn, so variables can be
+// built from others. Whatever can't be resolved — undefined, failing or
+// circular — stays literal, like Postman, and is described in *missing
+// when missing isn't nil.
+func expand(s string, vars map[string]string, missing *[]string) string {
+	e := &expander{vars: vars, missing: missing}
+	return e.expand(s)
+}
+
+// expander carries one expand call's state through nested values.
+type expander struct {
+	vars    map[string]string
+	missing *[]string
+	stack   []string // variables being expanded, outermost first
+}
+
+func (e *expander) expand(s string) string {
+	var b strings.Builder
+	for {
+		before, placeholder, expr, after, ok := nextPlaceholder(s)
+		if !ok {
+			break
+		}
+		b.WriteString(before)
+		s = after
+
+		v, err := e.eval(expr)
+		if err != nil {
+			if e.missing != nil {
+				*e.missing = append(*e.missing, err.Error())
+			}
+			b.WriteString(placeholder)
+			continue
+		}
+		b.WriteString(v)
+	}
+	b.WriteString(s)
+
+	return b.String()
+}
+
+// variable resolves name's value, expanding placeholders inside it.
+func (e *expander) variable(name string) (string, error) {
+	v, ok := e.vars[name]
+	if !ok {
+		return "", fmt.Errorf("{{%s}} is not defined", name)
+	}
+
+	if i := slices.Index(e.stack, name); i >= 0 {
+		cycle := append(slices.Clone(e.stack[i:]), name)
+		return "", fmt.Errorf("{{%s}} is circular: %s", name, strings.Join(cycle, " → "))
+	}
+	if !strings.Contains(v, "{{") {
+		return v, nil
+	}
+
+	e.stack = append(e.stack, name)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+
+	return e.expand(v), nil
+}
+
+// Placeholders lists the trimmed insides of s's {{…}}s in order: variable
+// names, and "$func args" for built-ins.
+func Placeholders(s string) []string {
+	var exprs []string
+	for {
+		_, _, expr, after, ok := nextPlaceholder(s)
+		if !ok {
+			return exprs
+		}
+		exprs = append(exprs, expr)
+		s = after
+	}
+}
+
+// nextPlaceholder splits s around its first complete {{…}}; expr is the
+// inside, trimmed.
+func nextPlaceholder(s string) (before, placeholder, expr, after string, ok bool) {
+	start := strings.Index(s, "{{")
+	if start < 0 {
+		return "", "", "", "", false
+	}
+	end := strings.Index(s[start+2:], "}}")
+	if end < 0 {
+		return "", "", "", "", false
+	}
+	end += start + 4
+
+	placeholder = s[start:end]
+	return s[:start], placeholder, strings.TrimSpace(placeholder[2 : len(placeholder)-2]), s[end:], true
+}
+
+// eval resolves the trimmed inside of one {{…}}.
+func (e *expander) eval(expr string) (string, error) {
+	name, rest, isFunc := strings.Cut(expr, " ")
+	if !strings.HasPrefix(expr, "$") {
+		return e.variable(expr)
+	}
+
+	name = strings.TrimPrefix(name, "$")
+	fn, ok := templateFuncs[name]
+	if !ok {
+		return "", fmt.Errorf("{{$%s}} is not a function", name)
+	}
+
+	var args []string
+	if isFunc {
+		var err error
+		if args, err = e.splitArgs(rest, name != "env"); err != nil {
+			return "", fmt.Errorf("{{$%s}}: %w", name, err)
+		}
+	}
+
+	v, err := fn(args)
+	if err != nil {
+		return "", fmt.Errorf("{{$%s}}: %w", name, err)
+	}
+	return v, nil
+}
+
+// splitArgs splits function arguments on spaces, keeping "quoted" runs
+// (with \" and \\ escapes) whole. With lookup, bare words naming a variable
+// become its value.
+func (e *expander) splitArgs(s string, lookup bool) ([]string, error) {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return args, nil
+		}
+
+		if s[0] != '"' {
+			word, rest, _ := strings.Cut(s, " ")
+			if _, ok := e.vars[word]; ok && lookup {
+				v, err := e.variable(word)
+				if err != nil {
+					return nil, err
+				}
+				word = v
+			}
+			args = append(args, word)
+			s = rest
+			continue
+		}
+
+		var arg strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			arg.WriteByte(s[i])
+		}
+		if i == len(s) {
+			return nil, errors.New("unterminated quote")
+		}
+		args = append(args, arg.String())
+		s = s[i+1:]
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("MYAPI_TEST_HOME", "/home/dev")
+
+	vars := map[string]string{"user": "ada", "pass": "s3cret", "q": "a b&c", "max": "5"}
+
+	for _, tc := range []struct{ in, want string }{
+		{"{{ user }}:{{pass}}", "ada:s3cret"},
+		{"{{$base64 \"ada:s3cret\"}}", "YWRhOnMzY3JldA=="},
+		{"{{$base64 user}}", "YWRh"},          // bare word → variable
+		{"{{$base64 nobody}}", "bm9ib2R5"},    // bare word, no such variable → literal
+		{"{{$base64Decode YWRh}}", "ada"},     // decoding
+		{"?q={{$urlEncode q}}", "?q=a+b%26c"}, // encoding a variable
+		{"{{$urlDecode \"a%20b\"}}", "a b"},   // quoted literal
+		{"{{$md5 user}}", "8c8d357b5e872bbacd45197626bd5759"},
+		{"{{$sha256 \"\"}}", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"{{$env MYAPI_TEST_HOME}}/x", "/home/dev/x"},
+		{"{{missing}} {{$nope}} {{$env MYAPI_UNSET_VAR}}", "{{missing}} {{$nope}} {{$env MYAPI_UNSET_VAR}}"},
+		{"{{unclosed", "{{unclosed"},
+	} {
+		if got := expand(tc.in, vars, nil); got != tc.want {
+			t.Errorf("expand(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if got := expand("{{$uuid}}", nil, nil); !uuid.MatchString(got) {
+		t.Errorf("$uuid = %q", got)
+	}
+
+	ts, err := strconv.ParseInt(expand("{{$timestamp}}", nil, nil), 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Errorf("$timestamp = %d, %v", ts, err)
+	}
+
+	if _, err := time.Parse(time.RFC3339, expand("{{ $isoDate }}", nil, nil)); err != nil {
+		t.Errorf("$isoDate: %v", err)
+	}
+
+	for range 50 {
+		n, err := strconv.Atoi(expand("{{$randomInt 1 max}}", vars, nil))
+		if err != nil || n < 1 || n > 5 {
+			t.Fatalf("$randomInt 1 max = %d, %v", n, err)
+		}
+	}
+
+	var missing []string
+	expand("{{missing}} {{$randomInt 9 1}} {{$base64 \"open}}", vars, &missing)
+	want := []string{
+		"{{missing}} is not defined",
+		"{{$randomInt}}: max is below min",
+		"{{$base64}}: unterminated quote",
+	}
+	if strings.Join(missing, "|") != strings.Join(want, "|") {
+		t.Errorf("missing = %q, want %q", missing, want)
+	}
+}
+
+// Strict mode fails before anything is sent, and only over what would be
+// sent: unchecked rows and other body types don't count.
+func TestStrictVars(t *testing.T) {
+	SetActiveVars(map[string]string{"host": "http://127.0.0.1:1"})
+	defer SetActiveVars(nil)
+
+	req := &Request{
+		ID:       "strictvars",
+		Method:   "POST",
+		URL:      "{{host}}/{{ path }}",
+		Headers:  &[]FormType{{Checked: false, Key: "X-Off", Value: "{{off}}"}},
+		BodyType: "JSON",
+		Body:     Body{Json: `{"id":"{{$uuid}}"}`, Text: "{{unused}}"},
+		Settings: Settings{StrictVars: true},
+	}
+	defer DeleteHistory(req.ID)
+
+	if got := req.Unresolved(); len(got) != 1 || got[0] != "{{path}} is not defined" {
+		t.Fatalf("Unresolved() = %q", got)
+	}
+
+	_, err := req.SendRequest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unresolved placeholders: {{path}} is not defined") {
+		t.Fatalf("err = %v", err)
+	}
+
+	// Every field the chosen auth signs or fetches with counts, the other
+	// auth types' fields don't
+	auth := &Auth{
+		JWTKey: "{{jk}}", JWTHeader: `{"kid":"{{jh}}"}`, JWTClaims: `{"sub":"{{jc}}"}`, JWTName: "{{jn}}",
+		OAuthTokenURL: "{{ou}}", OAuthClientID: "{{oi}}", OAuthClientSecret: "{{os}}", OAuthScope: "{{oc}}",
+	}
+	for authType, want := range map[string][]string{
+		"JWT":    {"{{jk}} is not defined", "{{jh}} is not defined", "{{jc}} is not defined", "{{jn}} is not defined"},
+		"OAuth2": {"{{ou}} is not defined", "{{oi}} is not defined", "{{os}} is not defined", "{{oc}} is not defined"},
+	} {
+		r := &Request{URL: "{{host}}", Headers: &[]FormType{}, AuthType: authType, Auth: auth}
+		if got := r.Unresolved(); !slices.Equal(got, want) {
+			t.Errorf("%s: Unresolved() = %q", authType, got)
+		}
+	}
+
+	if got := UnresolvedIn("{{host}}/{{path}}/{{$nope}}"); !slices.Equal(got, []string{"{{path}} is not defined", "{{$nope}} is not a function"}) {
+		t.Fatalf("UnresolvedIn = %q", got)
+	}
+	if got := VarNames(); !slices.Equal(got, []string{"host"}) {
+		t.Fatalf("VarNames = %q", got)
+	}
+	if got := FuncNames(); !slices.Contains(got, "$uuid") || !slices.IsSorted(got) {
+		t.Fatalf("FuncNames = %q", got)
+	}
+}
+
+func TestNestedVars(t *testing.T) {
+	vars := map[string]string{
+		"host":     "api.example.com",
+		"port":     "8443",
+		"origin":   "https://{{host}}:{{ port }}",
+		"baseUrl":  "{{origin}}/api",
+		"auth":     "{{$base64 userPass}}",
+		"userPass": "ada:{{pass}}",
+		"pass":     "s3cret",
+		"a":        "<{{b}}>",
+		"b":        "[{{a}}]",
+		"self":     "x{{self}}",
+		"partial":  "{{host}}/{{nope}}",
+	}
+
+	var missing []string
+	got := expand("{{baseUrl}}/users {{auth}} {{a}} {{self}} {{partial}}", vars, &missing)
+	want := "https://api.example.com:8443/api/users YWRhOnMzY3JldA== <[{{a}}]> x{{self}} api.example.com/{{nope}}"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	wantMissing := []string{
+		"{{a}} is circular: a → b → a",
+		"{{self}} is circular: self → self",
+		"{{nope}} is not defined",
+	}
+	if !slices.Equal(missing, wantMissing) {
+		t.Errorf("missing = %q, want %q", missing, wantMissing)
+	}
+
+	// Same answer every time: nothing depends on map order
+	for range 20 {
+		if again := expand("{{baseUrl}}/users {{auth}} {{a}} {{self}} {{partial}}", vars, nil); again != got {
+			t.Fatalf("expansion changed: %q", again)
+		}
+	}
+}
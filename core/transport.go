@@ -0,0 +1,249 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Settings.Protocol values. Empty negotiates as Go does by default:
+// HTTP/2 over TLS when the server offers it, HTTP/1.1 otherwise.
+const (
+	ProtoHTTP1 = "HTTP/1.1"
+	ProtoHTTP2 = "HTTP/2" // h2 over TLS; h2c with prior knowledge on http://
+	ProtoHTTP3 = "HTTP/3" // QUIC; https:// only
+)
+
+// Pooled transports are keyed by everything that shapes a connection, so
+// sends with the same TLS, proxy and protocol settings share keep-alive
+// connections. File paths are keyed as resolved, not by content: editing a
+// certificate in place needs a fresh connection (or CloseIdleConnections).
+type poolKey struct {
+	protocol    string
+	skipVerify  bool
+	cert        CertConfig
+	proxy       ProxyConfig
+	dns         string
+	maxIdle     int
+	idleTimeout int
+}
+
+type pooledTransport struct {
+	rt       http.RoundTripper
+	tlsCfg   *tls.Config
+	lastUsed time.Time
+}
+
+// maxPooledTransports caps distinct transport setups; past it the least
+// recently used one is closed.
+const maxPooledTransports = 16
+
+var (
+	poolMu sync.Mutex
+	pool   = map[poolKey]*pooledTransport{}
+)
+
+// oneOff marks a transport built for a single send (Settings.FreshConnection);
+// closeClient tears it down instead of returning it to the pool.
+type oneOff struct{ http.RoundTripper }
+
+func newPoolKey(s Settings, hostport string) poolKey {
+	c := certFor(s, hostport)
+	c.Host = ""
+	c.CertFile, c.KeyFile, c.PFXFile = ApplyEnv(c.CertFile), ApplyEnv(c.KeyFile), ApplyEnv(c.PFXFile)
+	c.Passphrase, c.CAFile = ApplyEnv(c.Passphrase), ApplyEnv(c.CAFile)
+
+	p := effectiveProxy(s)
+	p.URL, p.User, p.Pass, p.Bypass = ApplyEnv(p.URL), ApplyEnv(p.User), ApplyEnv(p.Pass), ApplyEnv(p.Bypass)
+
+	return poolKey{
+		protocol:    s.Protocol,
+		skipVerify:  s.SkipTLSVerify,
+		cert:        c,
+		proxy:       p,
+		dns:         effectiveDNS(s).resolved().poolKey(),
+		maxIdle:     s.MaxIdlePerHost,
+		idleTimeout: s.IdleTimeoutSec,
+	}
+}
+
+// newClient builds the http.Client for one send to hostport. SendRequest
+// and oauthToken both go through here so transport settings (TLS, client
+// certificates, protocol, proxy) apply to the token fetch too. The
+// transport comes from the pool unless Settings.FreshConnection asks for a
+// throwaway one. The returned tls.Config is nil when Go's TLS defaults
+// apply. Callers release the client with closeClient.
+func newClient(s Settings, hostport string, timeout time.Duration) (*http.Client, *tls.Config, error) {
+	client := &http.Client{Timeout: timeout}
+
+	if s.Protocol == ProtoHTTP3 {
+		proxy := effectiveProxy(s)
+		host, _, _ := net.SplitHostPort(hostport)
+		if proxy.URL != "" && !proxy.Direct && !bypassed(cmp.Or(host, hostport), ApplyEnv(proxy.Bypass)) {
+			return nil, nil, errors.New("HTTP/3 can't go through a proxy; pick another protocol or bypass the proxy for this host")
+		}
+	}
+
+	if s.FreshConnection {
+		rt, tlsCfg, err := newTransport(s, hostport)
+		if err != nil {
+			return nil, nil, err
+		}
+		client.Transport = oneOff{rt}
+		return client, tlsCfg, nil
+	}
+
+	key := newPoolKey(s, hostport)
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+
+	if p := pool[key]; p != nil {
+		p.lastUsed = time.Now()
+		client.Transport = p.rt
+		return client, p.tlsCfg, nil
+	}
+
+	rt, tlsCfg, err := newTransport(s, hostport)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(pool) >= maxPooledTransports {
+		var oldest poolKey
+		var oldestUsed time.Time
+		for k, p := range pool {
+			if oldestUsed.IsZero() || p.lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = k, p.lastUsed
+			}
+		}
+		closeTransport(pool[oldest].rt)
+		delete(pool, oldest)
+	}
+
+	pool[key] = &pooledTransport{rt: rt, tlsCfg: tlsCfg, lastUsed: time.Now()}
+	client.Transport = rt
+
+	return client, tlsCfg, nil
+}
+
+// newTransport builds a transport for s with its TLS, proxy, protocol,
+// DNS and idle-pool settings.
+func newTransport(s Settings, hostport string) (http.RoundTripper, *tls.Config, error) {
+	tlsCfg, err := tlsConfig(s, hostport)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dns := effectiveDNS(s).resolved()
+
+	if s.Protocol == ProtoHTTP3 {
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		t := &http3.Transport{TLSClientConfig: tlsCfg, DisableCompression: true}
+		if !dns.IsZero() {
+			t.Dial = func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+				return dialEach(ctx, dns, addr, func(ctx context.Context, a string) (*quic.Conn, error) {
+					return quic.DialAddrEarly(ctx, a, tlsCfg, cfg)
+				})
+			}
+		}
+		return t, tlsCfg, nil
+	}
+
+	proxyFn, err := effectiveProxy(s).proxyFunc()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Clone keeps DefaultTransport's timeouts; a bare &http.Transport{}
+	// dropped them. Proxy is set explicitly, so SkipTLSVerify and client
+	// certificates no longer change which proxy is used.
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsCfg
+	t.Proxy = proxyFn
+	// SendRequest decodes bodies itself so it can report the wire size;
+	// the transport's transparent gzip hides both.
+	t.DisableCompression = true
+	if s.MaxIdlePerHost > 0 {
+		t.MaxIdleConnsPerHost = s.MaxIdlePerHost
+	}
+	if s.IdleTimeoutSec > 0 {
+		t.IdleConnTimeout = time.Duration(s.IdleTimeoutSec) * time.Second
+	}
+	if !dns.IsZero() {
+		// Through a proxy this dials the proxy, so overrides name the
+		// proxy host, not the target
+		dial := t.DialContext
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialEach(ctx, dns, addr, func(ctx context.Context, a string) (net.Conn, error) {
+				return dial(ctx, network, a)
+			})
+		}
+	}
+
+	// Plaintext connections are tapped for the wire log, and so is HTTP/1
+	// over TLS when the connection is direct and may be HTTP/1; the rest
+	// are logged as reconstructed
+	dial := t.DialContext
+	t.DialContext = tapDial(dial)
+	if proxy := effectiveProxy(s); (proxy.URL == "" || proxy.Direct) && s.Protocol != ProtoHTTP2 {
+		t.DialTLSContext = dialTLSTapped(t, dial)
+	}
+
+	switch s.Protocol {
+	case ProtoHTTP1:
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP1(true)
+	case ProtoHTTP2:
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP2(true)
+		t.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return t, tlsCfg, nil
+}
+
+// closeClient releases what newClient opened: a one-off transport is torn
+// down, pooled ones stay for the next send.
+func closeClient(client *http.Client) {
+	rt := client.Transport
+	if l, ok := rt.(*wireLog); ok {
+		l.detach()
+		rt = l.rt
+	}
+	if t, ok := rt.(oneOff); ok {
+		closeTransport(t.RoundTripper)
+	}
+}
+
+func closeTransport(rt http.RoundTripper) {
+	switch t := rt.(type) {
+	case io.Closer: // HTTP/3: its UDP socket
+		t.Close()
+	case interface{ CloseIdleConnections() }:
+		t.CloseIdleConnections()
+	}
+}
+
+// CloseIdleConnections drops every pooled transport, so the next sends dial
+// fresh connections and re-read certificate files.
+func CloseIdleConnections() {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+
+	for k, p := range pool {
+		closeTransport(p.rt)
+		delete(pool, k)
+	}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestSettingsProtocol(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	// Plain http server that also speaks h2c with prior knowledge
+	h2cServer := httptest.NewUnstartedServer(handler)
+	h2cServer.Config.Protocols = new(http.Protocols)
+	h2cServer.Config.Protocols.SetHTTP1(true)
+	h2cServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2cServer.Start()
+	defer h2cServer.Close()
+
+	send := func(url, proto string) *Response {
+		t.Helper()
+		r := testRequest(NewRequestID(), url)
+		r.Settings = Settings{SkipTLSVerify: true, Protocol: proto}
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatalf("%s over %s: %v", url, proto, err)
+		}
+		return res
+	}
+
+	tests := []struct {
+		url, proto, want string
+	}{
+		{tlsServer.URL, "", "HTTP/2.0"},
+		{tlsServer.URL, ProtoHTTP1, "HTTP/1.1"},
+		{tlsServer.URL, ProtoHTTP2, "HTTP/2.0"},
+		{h2cServer.URL, "", "HTTP/1.1"},
+		{h2cServer.URL, ProtoHTTP2, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		res := send(tt.url, tt.proto)
+		if res.Proto != tt.want || res.Body != tt.want {
+			t.Errorf("%s with %q: proto=%q server saw %q, want %q", tt.url, tt.proto, res.Proto, res.Body, tt.want)
+		}
+	}
+
+	if res := send(tlsServer.URL, ProtoHTTP1); res.TLS == nil || res.TLS.Version == "" || res.TLS.CipherSuite == "" {
+		t.Fatalf("TLS details missing: %+v", res.TLS)
+	}
+}
+
+func TestSettingsProtocolHTTP3(t *testing.T) {
+	// Borrow httptest's self-signed certificate for the QUIC listener
+	certSource := httptest.NewTLSServer(http.NotFoundHandler())
+	tlsCfg := http3.ConfigureTLSConfig(certSource.TLS.Clone())
+	certSource.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no UDP: %v", err)
+	}
+	server := &http3.Server{
+		TLSConfig: tlsCfg,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, r.Proto)
+		}),
+	}
+	go server.Serve(conn)
+	defer server.Close()
+
+	r := testRequest(NewRequestID(), "https://"+conn.LocalAddr().String()+"/")
+	r.Settings = Settings{SkipTLSVerify: true, Protocol: ProtoHTTP3}
+	defer DeleteHistory(r.ID)
+
+	res, err := r.SendRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Proto != "HTTP/3.0" || res.Body != "HTTP/3.0" {
+		t.Fatalf("proto=%q body=%q", res.Proto, res.Body)
+	}
+	if res.TLS == nil || res.TLS.Version != "TLS 1.3" {
+		t.Fatalf("TLS details: %+v", res.TLS)
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+	defer CloseIdleConnections()
+
+	send := func(s Settings) Timings {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL)
+		r.Settings = s
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Timings
+	}
+
+	pooled := Settings{SkipTLSVerify: true}
+	if first := send(pooled); first.Reused || first.TLS == 0 {
+		t.Fatalf("first send should dial and handshake: %+v", first)
+	}
+	if second := send(pooled); !second.Reused || second.TLS != 0 {
+		t.Fatalf("second send should reuse the connection: %+v", second)
+	}
+
+	// A different setup gets its own pool
+	if other := send(Settings{SkipTLSVerify: true, Protocol: ProtoHTTP1}); other.Reused {
+		t.Fatal("HTTP/1.1 send reused the negotiated pool's connection")
+	}
+
+	fresh := Settings{SkipTLSVerify: true, FreshConnection: true}
+	for range 2 {
+		if got := send(fresh); got.Reused || got.TLS == 0 {
+			t.Fatalf("fresh send reused a connection: %+v", got)
+		}
+	}
+
+	CloseIdleConnections()
+	if got := send(pooled); got.Reused {
+		t.Fatal("send after CloseIdleConnections reused a connection")
+	}
+}
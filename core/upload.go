@@ -0,0 +1,214 @@
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Request bodies are streamed: a multipart form is its framing bytes with
+// the files read from disk in between, so uploads of any size use a few KB
+// of memory and still get an exact Content-Length.
+
+// Files lists a file row's paths, one per line of Value.
+func (f FormType) Files() []string {
+	var paths []string
+	for p := range strings.SplitSeq(f.Value, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// bodySource is a body as segments, read afresh on every open so
+// redirects and retries can replay it.
+type bodySource struct {
+	segs   []segment
+	length int64
+}
+
+func (b *bodySource) open() io.ReadCloser {
+	return &segmentReader{segs: b.segs}
+}
+
+// streamed reports whether any part is read from disk as it's sent.
+func (b *bodySource) streamed() bool {
+	for _, seg := range b.segs {
+		if seg.path != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func stringBody(s string) *bodySource {
+	return &bodySource{segs: []segment{{data: []byte(s)}}, length: int64(len(s))}
+}
+
+// quoteEscaper matches mime/multipart's escaping of quoted names.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// BinaryContentType is a Binary body's Content-Type: the override, else a
+// guess from the file's extension.
+func BinaryContentType(path, override string) string {
+	if override != "" {
+		return override
+	}
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// fileBody streams the file at path as the whole body.
+func fileBody(path string) (*bodySource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	return &bodySource{segs: []segment{{path: path, size: info.Size()}}, length: info.Size()}, nil
+}
+
+// segment is a stretch of the body: literal bytes, or a file on disk.
+type segment struct {
+	data []byte
+	path string
+	size int64 // read no further than the stat'ed size, to match Content-Length
+}
+
+// multipartBody lays out the enabled Form rows. Files are stat'ed here so a
+// missing one fails before anything is sent, and their sizes give the
+// length; they're only opened as the reader reaches them.
+func multipartBody(rows []FormType) (*bodySource, string, error) {
+	var (
+		segs   []segment
+		length int64
+		frame  bytes.Buffer
+	)
+	writer := multipart.NewWriter(&frame)
+
+	// flush moves the framing written so far into its own segment.
+	flush := func() {
+		if frame.Len() > 0 {
+			segs = append(segs, segment{data: bytes.Clone(frame.Bytes())})
+			length += int64(frame.Len())
+			frame.Reset()
+		}
+	}
+
+	for _, v := range rows {
+		if !v.Checked {
+			continue
+		}
+		key, contentType := ApplyEnv(v.Key), ApplyEnv(v.ContentType)
+
+		if !v.IsFile {
+			if contentType == "" {
+				writer.WriteField(key, ApplyEnv(v.Value))
+				continue
+			}
+
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", `form-data; name="`+quoteEscaper.Replace(key)+`"`)
+			h.Set("Content-Type", contentType)
+			part, err := writer.CreatePart(h)
+			if err != nil {
+				return nil, "", err
+			}
+			io.WriteString(part, ApplyEnv(v.Value))
+			continue
+		}
+
+		for _, p := range v.Files() {
+			path := ApplyEnv(p)
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, "", err
+			}
+
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", multipart.FileContentDisposition(key, filepath.Base(path)))
+			h.Set("Content-Type", cmp.Or(contentType, "application/octet-stream"))
+			if _, err := writer.CreatePart(h); err != nil {
+				return nil, "", err
+			}
+
+			flush()
+			segs = append(segs, segment{path: path, size: info.Size()})
+			length += info.Size()
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	flush()
+
+	return &bodySource{segs: segs, length: length}, writer.FormDataContentType(), nil
+}
+
+// segmentReader reads the segments in order, holding at most one file open.
+// Close releases it when a send is cut short.
+type segmentReader struct {
+	segs []segment
+	cur  io.Reader
+	file *os.File
+}
+
+func (sr *segmentReader) Read(b []byte) (int, error) {
+	for {
+		if sr.cur == nil {
+			if len(sr.segs) == 0 {
+				return 0, io.EOF
+			}
+			seg := sr.segs[0]
+			sr.segs = sr.segs[1:]
+
+			if seg.path == "" {
+				sr.cur = bytes.NewReader(seg.data)
+			} else {
+				f, err := os.Open(seg.path)
+				if err != nil {
+					return 0, err
+				}
+				sr.file, sr.cur = f, io.LimitReader(f, seg.size)
+			}
+		}
+
+		n, err := sr.cur.Read(b)
+		if err == io.EOF {
+			sr.closeFile()
+			sr.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (sr *segmentReader) closeFile() {
+	if sr.file != nil {
+		sr.file.Close()
+		sr.file = nil
+	}
+}
+
+func (sr *segmentReader) Close() error {
+	sr.closeFile()
+	sr.segs, sr.cur = nil, nil
+	return nil
+}
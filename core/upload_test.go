@@ -0,0 +1,195 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// A streamed multipart body still carries an exact Content-Length, one key
+// can hold several files, and parts keep their own Content-Type.
+func TestSendRequestMultipartStream(t *testing.T) {
+	dir := t.TempDir()
+	big := bytes.Repeat([]byte("0123456789abcdef"), 64<<10) // 1 MB
+	a, b := filepath.Join(dir, "a.bin"), filepath.Join(dir, "b.json")
+	if err := os.WriteFile(a, big, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(`{"b":2}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type part struct{ name, file, contentType, body string }
+	var (
+		parts         []part
+		contentLength int64
+		chunked       bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		chunked = len(r.TransferEncoding) > 0
+		mr, err := r.MultipartReader()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			body, _ := io.ReadAll(p)
+			parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(body)})
+		}
+	}))
+	defer server.Close()
+
+	r := testRequest(NewRequestID(), server.URL)
+	r.Method = "POST"
+	r.BodyType = "Form"
+	r.Body = Body{Form: &[]FormType{
+		{Checked: true, Key: "meta", Value: `{"a":1}`, ContentType: "application/json"},
+		{Checked: true, Key: "files", Value: a + "\n" + b + "\n", IsFile: true},
+	}}
+	defer DeleteHistory(r.ID)
+
+	var last Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		if p.Upload {
+			last = p
+		}
+	})
+	if _, err := r.SendRequest(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []part{
+		{"meta", "", "application/json", `{"a":1}`},
+		{"files", "a.bin", "application/octet-stream", string(big)},
+		{"files", "b.json", "application/octet-stream", `{"b":2}`},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("part %d: got %q %q %q (%d bytes), want %q %q %q", i,
+				parts[i].name, parts[i].file, parts[i].contentType, len(parts[i].body),
+				want[i].name, want[i].file, want[i].contentType)
+		}
+	}
+
+	if chunked || contentLength <= int64(len(big)) {
+		t.Fatalf("content-length=%d chunked=%v, want an exact length", contentLength, chunked)
+	}
+	if last.Done != contentLength || last.Total != contentLength {
+		t.Fatalf("upload progress %+v, want %d of %d", last, contentLength, contentLength)
+	}
+}
+
+func TestSendRequestBinaryBody(t *testing.T) {
+	dir := t.TempDir()
+	blob := bytes.Repeat([]byte{0, 1, 2, 0xff}, 4096)
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), blob, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotType string
+	var gotBody []byte
+	var gotLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType, gotLength = r.Header.Get("Content-Type"), r.ContentLength
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	SetActiveVars(map[string]string{"dir": dir})
+	defer SetActiveVars(nil)
+
+	send := func(override string, headers ...FormType) {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL)
+		r.Method = "PUT"
+		r.Headers = &headers
+		r.BodyType = "Binary"
+		r.Body = Body{Binary: "{{dir}}/logo.png", BinaryType: override}
+		defer DeleteHistory(r.ID)
+		if _, err := r.SendRequest(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send("")
+	if gotType != "image/png" || gotLength != int64(len(blob)) || !bytes.Equal(gotBody, blob) {
+		t.Fatalf("type=%q length=%d body=%d bytes", gotType, gotLength, len(gotBody))
+	}
+
+	send("application/x-custom")
+	if gotType != "application/x-custom" {
+		t.Fatalf("override ignored: %q", gotType)
+	}
+
+	send("", FormType{Checked: true, Key: "Content-Type", Value: "application/vnd.blob"})
+	if gotType != "application/vnd.blob" {
+		t.Fatalf("header ignored: %q", gotType)
+	}
+
+	r := testRequest(NewRequestID(), server.URL)
+	r.BodyType = "Binary"
+	r.Body = Body{Binary: filepath.Join(dir, "gone.bin")}
+	if _, err := r.SendRequest(context.Background()); err == nil {
+		t.Fatal("missing file should error")
+	}
+}
+
+// A file upload may take longer than the timeout, which only starts once
+// the body is written.
+func TestUploadTimeout(t *testing.T) {
+	var got int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 64<<10)
+		for {
+			n, err := r.Body.Read(buf)
+			got += int64(n)
+			if err != nil {
+				break
+			}
+			time.Sleep(4 * time.Millisecond) // ~3s for the file
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "big.bin")
+	// Sparse and larger than socket buffers can absorb, so the client is
+	// still writing well past the timeout
+	if err := os.WriteFile(path, nil, 0o600); err != nil || os.Truncate(path, 48<<20) != nil {
+		t.Fatal(err)
+	}
+
+	for _, bodyType := range []string{"Binary", "Form"} {
+		got = 0
+		r := testRequest(NewRequestID(), server.URL)
+		r.Method = "POST"
+		r.BodyType = bodyType
+		r.Body = Body{Binary: path, Form: &[]FormType{{Checked: true, Key: "f", Value: path, IsFile: true}}}
+		r.Settings.TimeoutSec = 2
+
+		if _, err := r.SendRequest(context.Background()); err != nil {
+			t.Fatalf("%s: %v", bodyType, err)
+		}
+		DeleteHistory(r.ID)
+		if got < 48<<20 {
+			t.Fatalf("%s: server read %d bytes", bodyType, got)
+		}
+	}
+}
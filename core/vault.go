@@ -0,0 +1,374 @@
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+)
+
+// The vault holds secret values — variables and auth credentials marked
+// secret — encrypted at rest in vault.json. The rest of the app's files
+// store those fields blank and refill them from here on load.
+//
+// Two ways to get the key: "keyring" (default) uses a random key kept by
+// the OS secret service, and "password" derives it from a master password
+// that must be entered each launch.
+// ponytail: the keyring is a local stand-in — a random key in a 0600 file
+// next to the vault — until a real Secret Service/Keychain/Credential
+// Manager binding is worth the dependency.
+
+const (
+	VaultKeyring  = "keyring"
+	VaultPassword = "password"
+
+	vaultKDFIterations = 600_000
+)
+
+// ErrVaultLocked is returned when a secret must be read or written while
+// the master password hasn't been entered.
+var ErrVaultLocked = errors.New("vault is locked: enter the master password")
+
+type vaultFile struct {
+	Mode string `json:"Mode"`
+	Salt []byte `json:"Salt,omitempty"` // password mode only
+	Data []byte `json:"Data,omitempty"` // nonce || AES-GCM(JSON map)
+}
+
+var (
+	vaultMu      sync.Mutex
+	vaultOpened  bool
+	vaultMode    string
+	vaultSalt    []byte
+	vaultKey     []byte            // nil while locked
+	vaultSecrets map[string]string // nil while locked
+	vaultErr     error             // why the last open left a keyring vault locked
+)
+
+// OpenVault loads the vault from disk. Keyring vaults unlock right away;
+// password vaults stay locked until UnlockVault. Safe to call again, e.g.
+// after the config dir changed. An error leaves the vault locked; see
+// VaultError.
+func OpenVault() error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+
+	return openVault()
+}
+
+// openVault is OpenVault with vaultMu held.
+func openVault() error {
+	vaultOpened = true
+	vaultMode, vaultSalt, vaultKey, vaultSecrets = VaultKeyring, nil, nil, nil
+
+	vaultErr = openKeyring()
+	return vaultErr
+}
+
+// openKeyring reads the vault and, unless it wants a master password,
+// unlocks it with the keyring key.
+func openKeyring() error {
+	vf, err := readVaultFile()
+	if err != nil {
+		return err
+	}
+	if vf.Mode == VaultPassword {
+		vaultMode, vaultSalt = VaultPassword, vf.Salt
+		return nil
+	}
+
+	key, err := keyringKey(len(vf.Data) == 0)
+	if err != nil {
+		return err
+	}
+
+	if err := unlockWith(key, vf.Data); err != nil {
+		return fmt.Errorf("vault can't be decrypted with the keyring key: %w", err)
+	}
+	return nil
+}
+
+// ensureVault lazily opens the vault for callers that didn't. vaultMu must
+// be held.
+func ensureVault() {
+	if !vaultOpened {
+		openVault()
+	}
+}
+
+// UnlockVault derives the key from password and decrypts the vault. A
+// keyring vault takes no password; it is reopened instead, e.g. after its
+// key file was restored, and the reason it is still locked returned.
+func UnlockVault(password string) error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	if vaultSecrets != nil {
+		return nil
+	}
+	if vaultMode != VaultPassword {
+		return openVault()
+	}
+
+	vf, err := readVaultFile()
+	if err != nil {
+		return err
+	}
+
+	key := passwordKey(password, vaultSalt)
+	if err := unlockWith(key, vf.Data); err != nil {
+		return errors.New("wrong master password")
+	}
+
+	return nil
+}
+
+// VaultLocked reports whether secrets are currently unavailable.
+func VaultLocked() bool {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	return vaultSecrets == nil
+}
+
+// VaultError is why a keyring vault couldn't be opened, or nil.
+func VaultError() error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	return vaultErr
+}
+
+// VaultMode is VaultKeyring or VaultPassword.
+func VaultMode() string {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	return vaultMode
+}
+
+// SetVaultPassword re-encrypts the vault under a master password, or back
+// under the keyring key when password is empty. The vault must be unlocked.
+func SetVaultPassword(password string) error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	if vaultSecrets == nil {
+		return ErrVaultLocked
+	}
+
+	if password == "" {
+		key, err := keyringKey(true) // everything is re-encrypted below
+		if err != nil {
+			return err
+		}
+		vaultMode, vaultSalt, vaultKey = VaultKeyring, nil, key
+	} else {
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		vaultMode, vaultSalt, vaultKey = VaultPassword, salt, passwordKey(password, salt)
+	}
+
+	return writeVaultFile()
+}
+
+func vaultGet(key string) (string, bool) {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	v, ok := vaultSecrets[key]
+	return v, ok
+}
+
+// vaultSet stores (or, with an empty value, deletes) a secret and persists.
+// A locked vault only refuses when there's something to store.
+func vaultSet(key, value string) error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	return replaceSecrets(func(next map[string]string) {
+		if value == "" {
+			delete(next, key)
+		} else {
+			next[key] = value
+		}
+	}, value != "")
+}
+
+// vaultReplace swaps every secret under prefix for values; empty values
+// are dropped.
+func vaultReplace(prefix string, values map[string]string) error {
+	vaultMu.Lock()
+	defer vaultMu.Unlock()
+	ensureVault()
+
+	storing := false
+	for _, v := range values {
+		storing = storing || v != ""
+	}
+
+	return replaceSecrets(func(next map[string]string) {
+		maps.DeleteFunc(next, func(k, _ string) bool {
+			return strings.HasPrefix(k, prefix)
+		})
+		for k, v := range values {
+			if v != "" {
+				next[k] = v
+			}
+		}
+	}, storing)
+}
+
+// replaceSecrets applies edit to a copy of the secrets and persists only
+// if something changed. vaultMu must be held.
+func replaceSecrets(edit func(map[string]string), storing bool) error {
+	if vaultSecrets == nil {
+		if storing {
+			return ErrVaultLocked
+		}
+		return nil
+	}
+
+	next := maps.Clone(vaultSecrets)
+	edit(next)
+	if maps.Equal(next, vaultSecrets) {
+		return nil
+	}
+
+	vaultSecrets = next
+	return writeVaultFile()
+}
+
+// unlockWith decrypts data with key into the in-memory secrets. Empty data
+// is a fresh vault.
+func unlockWith(key, data []byte) error {
+	secrets := map[string]string{}
+
+	if len(data) > 0 {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return err
+		}
+		n := gcm.NonceSize()
+		if len(data) < n {
+			return errors.New("vault file is corrupt")
+		}
+		plain, err := gcm.Open(nil, data[:n], data[n:], nil)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(plain, &secrets); err != nil {
+			return err
+		}
+	}
+
+	vaultKey, vaultSecrets = key, secrets
+	return nil
+}
+
+func writeVaultFile() error {
+	plain, err := json.Marshal(vaultSecrets)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(vaultKey)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+
+	data, err := json.Marshal(vaultFile{
+		Mode: vaultMode,
+		Salt: vaultSalt,
+		Data: gcm.Seal(nonce, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := configFile("vault.json")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0o600)
+}
+
+func readVaultFile() (vaultFile, error) {
+	vf := vaultFile{Mode: VaultKeyring}
+
+	file, err := configFile("vault.json")
+	if err != nil {
+		return vf, err
+	}
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return vf, nil
+	}
+	if err != nil {
+		return vf, err
+	}
+
+	err = json.Unmarshal(content, &vf)
+	return vf, err
+}
+
+// keyringKey fetches the keyring-held vault key. A missing or damaged key
+// is only replaced when nothing is encrypted under it yet (fresh); otherwise
+// the next save would re-key the vault and lose every secret in it.
+func keyringKey(fresh bool) ([]byte, error) {
+	file, err := configFile("keyring.key")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(file)
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if !fresh {
+		if err == nil {
+			err = fmt.Errorf("%d bytes, want 32", len(key))
+		}
+		return nil, fmt.Errorf("vault key %s is unusable, so saved secrets can't be decrypted: %w", file, err)
+	}
+
+	key = make([]byte, 32)
+	rand.Read(key)
+	if err := os.WriteFile(file, key, 0o600); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func passwordKey(password string, salt []byte) []byte {
+	key, _ := pbkdf2.Key(sha256.New, password, salt, vaultKDFIterations, 32)
+	return key
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
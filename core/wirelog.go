@@ -0,0 +1,486 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxLoggedBody caps each request and response body a wire log keeps;
+// maxLoggedHead is the extra room a captured stream gets for its head.
+const (
+	maxLoggedBody = 64 << 10
+	maxLoggedHead = 16 << 10
+)
+
+// wireLog is the dumping transport: it records each round trip of a send —
+// every redirect and retry adds one — as the request went out and the
+// response came back.
+//
+// HTTP/1.x over a connection the transport tapped (see tapConn) is logged
+// as the exact bytes both ways, bodies as transferred up to maxLoggedBody.
+// Anything else is reconstructed from the parsed exchange and says so:
+// HTTP/2 and HTTP/3 frames have no text form, and HTTPS through a proxy
+// or with a forced protocol isn't tapped. A reconstructed request keeps
+// the fields in the order the transport wrote them; its response's
+// headers come out sorted by name.
+type wireLog struct {
+	rt        http.RoundTripper
+	mu        sync.Mutex
+	exchanges []*exchange
+}
+
+type exchange struct {
+	req      *http.Request
+	reqBody  loggedBody
+	resp     *http.Response
+	respBody loggedBody
+	err      error
+
+	mu     sync.Mutex
+	tap    *tapConn // the connection, when tapped
+	wire   capture
+	fields []HeaderField // as the transport wrote them
+}
+
+// capture is a tapped connection's traffic during one round trip.
+type capture struct {
+	sent, recv loggedBody
+}
+
+// loggedBody is a body's head and its full length. The transport may
+// write a request body from its own goroutine, hence the lock.
+type loggedBody struct {
+	mu   sync.Mutex
+	head headBuffer
+	n    int64
+}
+
+func (b *loggedBody) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.n += int64(len(p))
+	return b.head.Write(p)
+}
+
+// bytes is a copy of what was kept.
+func (b *loggedBody) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.head.buf)
+}
+
+// teeBody is a body whose reads are copied into a loggedBody.
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+func (l *wireLog) RoundTrip(req *http.Request) (*http.Response, error) {
+	x := &exchange{req: req}
+	x.reqBody.head.max, x.respBody.head.max = maxLoggedBody, maxLoggedBody
+	x.wire.sent.head.max, x.wire.recv.head.max = maxLoggedBody+maxLoggedHead, maxLoggedBody+maxLoggedHead
+
+	// A copy: RoundTrip mustn't change the caller's
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if c, ok := info.Conn.(*tapConn); ok {
+				c.attach(&x.wire)
+				x.mu.Lock()
+				x.tap = c
+				x.mu.Unlock()
+			}
+		},
+		WroteHeaderField: func(key string, values []string) {
+			x.mu.Lock()
+			defer x.mu.Unlock()
+			for _, v := range values {
+				x.fields = append(x.fields, HeaderField{key, v})
+			}
+		},
+	}))
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = teeBody{io.TeeReader(req.Body, &x.reqBody), req.Body}
+	}
+
+	resp, err := l.rt.RoundTrip(req)
+	x.resp, x.err = resp, err
+	if resp != nil && resp.Body != nil {
+		resp.Body = teeBody{io.TeeReader(resp.Body, &x.respBody), resp.Body}
+	}
+	// The TLS dialer's HTTP/1 connections hide their state from the
+	// transport; see dialTLSTapped.
+	if tap := x.tapped(); resp != nil && resp.TLS == nil && tap != nil && tap.tls != nil {
+		state := tap.tls.ConnectionState()
+		resp.TLS = &state
+	}
+
+	l.mu.Lock()
+	l.exchanges = append(l.exchanges, x)
+	l.mu.Unlock()
+
+	return resp, err
+}
+
+func (x *exchange) tapped() *tapConn {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.tap
+}
+
+// exact reports whether the round trip's bytes were captured as text.
+func (x *exchange) exact() bool {
+	return x.tapped() != nil && (x.resp == nil || x.resp.ProtoMajor == 1)
+}
+
+// detach stops the tapped connections feeding this log, before the pool
+// hands them to another send.
+func (l *wireLog) detach() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, x := range l.exchanges {
+		if tap := x.tapped(); tap != nil {
+			tap.detach(&x.wire)
+		}
+	}
+}
+
+// header is resp's header fields in the order received when its round trip
+// was captured, else sorted by name; sorted reports which.
+func (l *wireLog) header(resp *http.Response) (h Header, sorted bool) {
+	l.mu.Lock()
+	var x *exchange
+	for _, e := range l.exchanges {
+		if e.resp == resp {
+			x = e
+		}
+	}
+	l.mu.Unlock()
+
+	if x == nil || !x.exact() {
+		return newHeader(resp.Header), true
+	}
+	names := headNames(x.wire.recv.bytes(), resp.StatusCode)
+	if names == nil {
+		return newHeader(resp.Header), true
+	}
+	return orderedHeader(resp.Header, names), false
+}
+
+// headNames lists the field names of the response head for code in a
+// captured stream, one per line; interim 1xx heads before it are skipped.
+// Nil when the head wasn't captured whole.
+func headNames(stream []byte, code int) []string {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(stream)))
+	for {
+		status, err := r.ReadLine()
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for {
+			line, err := r.ReadLine()
+			if err != nil {
+				return nil
+			}
+			if line == "" {
+				break
+			}
+			if name, _, ok := strings.Cut(line, ":"); ok {
+				names = append(names, textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name)))
+			}
+		}
+
+		_, rest, _ := strings.Cut(status, " ")
+		got, _ := strconv.Atoi(strings.TrimSpace(rest[:min(3, len(rest))]))
+		if got == code || got >= 200 {
+			return names
+		}
+	}
+}
+
+// String renders the round trips so far: each request, a blank line, then
+// its response or error. Trailers show once the body was read to the end.
+func (l *wireLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b strings.Builder
+	for i, x := range l.exchanges {
+		if len(l.exchanges) > 1 {
+			fmt.Fprintf(&b, "## Round trip %d of %d\n\n", i+1, len(l.exchanges))
+		}
+
+		if x.exact() {
+			writeWire(&b, x.wire.sent.bytes(), x.wire.sent.n)
+			switch {
+			case x.err != nil:
+				fmt.Fprintf(&b, "\n! %v\n", x.err)
+			default:
+				b.WriteString("\n")
+				writeWire(&b, x.wire.recv.bytes(), x.wire.recv.n)
+			}
+			b.WriteString("\n")
+			continue
+		}
+
+		proto := "HTTP/1.1"
+		if x.resp != nil {
+			proto = x.resp.Proto
+		}
+		if x.resp != nil && x.resp.ProtoMajor > 1 {
+			fmt.Fprintf(&b, "(reconstructed: %s frames have no text form; response headers sorted by name)\n\n", proto)
+		} else {
+			b.WriteString("(reconstructed: this connection wasn't captured; response headers sorted by name)\n\n")
+		}
+
+		x.mu.Lock()
+		fields := x.fields
+		x.mu.Unlock()
+		if fields != nil {
+			fmt.Fprintf(&b, "%s %s %s\n", x.req.Method, x.req.URL.RequestURI(), proto)
+			for _, f := range fields {
+				fmt.Fprintf(&b, "%s: %s\n", f.Name, f.Value)
+			}
+			b.WriteString("\n")
+		} else {
+			writeRequestHead(&b, x.req, proto, x.req.ContentLength)
+		}
+		x.reqBody.writeTo(&b)
+
+		switch {
+		case x.err != nil:
+			fmt.Fprintf(&b, "\n! %v\n", x.err)
+		case x.resp != nil:
+			b.WriteString("\n")
+			fmt.Fprintf(&b, "%s %s\n", x.resp.Proto, x.resp.Status)
+			writeHeader(&b, x.resp.Header)
+			x.respBody.writeTo(&b)
+			if len(x.resp.Trailer) > 0 {
+				b.WriteString("\n")
+				writeHeader(&b, x.resp.Trailer)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeWire writes a captured stream of n bytes: its head verbatim, CRLFs
+// and all, then the rest like a logged body.
+func writeWire(b *strings.Builder, stream []byte, n int64) {
+	end := bytes.Index(stream, []byte("\r\n\r\n"))
+	if end < 0 {
+		writeBody(b, stream, n)
+		return
+	}
+	end += 4
+	b.Write(stream[:end])
+	writeBody(b, stream[end:], n-int64(end))
+}
+
+// writeRequestHead writes req's request line and headers as the transport
+// sends them: Host, Go's User-Agent unless set, the length, then the rest
+// sorted, ending with the blank line. For transports that don't report
+// the fields they wrote (HTTP/3).
+func writeRequestHead(b *strings.Builder, req *http.Request, proto string, length int64) {
+	h := req.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	if h.Get("User-Agent") == "" {
+		h.Set("User-Agent", "Go-http-client/"+strings.TrimPrefix(cmp.Or(proto, "HTTP/1.1"), "HTTP/"))
+	}
+	switch {
+	case length > 0:
+		h.Set("Content-Length", fmt.Sprint(length))
+	case length < 0:
+		h.Set("Transfer-Encoding", "chunked")
+	}
+
+	fmt.Fprintf(b, "%s %s %s\n", req.Method, req.URL.RequestURI(), proto)
+	fmt.Fprintf(b, "Host: %s\n", cmp.Or(req.Host, req.URL.Host))
+	writeHeader(b, h)
+	b.WriteString("\n")
+}
+
+// writeHeader writes h's fields sorted by name, each value on its own
+// line in the order received.
+func writeHeader(b *strings.Builder, h http.Header) {
+	for _, f := range newHeader(h) {
+		fmt.Fprintf(b, "%s: %s\n", f.Name, f.Value)
+	}
+}
+
+// writeTo writes the body's head — as text when it is, else a hex dump —
+// then how much was cut.
+func (lb *loggedBody) writeTo(b *strings.Builder) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	writeBody(b, lb.head.buf, lb.n)
+}
+
+// writeBody writes head, the start of an n-byte body; see writeTo.
+func writeBody(b *strings.Builder, head []byte, n int64) {
+	if n := textPrefix(head); n >= 0 {
+		head = head[:n]
+		if n > 0 {
+			b.Write(head)
+			b.WriteString("\n")
+		}
+	} else {
+		b.WriteString(hex.Dump(head))
+	}
+	if more := n - int64(len(head)); more > 0 {
+		fmt.Fprintf(b, "… [%d more bytes]\n", more)
+	}
+}
+
+// textPrefix is how much of head is UTF-8 text, allowing for a rune the
+// cap cut in two; -1 when head isn't text.
+func textPrefix(head []byte) int {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return -1
+	}
+	for n := len(head); n >= max(len(head)-utf8.UTFMax+1, 0); n-- {
+		if utf8.Valid(head[:n]) {
+			return n
+		}
+	}
+	return -1
+}
+
+// tapConn copies a pooled connection's plaintext traffic into the capture
+// of the round trip using it; a wire log attaches each in turn on GotConn.
+type tapConn struct {
+	net.Conn
+	tls *tls.Conn // the TLS dialer's: Conn itself, whose state the transport can't see
+
+	mu   sync.Mutex
+	sink *capture
+}
+
+func (c *tapConn) attach(sink *capture) {
+	c.mu.Lock()
+	c.sink = sink
+	c.mu.Unlock()
+}
+
+// detach stops feeding sink, unless another round trip took over since.
+func (c *tapConn) detach(sink *capture) {
+	c.mu.Lock()
+	if c.sink == sink {
+		c.sink = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *tapConn) current() *capture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sink
+}
+
+func (c *tapConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if sink := c.current(); sink != nil && n > 0 {
+		sink.recv.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *tapConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if sink := c.current(); sink != nil && n > 0 {
+		sink.sent.Write(p[:n])
+	}
+	return n, err
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// tapDial wraps dial's connections in a tapConn. Only the ones the
+// transport speaks HTTP over directly are ever attached: under TLS it's
+// the tls.Conn that the wire log sees.
+func tapDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &tapConn{Conn: conn}, nil
+	}
+}
+
+// dialTLSTapped is a Transport.DialTLSContext that taps HTTP/1 over TLS.
+// It handshakes itself, with the transport's (ALPN-bearing) config, and
+// returns the bare tls.Conn when h2 was negotiated: the HTTP/2 transport
+// needs one. An HTTP/1 connection comes back tapped and without
+// ConnectionState, so the transport doesn't trace a second, empty
+// handshake; wireLog puts its state back on the response.
+func dialTLSTapped(t *http.Transport, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		raw, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			cfg = t.TLSClientConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				host = addr
+			}
+			cfg.ServerName = host
+		}
+
+		if t.TLSHandshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.TLSHandshakeTimeout)
+			defer cancel()
+		}
+
+		trace := httptrace.ContextClientTrace(ctx)
+		if trace != nil && trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+		conn := tls.Client(raw, cfg)
+		err = conn.HandshakeContext(ctx)
+		state := conn.ConnectionState()
+		if err != nil {
+			raw.Close()
+			if trace != nil && trace.TLSHandshakeDone != nil {
+				trace.TLSHandshakeDone(tls.ConnectionState{}, err)
+			}
+			return nil, err
+		}
+
+		// The transport traces the (finished) handshake of a bare tls.Conn
+		// again; the trace keeps the start recorded above.
+		if state.NegotiatedProtocol == "h2" {
+			return conn, nil
+		}
+		if trace != nil && trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(state, nil)
+		}
+		return &tapConn{Conn: conn, tls: conn}, nil
+	}
+}
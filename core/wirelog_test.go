@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The raw log holds every round trip of a send: the redirect and the final
+// exchange, repeated headers in order, the bodies both ways and trailers.
+// The response keeps the repeats and the trailers too.
+func TestWireLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Add("X-Multi", "b")
+		w.Header().Add("X-Multi", "a")
+		io.WriteString(w, "done")
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer server.Close()
+
+	req := testRequest("wirelog", server.URL+"/start")
+	req.Method = "POST"
+	req.BodyType = "Text"
+	req.Body = Body{Text: "hello"}
+	defer DeleteHistory(req.ID)
+
+	res, err := req.SendRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plain HTTP/1.1 is captured off the connection: CRLFs, the order
+	// fields were written in and the chunked framing, trailer included
+	log := res.RawLog
+	for _, want := range []string{
+		"## Round trip 1 of 2\n\nPOST /start HTTP/1.1\r\nHost: ",
+		"User-Agent: Go-http-client/1.1\r\nContent-Length: 5\r\n",
+		"Content-Type: text/plain\r\n\r\nhello\n\nHTTP/1.1 307 Temporary Redirect\r\n",
+		"Location: /final\r\n",
+		"## Round trip 2 of 2\n\nPOST /final HTTP/1.1\r\n",
+		"HTTP/1.1 200 OK\r\n",
+		"X-Multi: b\r\nX-Multi: a\r\n",
+		"\r\n\r\n4\r\ndone\r\n0\r\nX-Checksum: abc\r\n",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log lacks %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "reconstructed") {
+		t.Errorf("plain HTTP/1.1 logged as reconstructed:\n%s", log)
+	}
+	if got := res.Headers.Values("X-Multi"); !slices.Equal(got, []string{"b", "a"}) {
+		t.Errorf("X-Multi = %q", got)
+	}
+	if got := res.Trailers.Get("X-Checksum"); got != "abc" {
+		t.Errorf("trailer = %q", got)
+	}
+	if strings.Count(log, "hello") != 2 {
+		t.Errorf("request body not logged per round trip:\n%s", log)
+	}
+}
+
+func TestLoggedBody(t *testing.T) {
+	var b strings.Builder
+	lb := &loggedBody{head: headBuffer{max: 9}}
+	io.WriteString(lb, "héllo wörld") // ö cut by the cap
+	lb.writeTo(&b)
+	if got := b.String(); got != "héllo w\n… [5 more bytes]\n" {
+		t.Fatalf("text: %q", got)
+	}
+
+	b.Reset()
+	bin := &loggedBody{head: headBuffer{max: 64}}
+	bin.Write([]byte{0x1f, 0x8b, 0, 1})
+	bin.writeTo(&b)
+	if !strings.HasPrefix(b.String(), "00000000  1f 8b 00 01") {
+		t.Fatalf("binary: %q", b.String())
+	}
+}
+
+// HTTPS is captured too while it's HTTP/1; HTTP/2 is labelled as rebuilt
+// from the parsed exchange, with the request fields as written.
+func TestWireLogTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	defer CloseIdleConnections()
+
+	send := func(proto string) *Response {
+		t.Helper()
+		r := testRequest(NewRequestID(), server.URL+"/tls")
+		r.Settings = Settings{SkipTLSVerify: true, Protocol: proto}
+		defer DeleteHistory(r.ID)
+		res, err := r.SendRequest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := send(ProtoHTTP1)
+	if !strings.HasPrefix(res.RawLog, "GET /tls HTTP/1.1\r\nHost: ") || !strings.Contains(res.RawLog, "\r\n\r\nok\n") {
+		t.Errorf("HTTP/1.1 over TLS not captured:\n%s", res.RawLog)
+	}
+	if res.Sorted {
+		t.Error("HTTP/1.1 over TLS headers sorted, want them in the order received")
+	}
+	if res.TLS == nil || res.TLS.Version == "" || res.Timings.TLS == 0 {
+		t.Errorf("tapped TLS connection lost its details: tls=%+v timings=%+v", res.TLS, res.Timings)
+	}
+
+	res = send("")
+	if res.Proto != "HTTP/2.0" || !strings.HasPrefix(res.RawLog, "(reconstructed: HTTP/2.0 frames have no text form") {
+		t.Errorf("HTTP/2 log:\n%s", res.RawLog)
+	}
+	if !res.Sorted {
+		t.Error("HTTP/2 headers not marked sorted")
+	}
+	if !strings.Contains(res.RawLog, ":path: /tls\n") || res.Timings.TLS == 0 {
+		t.Errorf("HTTP/2 request fields or TLS timing missing: %+v\n%s", res.Timings, res.RawLog)
+	}
+}
+
+// Headers come back in the order the server wrote them, across names and
+// past an interim 1xx head, not sorted the way Go's map would give them.
+func TestHeaderOrder(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 4096)
+		conn.Read(buf)
+		io.WriteString(conn, "HTTP/1.1 103 Early Hints\r\nLink: </a.css>\r\n\r\n"+
+			"HTTP/1.1 200 OK\r\nX-B: 1\r\nX-A: 2\r\nx-b: 3\r\nContent-Length: 2\r\n\r\nok")
+	}()
+	defer CloseIdleConnections()
+
+	r := testRequest(NewRequestID(), "http://"+ln.Addr().String()+"/")
+	defer DeleteHistory(r.ID)
+	res, err := r.SendRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Header{{"X-B", "1"}, {"X-A", "2"}, {"X-B", "3"}, {"Content-Length", "2"}}
+	if res.Sorted || !slices.Equal(res.Headers, want) {
+		t.Errorf("headers = %v (sorted %v), want %v", res.Headers, res.Sorted, want)
+	}
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// pathEntry is an entry plus a file-picker button; typing and picking both
+// go through set, so {{var}} paths stay possible.
+func (g *gui) pathEntry(label, value, placeholder string, set func(string)) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.SetText(value)
+	entry.OnChanged = set
+
+	pick := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close() // only the path is needed; SendRequest reads it fresh
+			entry.SetText(rc.URI().Path())
+		}, *g.Window)
+	})
+	pick.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, widget.NewLabel(label), pick, entry)
+}
+
+// certForm edits a CertConfig in place. onChange runs after every edit.
+func (g *gui) certForm(cc *core.CertConfig, onChange func()) fyne.CanvasObject {
+	passphrase := widget.NewPasswordEntry()
+	passphrase.SetPlaceHolder("PKCS#12 or encrypted key only")
+	passphrase.SetText(cc.Passphrase)
+	passphrase.OnChanged = func(s string) {
+		cc.Passphrase = s
+		onChange()
+	}
+
+	return container.NewVBox(
+		g.pathEntry("Certificate", cc.Certificate(), "PEM, .p12 or .pfx", func(s string) {
+			cc.SetCertificate(s)
+			onChange()
+		}),
+		g.pathEntry("Key", cc.KeyFile, "PEM key; empty if in the certificate file", func(s string) {
+			cc.KeyFile = s
+			onChange()
+		}),
+		container.NewBorder(nil, nil, widget.NewLabel("Passphrase"), nil, passphrase),
+		g.pathEntry("CA bundle", cc.CAFile, "Extra trusted roots, PEM", func(s string) {
+			cc.CAFile = s
+			onChange()
+		}),
+	)
+}
+
+// hostCertsDialog manages the per-host certificate store. Edits write
+// straight into the store; closing persists and re-publishes it to core.
+func (g *gui) hostCertsDialog() {
+	var list *widget.List
+	list = widget.NewList(
+		func() int {
+			return len(g.certStore.Hosts)
+		},
+		func() fyne.CanvasObject {
+			host := widget.NewLabel("host")
+			host.Truncation = fyne.TextTruncateEllipsis
+			edit := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			edit.Importance = widget.LowImportance
+			del := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			del.Importance = widget.LowImportance
+
+			return container.NewBorder(nil, nil, nil, container.NewHBox(edit, del), host)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			row := o.(*fyne.Container)
+			cc := g.certStore.Hosts[i]
+
+			label := cc.Host
+			if label == "" {
+				label = "(no host)"
+			}
+			row.Objects[0].(*widget.Label).SetText(label)
+
+			btns := row.Objects[1].(*fyne.Container)
+			btns.Objects[0].(*widget.Button).OnTapped = func() {
+				g.editHostCertDialog(cc, list.Refresh)
+			}
+			btns.Objects[1].(*widget.Button).OnTapped = func() {
+				for j, c := range g.certStore.Hosts {
+					if c == cc {
+						g.certStore.Hosts = append(g.certStore.Hosts[:j], g.certStore.Hosts[j+1:]...)
+						break
+					}
+				}
+				list.Refresh()
+			}
+		},
+	)
+
+	addBtn := widget.NewButtonWithIcon("Add Host", theme.ContentAddIcon(), func() {
+		cc := &core.CertConfig{}
+		g.certStore.Hosts = append(g.certStore.Hosts, cc)
+		list.Refresh()
+		g.editHostCertDialog(cc, list.Refresh)
+	})
+
+	hint := widget.NewLabel("Used when a request has no certificate of its own. First match wins.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(hint, container.NewBorder(nil, nil, addBtn, nil), nil, nil, list)
+
+	d := dialog.NewCustom("Host Certificates", "Done", content, *g.Window)
+	d.SetOnClosed(func() {
+		core.SetHostCerts(g.certStore.Hosts)
+
+		if err := core.SaveCertStore(g.certStore); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+	})
+	d.Resize(fyne.NewSize(520, 380))
+	d.Show()
+}
+
+func (g *gui) editHostCertDialog(cc *core.CertConfig, onDone func()) {
+	host := widget.NewEntry()
+	host.SetPlaceHolder("api.internal, *.internal or host:8443")
+	host.SetText(cc.Host)
+	host.OnChanged = func(s string) {
+		cc.Host = s
+	}
+
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Host"), nil, host),
+		g.certForm(cc, func() {}),
+	)
+
+	d := dialog.NewCustom("Host Certificate", "Done", content, *g.Window)
+	d.SetOnClosed(onDone)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
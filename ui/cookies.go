@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// cookieExpiresLayout is how the cookie editor shows and parses Expires.
+const cookieExpiresLayout = "2006-01-02 15:04:05"
+
+// applyCookieJar publishes the active environment's jar to core, or none
+// when the jar is switched off.
+func (g *gui) applyCookieJar() {
+	if !g.cookieStore.Enabled {
+		core.SetCookieJar(nil)
+		return
+	}
+
+	core.SetCookieJar(g.cookieStore.Jar(g.envStore.Active))
+}
+
+// saveCookies persists the jars so cookies survive a restart. Sends call
+// it after every response, through fyne.Do: the store's map belongs to the
+// UI thread, which also keeps two sends from writing the file at once.
+func (g *gui) saveCookies() error {
+	if !g.cookieStore.Enabled {
+		return nil
+	}
+
+	return core.SaveCookieStore(g.cookieStore)
+}
+
+// cookieExpiry phrases a cookie's lifetime for the manager list.
+func cookieExpiry(c core.Cookie) string {
+	if c.Expires.IsZero() {
+		return "Session"
+	}
+	return c.Expires.Local().Format(cookieExpiresLayout)
+}
+
+// cookiesDialog is the cookie manager for the active environment's jar.
+func (g *gui) cookiesDialog() {
+	env := g.envStore.Active
+	jar := g.cookieStore.Jar(env)
+	var cookies []core.Cookie
+
+	envName := env
+	if envName == "" {
+		envName = "No Environment"
+	}
+
+	empty := widget.NewLabel("No cookies yet. Responses add them here while the jar is on.")
+	empty.Importance = widget.LowImportance
+
+	var list *widget.List
+	reload := func() {
+		cookies = jar.All()
+		if len(cookies) == 0 {
+			empty.Show()
+		} else {
+			empty.Hide()
+		}
+		list.Refresh()
+	}
+
+	list = widget.NewList(
+		func() int {
+			return len(cookies)
+		},
+		func() fyne.CanvasObject {
+			domain := widget.NewLabel("example.com")
+			domain.TextStyle.Bold = true
+			cookie := widget.NewLabel("name=value")
+			cookie.Truncation = fyne.TextTruncateEllipsis
+			expiry := widget.NewLabel("Session")
+			expiry.Importance = widget.LowImportance
+			edit := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			edit.Importance = widget.LowImportance
+			del := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			del.Importance = widget.LowImportance
+
+			return container.NewBorder(nil, nil, domain, container.NewHBox(expiry, edit, del), cookie)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			c := cookies[i]
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(c.Name + "=" + c.Value)
+			row.Objects[1].(*widget.Label).SetText(c.Domain + c.Path)
+
+			right := row.Objects[2].(*fyne.Container)
+			right.Objects[0].(*widget.Label).SetText(cookieExpiry(c))
+			right.Objects[1].(*widget.Button).OnTapped = func() {
+				g.editCookieDialog(jar, c, reload)
+			}
+			right.Objects[2].(*widget.Button).OnTapped = func() {
+				jar.Delete(c)
+				reload()
+			}
+		},
+	)
+
+	reload()
+
+	enabled := widget.NewCheck("Store cookies and send them with matching requests", func(b bool) {
+		g.cookieStore.Enabled = b
+		g.applyCookieJar()
+	})
+	enabled.SetChecked(g.cookieStore.Enabled)
+
+	addBtn := widget.NewButtonWithIcon("Add Cookie", theme.ContentAddIcon(), func() {
+		g.editCookieDialog(jar, core.Cookie{Path: "/"}, reload)
+	})
+
+	clearBtn := widget.NewButtonWithIcon("Clear All", theme.DeleteIcon(), func() {
+		dialog.NewConfirm("Clear Cookies", "Delete every cookie in \""+envName+"\"?", func(ok bool) {
+			if ok {
+				jar.Clear()
+				reload()
+			}
+		}, *g.Window).Show()
+	})
+	clearBtn.Importance = widget.DangerImportance
+
+	content := container.NewBorder(
+		container.NewVBox(enabled, sectionHeader("Cookies — "+envName)),
+		container.NewBorder(nil, nil, addBtn, clearBtn),
+		nil, nil,
+		container.NewStack(list, container.NewCenter(empty)),
+	)
+
+	d := dialog.NewCustom("Cookies", "Done", content, *g.Window)
+	d.SetOnClosed(func() {
+		g.applyCookieJar()
+
+		if err := core.SaveCookieStore(g.cookieStore); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+	})
+	d.Resize(fyne.NewSize(640, 440))
+	d.Show()
+}
+
+// editCookieDialog adds or edits one cookie. Changing the name, domain or
+// path makes it a different cookie, so the original is replaced.
+func (g *gui) editCookieDialog(jar *core.CookieJar, orig core.Cookie, onDone func()) {
+	name := widget.NewEntry()
+	name.SetText(orig.Name)
+	value := widget.NewEntry()
+	value.SetText(orig.Value)
+	domain := widget.NewEntry()
+	domain.SetPlaceHolder("api.example.com")
+	domain.SetText(orig.Domain)
+	path := widget.NewEntry()
+	path.SetText(orig.Path)
+	expires := widget.NewEntry()
+	expires.SetPlaceHolder("Empty for a session cookie, or " + cookieExpiresLayout)
+	if !orig.Expires.IsZero() {
+		expires.SetText(orig.Expires.Local().Format(cookieExpiresLayout))
+	}
+
+	hostOnly := widget.NewCheck("Exact host only (no subdomains)", nil)
+	hostOnly.SetChecked(orig.HostOnly)
+	secure := widget.NewCheck("Secure (HTTPS only)", nil)
+	secure.SetChecked(orig.Secure)
+	httpOnly := widget.NewCheck("HttpOnly", nil)
+	httpOnly.SetChecked(orig.HttpOnly)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Value", value),
+		widget.NewFormItem("Domain", domain),
+		widget.NewFormItem("Path", path),
+		widget.NewFormItem("Expires", expires),
+	)
+
+	dialog.NewCustomConfirm("Cookie", "Save", "Cancel", container.NewVBox(form, hostOnly, secure, httpOnly), func(ok bool) {
+		if !ok {
+			return
+		}
+
+		c := core.Cookie{
+			Name:     name.Text,
+			Value:    value.Text,
+			Domain:   domain.Text,
+			Path:     path.Text,
+			HostOnly: hostOnly.Checked,
+			Secure:   secure.Checked,
+			HttpOnly: httpOnly.Checked,
+		}
+		if c.Name == "" || c.Domain == "" {
+			dialog.NewError(errors.New("a cookie needs a name and a domain"), *g.Window).Show()
+			return
+		}
+		if expires.Text != "" {
+			t, err := time.ParseInLocation(cookieExpiresLayout, expires.Text, time.Local)
+			if err != nil {
+				dialog.NewError(errors.New("expires must look like "+cookieExpiresLayout), *g.Window).Show()
+				return
+			}
+			c.Expires = t
+		}
+
+		if orig.Name != "" {
+			jar.Delete(orig)
+		}
+		jar.Put(c)
+		onDone()
+	}, *g.Window).Show()
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// applyDNS publishes the active environment's host overrides and resolver;
+// requests layer their own on top at send time.
+func (g *gui) applyDNS() {
+	if env := g.envStore.ActiveEnv(); env != nil {
+		core.SetDNS(env.DNS)
+		return
+	}
+
+	core.SetDNS(core.DNSConfig{})
+}
+
+// dnsForm edits a DNSConfig in place: host → address rows and the DNS
+// server. onChange runs after every edit.
+func (g *gui) dnsForm(d *core.DNSConfig, onChange func()) fyne.CanvasObject {
+	rows := container.NewVBox()
+
+	var rebuild func()
+	rebuild = func() {
+		rows.Objects = nil
+		for i := range d.Overrides {
+			o := &d.Overrides[i]
+
+			host := widget.NewEntry()
+			host.SetPlaceHolder("api.example.com or api.example.com:443")
+			host.SetText(o.Host)
+			host.OnChanged = func(s string) {
+				o.Host = s
+				onChange()
+			}
+
+			addr := widget.NewEntry()
+			addr.SetPlaceHolder("10.0.0.5, 10.0.0.5:8443 or other.host:443")
+			addr.SetText(o.Addr)
+			addr.OnChanged = func(s string) {
+				o.Addr = s
+				onChange()
+			}
+
+			remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				d.Overrides = append(d.Overrides[:i], d.Overrides[i+1:]...)
+				onChange()
+				rebuild()
+			})
+
+			rows.Add(container.NewBorder(nil, nil, nil, remove, container.NewGridWithColumns(2, host, addr)))
+		}
+		rows.Refresh()
+	}
+	rebuild()
+
+	addBtn := widget.NewButtonWithIcon("Add Host Override", theme.ContentAddIcon(), func() {
+		d.Overrides = append(d.Overrides, core.HostOverride{})
+		onChange()
+		rebuild()
+	})
+	addBtn.Importance = widget.LowImportance
+
+	server := widget.NewEntry()
+	server.SetPlaceHolder("Empty: system resolver")
+	server.SetText(d.Server)
+	server.OnChanged = func(s string) {
+		d.Server = s
+		onChange()
+	}
+
+	hint := widget.NewLabel("Overrides connect to another address without changing the Host header or TLS name, like curl --resolve / --connect-to. Behind a proxy they apply to the proxy's host.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(
+		rows,
+		container.NewHBox(addBtn),
+		container.NewBorder(nil, nil, widget.NewLabel("DNS server"), nil, server),
+		hint,
+	)
+}
+
+// envDNSDialog edits an environment's overrides; the env dialog persists
+// them.
+func (g *gui) envDNSDialog(env *core.Environment) {
+	d := env.DNS
+	d.Overrides = append([]core.HostOverride(nil), env.DNS.Overrides...)
+
+	dlg := dialog.NewCustomConfirm("Hosts & DNS — "+env.Name, "Done", "Cancel", g.dnsForm(&d, func() {}), func(ok bool) {
+		if ok {
+			env.DNS = d
+		}
+	}, *g.Window)
+	dlg.Resize(fyne.NewSize(600, 0))
+	dlg.Show()
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// watchDotenv (re)starts watching every linked .env file; a change reloads
+// the environments linked to it and republishes the variables.
+func (g *gui) watchDotenv() {
+	if g.stopDotenvWatch != nil {
+		g.stopDotenvWatch()
+		g.stopDotenvWatch = nil
+	}
+
+	var paths []string
+	for _, e := range g.envStore.Envs {
+		if e.Dotenv != "" {
+			paths = append(paths, e.Dotenv)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	stop, err := core.WatchFiles(paths, func(path string) {
+		fyne.Do(func() {
+			for _, e := range g.envStore.Envs {
+				if e.Dotenv != "" && filepath.Clean(e.Dotenv) == path {
+					e.ReloadDotenv()
+				}
+			}
+			g.applyVars()
+		})
+	})
+	if err != nil {
+		log.Println("watching .env files:", err)
+		return
+	}
+	g.stopDotenvWatch = stop
+}
+
+// dotenvRow offers importing a .env file into env's rows (a copy) and
+// linking one, whose variables the rows then override. refresh redraws
+// the rows after an import.
+func (g *gui) dotenvRow(env *core.Environment, refresh func()) fyne.CanvasObject {
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+	status.Importance = widget.LowImportance
+
+	var linkBtn *widget.Button
+	update := func() {
+		switch {
+		case env.Dotenv == "":
+			status.SetText("")
+			status.Hide()
+			linkBtn.SetText("Link .env")
+			return
+		case env.DotenvErr != nil:
+			status.SetText("Linked .env: " + env.DotenvErr.Error())
+		default:
+			status.SetText(fmt.Sprintf("Linked to %s: %d variables, reloaded when the file changes. Rows here override them.", env.Dotenv, len(env.DotenvVars)))
+		}
+		status.Show()
+		linkBtn.SetText("Unlink .env")
+	}
+
+	pick := func(then func(path string)) {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close() // only the path is needed
+			then(rc.URI().Path())
+		}, *g.Window)
+	}
+
+	importBtn := widget.NewButtonWithIcon("Import .env", theme.DownloadIcon(), func() {
+		pick(func(path string) {
+			vars, err := core.ReadDotenv(path)
+			if err != nil {
+				dialog.NewError(err, *g.Window).Show()
+				return
+			}
+			*env.Variables = mergeVars(*env.Variables, vars)
+			refresh()
+		})
+	})
+	importBtn.Importance = widget.LowImportance
+
+	linkBtn = widget.NewButtonWithIcon("", theme.MailAttachmentIcon(), func() {
+		if env.Dotenv != "" {
+			env.Dotenv = ""
+			env.ReloadDotenv()
+			update()
+			return
+		}
+		pick(func(path string) {
+			env.Dotenv = path
+			env.ReloadDotenv()
+			update()
+		})
+	})
+	linkBtn.Importance = widget.LowImportance
+
+	update()
+	return container.NewVBox(container.NewHBox(importBtn, linkBtn), status)
+}
+
+// mergeVars sets imported over rows — same key: new value — keeping one
+// empty row last for typing.
+func mergeVars(rows, imported []core.FormType) []core.FormType {
+	var out []core.FormType
+	for _, r := range rows {
+		if r.Key != "" || r.Value != "" {
+			out = append(out, r)
+		}
+	}
+
+	for _, v := range imported {
+		if i := slices.IndexFunc(out, func(r core.FormType) bool { return r.Key == v.Key }); i >= 0 {
+			out[i].Value = v.Value
+		} else {
+			out = append(out, v)
+		}
+	}
+
+	return append(out, core.FormType{Checked: true})
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// humanBytes is a one-decimal size for progress text: "12.3 MB".
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressText phrases a transfer: "12.3 MB of 40.0 MB · 5.1 MB/s · 6s left".
+func progressText(p core.Progress) string {
+	s := humanBytes(p.Done)
+	if p.Total >= 0 {
+		s += " of " + humanBytes(p.Total)
+	}
+	if rate := p.Rate(); rate > 0 {
+		s += " · " + humanBytes(int64(rate)) + "/s"
+	}
+	if eta := p.ETA(); eta > 0 {
+		s += " · " + eta.Round(time.Second).String() + " left"
+	}
+	if p.Upload {
+		s = "Uploading " + s
+	}
+	return s
+}
+
+// transferBar shows live progress while a body is moving, then, for
+// download mode, where the file went. progress holds a core.Progress; the
+// zero value hides the bar.
+func (g *gui) transferBar(progress binding.Untyped, file binding.String) fyne.CanvasObject {
+	bar := widget.NewProgressBar()
+	bar.TextFormatter = func() string { return "" }
+	unknown := widget.NewProgressBarInfinite()
+	unknown.Stop()
+	unknown.Hide()
+	label := widget.NewLabel("")
+	label.Importance = widget.LowImportance
+
+	progressRow := container.NewBorder(nil, nil, nil, label, container.NewStack(bar, unknown))
+	progressRow.Hide()
+
+	progress.AddListener(binding.NewDataListener(func() {
+		v, _ := progress.Get()
+		p, _ := v.(core.Progress)
+		if p == (core.Progress{}) {
+			unknown.Stop()
+			progressRow.Hide()
+			return
+		}
+
+		label.SetText(progressText(p))
+		if p.Total > 0 {
+			unknown.Stop()
+			unknown.Hide()
+			bar.Show()
+			bar.SetValue(float64(p.Done) / float64(p.Total))
+		} else {
+			bar.Hide()
+			unknown.Show()
+			unknown.Start()
+		}
+		progressRow.Show()
+	}))
+
+	path := widget.NewLabel("")
+	path.Truncation = fyne.TextTruncateEllipsis
+	copyPath := copyFeedbackButton(func() string {
+		p, _ := file.Get()
+		return p
+	})
+	fileRow := container.NewBorder(nil, nil, widget.NewIcon(theme.DownloadIcon()), copyPath, path)
+	fileRow.Hide()
+
+	file.AddListener(binding.NewDataListener(func() {
+		p, _ := file.Get()
+		if p == "" {
+			fileRow.Hide()
+			return
+		}
+		path.SetText("Saved to " + p + " — showing the first 64 KB")
+		fileRow.Show()
+	}))
+
+	return container.NewVBox(progressRow, fileRow)
+}
+
+// copyFileTo writes the downloaded file at src to a user-chosen location.
+func (g *gui) copyFileTo(src, name string) {
+	fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		f, err := os.Open(src)
+		if err != nil {
+			dialog.NewError(err, *g.Window).Show()
+			return
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(writer, f); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+	}, *g.Window)
+	fileSave.SetFileName(name)
+	fileSave.Show()
+}
+
+// savePathEntry is pathEntry with a save dialog, for files yet to exist.
+func (g *gui) savePathEntry(label, value, placeholder string, set func(string)) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.SetText(value)
+	entry.OnChanged = set
+
+	pick := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		dialog.ShowFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil || wc == nil {
+				return
+			}
+			wc.Close() // only the path is needed; SendRequest writes it
+			entry.SetText(wc.URI().Path())
+		}, *g.Window)
+	})
+	pick.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, widget.NewLabel(label), pick, entry)
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vardanabhanot/myapi/core"
+)
+
+func TestProgressText(t *testing.T) {
+	tests := []struct {
+		p    core.Progress
+		want string
+	}{
+		{core.Progress{Done: 512, Total: -1}, "512 B"},
+		{core.Progress{Done: 1536, Total: 4096, Elapsed: time.Second}, "1.5 KB of 4.0 KB · 1.5 KB/s · 2s left"},
+		{core.Progress{Done: 3 << 20, Total: 3 << 20, Elapsed: 2 * time.Second}, "3.0 MB of 3.0 MB · 1.5 MB/s"},
+		{core.Progress{Upload: true, Done: 5 << 30, Total: 10 << 30, Elapsed: 10 * time.Second}, "Uploading 5.0 GB of 10.0 GB · 512.0 MB/s · 10s left"},
+	}
+	for _, tt := range tests {
+		if got := progressText(tt.p); got != tt.want {
+			t.Errorf("progressText(%+v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -13,7 +15,13 @@ import (
 // "No Environment" option; list selection IS the active environment.
 func (g *gui) makeEnvContent() *fyne.Container {
 	g.envStore = core.LoadEnvStore()
-	core.SetActiveVars(g.envStore.ActiveEnv().VarMap())
+	g.applyVars()
+	g.watchDotenv()
+	g.cookieStore = core.LoadCookieStore()
+	g.applyCookieJar()
+	g.globalProxy = core.LoadGlobalProxy()
+	g.applyProxy()
+	g.applyDNS()
 
 	g.envList = widget.NewList(
 		func() int {
@@ -53,7 +61,10 @@ func (g *gui) makeEnvContent() *fyne.Container {
 			g.envStore.Active = g.envStore.Envs[i-1].Name
 		}
 
-		core.SetActiveVars(g.envStore.ActiveEnv().VarMap())
+		g.applyVars()
+		g.applyCookieJar()
+		g.applyProxy()
+		g.applyDNS()
 
 		if err := core.SaveEnvStore(g.envStore); err != nil {
 			dialog.NewError(err, *g.Window).Show()
@@ -79,11 +90,99 @@ func (g *gui) makeEnvContent() *fyne.Container {
 	})
 	addBtn.Importance = widget.HighImportance
 
-	header := container.NewBorder(nil, nil, container.NewPadded(sectionHeader("Environments")), container.NewPadded(addBtn), nil)
+	globalsBtn := widget.NewButtonWithIcon("", theme.HomeIcon(), g.globalsDialog)
+	globalsBtn.Importance = widget.LowImportance
+
+	sessionBtn := widget.NewButtonWithIcon("", theme.HistoryIcon(), g.sessionVarsDialog)
+	sessionBtn.Importance = widget.LowImportance
+
+	compareBtn := widget.NewButtonWithIcon("", theme.ListIcon(), g.compareEnvsDialog)
+	compareBtn.Importance = widget.LowImportance
+
+	vaultBtn := widget.NewButtonWithIcon("", theme.VisibilityOffIcon(), g.vaultDialog)
+	vaultBtn.Importance = widget.LowImportance
+
+	cookiesBtn := widget.NewButtonWithIcon("", theme.StorageIcon(), g.cookiesDialog)
+	cookiesBtn.Importance = widget.LowImportance
+
+	header := container.NewBorder(nil, nil, container.NewPadded(sectionHeader("Environments")), container.NewPadded(container.NewHBox(globalsBtn, sessionBtn, compareBtn, vaultBtn, cookiesBtn, addBtn)), nil)
+
+	// A master-password vault starts locked; ask up front rather than let
+	// secret variables silently resolve to nothing.
+	if core.VaultLocked() && core.VaultMode() == core.VaultPassword {
+		g.vaultDialog()
+	}
 
 	return container.NewBorder(header, nil, nil, nil, g.envList)
 }
 
+// applyVars publishes every variable scope ApplyEnv layers: globals, the
+// active environment's, then the session's.
+func (g *gui) applyVars() {
+	core.SetOSVars(core.OSVars(g.envStore.OSVars))
+	core.SetGlobalVars(core.VarsOf(g.envStore.Globals))
+	core.SetActiveVars(g.envStore.ActiveEnv().VarMap())
+	core.SetSessionVars(core.VarsOf(g.sessionVars))
+	g.recheckVars()
+}
+
+// globalsDialog edits the variables shared by every environment.
+func (g *gui) globalsDialog() {
+	if g.envStore.Globals == nil {
+		g.envStore.Globals = &[]core.FormType{{Checked: true}}
+	}
+
+	// OS variables sit beneath the globals, read live, never saved
+	exposed := widget.NewLabel("")
+	exposed.Importance = widget.LowImportance
+	countExposed := func() {
+		exposed.SetText(fmt.Sprintf("%d exposed", len(core.OSVars(g.envStore.OSVars))))
+	}
+	countExposed()
+
+	osVars := widget.NewEntry()
+	osVars.SetPlaceHolder("HOME, CI_*")
+	osVars.SetText(g.envStore.OSVars)
+	osVars.OnChanged = func(s string) {
+		g.envStore.OSVars = s
+		countExposed()
+	}
+
+	osRow := container.NewBorder(nil, nil, widget.NewLabel("OS variables"), exposed, osVars)
+
+	g.varsDialog("Global Variables", "Shared by every environment; an environment's variable of the same name wins. OS variables are read from MyAPI's process environment, beneath the globals, and never saved.", g.envStore.Globals, osRow, func() {
+		if err := core.SaveEnvStore(g.envStore); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+	})
+}
+
+// sessionVarsDialog edits the transient variables that win over every
+// environment and global until the app closes.
+func (g *gui) sessionVarsDialog() {
+	if g.sessionVars == nil {
+		g.sessionVars = &[]core.FormType{{Checked: true}}
+	}
+
+	g.varsDialog("Session Variables", "Override the environment and globals until MyAPI closes. Never saved.", g.sessionVars, nil, func() {})
+}
+
+// varsDialog edits a scope's rows in place, with extra (optional) below,
+// and republishes the scopes on close, before onClosed.
+func (g *gui) varsDialog(title, hint string, rows *[]core.FormType, extra fyne.CanvasObject, onClosed func()) {
+	hintLabel := widget.NewLabel(hint + " Hover a {{name}} in a request to see which scope it resolves from.")
+	hintLabel.Wrapping = fyne.TextWrapWord
+	hintLabel.Importance = widget.LowImportance
+
+	d := dialog.NewCustom(title, "Done", container.NewBorder(hintLabel, extra, nil, nil, g.formBlock(rows, true)), *g.Window)
+	d.SetOnClosed(func() {
+		g.applyVars()
+		onClosed()
+	})
+	d.Resize(fyne.NewSize(560, 460))
+	d.Show()
+}
+
 // selectActiveEnv syncs the list selection with the persisted active env.
 func (g *gui) selectActiveEnv() {
 	index := 0
@@ -133,10 +232,12 @@ func (g *gui) editEnvDialog(env *core.Environment) {
 	nameEntry := widget.NewEntry()
 	nameEntry.SetText(env.Name)
 	nameEntry.OnChanged = func(s string) {
-		// Active is tracked by name, keep it following a rename
+		// Active is tracked by name, keep it following a rename; so are
+		// cookie jars
 		if g.envStore.Active == env.Name {
 			g.envStore.Active = s
 		}
+		g.cookieStore.RenameEnv(env.Name, s)
 		env.Name = s
 	}
 
@@ -158,30 +259,113 @@ func (g *gui) editEnvDialog(env *core.Environment) {
 			if g.envStore.Active == env.Name {
 				g.envStore.Active = ""
 			}
+			delete(g.cookieStore.Jars, env.Name)
 
 			d.Hide() // OnClosed persists and re-syncs the list
 		}, *g.Window).Show()
 	})
 	deleteBtn.Importance = widget.DangerImportance
 
-	hint := widget.NewLabel("Use {{name}} in URL, headers, body or auth fields.")
+	proxyBtn := widget.NewButtonWithIcon("Proxy", theme.SettingsIcon(), func() {
+		g.envProxyDialog(env)
+	})
+	proxyBtn.Importance = widget.LowImportance
+
+	dnsBtn := widget.NewButtonWithIcon("Hosts & DNS", theme.SettingsIcon(), func() {
+		g.envDNSDialog(env)
+	})
+	dnsBtn.Importance = widget.LowImportance
+
+	hint := widget.NewLabel("Use {{name}} in URL, headers, body or auth fields, or in another variable's value. The eye marks a variable secret: masked, and kept in the vault.")
+	hint.Wrapping = fyne.TextWrapWord
 	hint.Importance = widget.LowImportance
 
+	var raw *appEntry
+	var fromRaw func() bool
+
+	duplicateBtn := widget.NewButtonWithIcon("Duplicate", theme.ContentCopyIcon(), func() {
+		if raw.Visible() && !fromRaw() {
+			return
+		}
+		dup := g.envStore.Duplicate(env)
+		d.Hide() // OnClosed persists
+		g.editEnvDialog(dup)
+	})
+	duplicateBtn.Importance = widget.LowImportance
+
+	vars := g.formBlock(env.Variables, true)
+
+	// Raw mode edits the rows as .env lines; switching back parses them
+	raw = g.newAppEntry()
+	raw.MultiLine = true
+	raw.TextStyle.Monospace = true
+	raw.SetPlaceHolder("KEY=value, one per line. # KEY=value disables a row.")
+	raw.Hide()
+
+	// fromRaw applies the raw text, reporting whether it parsed
+	fromRaw = func() bool {
+		rows, err := core.ParseVars(raw.Text, *env.Variables)
+		if err != nil {
+			dialog.NewError(err, *g.Window).Show()
+			return false
+		}
+		*env.Variables = rows
+		vars.Refresh()
+		return true
+	}
+
+	mode := widget.NewRadioGroup([]string{"Form", "Raw"}, nil)
+	mode.Horizontal = true
+	mode.SetSelected("Form")
+	mode.OnChanged = func(s string) {
+		switch {
+		case s == "Raw" && !raw.Visible():
+			raw.SetText(core.FormatVars(*env.Variables))
+			vars.Hide()
+			raw.Show()
+		case s == "Form" && raw.Visible():
+			if !fromRaw() {
+				mode.SetSelected("Raw")
+				return
+			}
+			raw.Hide()
+			vars.Show()
+		}
+	}
+
 	content := container.NewBorder(
-		container.NewVBox(nameEntry, hint),
-		container.NewBorder(nil, nil, deleteBtn, nil),
+		container.NewVBox(nameEntry, hint, g.dotenvRow(env, func() {
+			vars.Refresh()
+			if raw.Visible() {
+				raw.SetText(core.FormatVars(*env.Variables))
+			}
+		}), mode),
+		container.NewBorder(nil, nil, container.NewHBox(deleteBtn, duplicateBtn), container.NewHBox(dnsBtn, proxyBtn)),
 		nil, nil,
-		g.formBlock(env.Variables),
+		container.NewStack(vars, raw),
 	)
 
 	d = dialog.NewCustom("Edit Environment", "Done", content, *g.Window)
 	d.SetOnClosed(func() {
-		core.SetActiveVars(g.envStore.ActiveEnv().VarMap())
+		// Unparseable raw text keeps the rows as they were
+		if raw.Visible() {
+			fromRaw()
+		}
+
+		g.applyVars()
+		g.watchDotenv() // the link may have changed, or the env gone
+		g.applyCookieJar()
+		g.applyProxy()
+		g.applyDNS()
 
 		if err := core.SaveEnvStore(g.envStore); err != nil {
 			dialog.NewError(err, *g.Window).Show()
 		}
 
+		if err := core.SaveCookieStore(g.cookieStore); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+
 		g.envList.Refresh()
 		g.selectActiveEnv()
 		g.syncEnvSelect() // rename/delete may not move the list index
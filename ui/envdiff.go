@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// compareEnvsDialog lines environments up side by side — missing keys in
+// red, differing values in amber — and renames or adds a key across all of
+// them.
+func (g *gui) compareEnvsDialog() {
+	if len(g.envStore.Envs) < 2 {
+		dialog.NewInformation("Compare Environments", "Create at least two environments to compare them.", *g.Window).Show()
+		return
+	}
+
+	included := map[*core.Environment]bool{}
+	for _, e := range g.envStore.Envs {
+		included[e] = true
+	}
+	onlyDiffs := true
+
+	table := container.NewVBox()
+	summary := widget.NewLabel("")
+	summary.Importance = widget.LowImportance
+
+	rebuild := func() {
+		var envs []*core.Environment
+		for _, e := range g.envStore.Envs {
+			if included[e] {
+				envs = append(envs, e)
+			}
+		}
+
+		cells := []fyne.CanvasObject{widget.NewLabelWithStyle("Key", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})}
+		for _, e := range envs {
+			cells = append(cells, diffCell(e.Name, widget.MediumImportance, true))
+		}
+
+		diffs := core.DiffEnvs(envs)
+		differing := 0
+		for _, d := range diffs {
+			same := d.Same()
+			if !same {
+				differing++
+			}
+			if same && onlyDiffs {
+				continue
+			}
+
+			cells = append(cells, diffCell(d.Key, widget.MediumImportance, !same))
+			for i := range envs {
+				switch {
+				case !d.Present[i]:
+					cells = append(cells, diffCell("missing", widget.DangerImportance, false))
+				case d.Secret:
+					cells = append(cells, diffCell(core.SecretMask, diffImportance(same), false))
+				default:
+					cells = append(cells, diffCell(strings.ReplaceAll(d.Values[i], "\n", " "), diffImportance(same), false))
+				}
+			}
+		}
+
+		table.Objects = []fyne.CanvasObject{container.NewGridWithColumns(len(envs)+1, cells...)}
+		table.Refresh()
+		summary.SetText(fmt.Sprintf("%d keys, %d differ or are missing somewhere", len(diffs), differing))
+	}
+
+	envChecks := container.NewHBox()
+	for _, e := range g.envStore.Envs {
+		check := widget.NewCheck(e.Name, nil)
+		check.SetChecked(true)
+		check.OnChanged = func(b bool) {
+			included[e] = b
+			rebuild()
+		}
+		envChecks.Add(check)
+	}
+
+	diffsCheck := widget.NewCheck("Only differences", nil)
+	diffsCheck.SetChecked(true)
+	diffsCheck.OnChanged = func(b bool) {
+		onlyDiffs = b
+		rebuild()
+	}
+
+	changed := func(n int, what string) {
+		if n == 0 {
+			return
+		}
+		if err := core.SaveEnvStore(g.envStore); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+		g.applyVars()
+		rebuild()
+		summary.SetText(fmt.Sprintf("%s in %d environments", what, n))
+	}
+
+	addBtn := widget.NewButtonWithIcon("Add Key", theme.ContentAddIcon(), func() {
+		key, value := widget.NewEntry(), widget.NewEntry()
+		value.SetPlaceHolder("Value for environments without it")
+		dialog.NewForm("Add Key to All Environments", "Add", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Key", key),
+			widget.NewFormItem("Value", value),
+		}, func(ok bool) {
+			if ok && key.Text != "" {
+				changed(g.envStore.AddKey(key.Text, value.Text), "Added "+key.Text)
+			}
+		}, *g.Window).Show()
+	})
+	addBtn.Importance = widget.LowImportance
+
+	renameBtn := widget.NewButtonWithIcon("Rename Key", theme.DocumentCreateIcon(), func() {
+		keys := map[string]bool{}
+		for _, e := range g.envStore.Envs {
+			if e.Variables != nil {
+				for _, v := range *e.Variables {
+					if v.Key != "" {
+						keys[v.Key] = true
+					}
+				}
+			}
+		}
+		from := widget.NewSelect(slices.Sorted(maps.Keys(keys)), nil)
+		to := widget.NewEntry()
+		dialog.NewForm("Rename Key in All Environments", "Rename", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Key", from),
+			widget.NewFormItem("New name", to),
+		}, func(ok bool) {
+			if ok && from.Selected != "" && to.Text != "" {
+				changed(g.envStore.RenameKey(from.Selected, to.Text), "Renamed "+from.Selected+" to "+to.Text)
+			}
+		}, *g.Window).Show()
+	})
+	renameBtn.Importance = widget.LowImportance
+
+	rebuild()
+
+	content := container.NewBorder(
+		container.NewVBox(
+			container.NewHScroll(envChecks),
+			container.NewHBox(diffsCheck, layout.NewSpacer(), addBtn, renameBtn),
+			widget.NewSeparator(),
+		),
+		summary, nil, nil,
+		container.NewVScroll(table),
+	)
+
+	d := dialog.NewCustom("Compare Environments", "Close", content, *g.Window)
+	d.Resize(fyne.NewSize(760, 500))
+	d.Show()
+}
+
+// diffImportance colours the values of a key: amber unless all agree.
+func diffImportance(same bool) widget.Importance {
+	if same {
+		return widget.MediumImportance
+	}
+	return widget.WarningImportance
+}
+
+func diffCell(text string, importance widget.Importance, bold bool) *widget.Label {
+	l := widget.NewLabelWithStyle(text, fyne.TextAlignLeading, fyne.TextStyle{Bold: bold})
+	l.Importance = importance
+	l.Truncation = fyne.TextTruncateEllipsis
+	return l
+}
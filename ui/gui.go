@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"net/url"
 	"strings"
+	"weak"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -23,19 +24,27 @@ var appversion string
 type gui struct {
 	Window *fyne.Window
 
-	urlInput       *appEntry
-	queryList      *widget.List
-	syncingQuery   bool // true while updateURL writes the URL entry
-	tabs           map[string]*tab
-	doctabs        *container.DocTabs
-	sidebar        *fyne.Container
-	requestHistory []*core.HistoryEntry
-	requestList    *widget.List
-	envStore       *core.EnvStore
-	envList        *widget.List
-	envSelect      *widget.Select
-	collections    []*core.Collection
-	collectionTree *widget.Tree
+	urlInput        *appEntry
+	queryList       *widget.List
+	syncingQuery    bool // true while updateURL writes the URL entry
+	tabs            map[string]*tab
+	doctabs         *container.DocTabs
+	sidebar         *fyne.Container
+	requestHistory  []*core.HistoryEntry
+	requestList     *widget.List
+	envStore        *core.EnvStore
+	envList         *widget.List
+	envSelect       *widget.Select
+	certStore       *core.CertStore
+	cookieStore     *core.CookieStore
+	globalProxy     core.ProxyConfig
+	sessionVars     *[]core.FormType // transient variables over the environment's; never saved
+	stopDotenvWatch func()           // ends watching linked .env files; nil when none are
+	varTip          *varTip
+	varEntries      []weak.Pointer[appEntry] // for recheckVars
+	sendLiteral     map[string]bool          // request IDs whose unresolved placeholders no longer ask
+	collections     []*core.Collection
+	collectionTree  *widget.Tree
 
 	// focusedCollection is the creation context for the collections tab's
 	// new-request button, VS Code style: the last collection the user
@@ -59,12 +68,23 @@ type tab struct {
 
 type bindings struct {
 	headers binding.StringList
+	sorted  binding.Bool // headers came back sorted by name, not in the order received
 	cookies binding.StringList
 	body    binding.String
 	status  binding.String
 	size    binding.String
 	time    binding.String
 	timings binding.Untyped // holds core.Timings for the waterfall popup
+	tls     binding.StringList
+	jwts    binding.StringList // "source||token" rows for the JWT tab
+
+	progress  binding.Untyped    // core.Progress while a body transfers; zero when idle
+	file      binding.String     // download mode: where the body was saved
+	proto     binding.String     // "HTTP/2.0 · TLS 1.3 · cipher" beside the status pill
+	attempts  binding.StringList // "#n · status||timings" rows when the send was retried
+	redirects binding.Untyped    // []core.Hop: the followed chain plus the final response
+	wire      binding.Untyped    // []byte: a compressed response's raw head; nil otherwise
+	rawLog    binding.String     // the Raw tab: every round trip as sent and received
 }
 
 func MakeGUI(window *fyne.Window, version string) fyne.CanvasObject {
@@ -72,6 +92,10 @@ func MakeGUI(window *fyne.Window, version string) fyne.CanvasObject {
 	g := &gui{Window: window}
 	appversion = version
 	g.tabs = make(map[string]*tab)
+	g.varTip = newVarTip()
+	vaultErr := core.OpenVault()
+	g.certStore = core.LoadCertStore()
+	core.SetHostCerts(g.certStore.Hosts)
 	g.doctabs = container.NewDocTabs()
 	tabItem := g.makeTab(nil)
 	g.doctabs.Append(tabItem)
@@ -109,6 +133,9 @@ func MakeGUI(window *fyne.Window, version string) fyne.CanvasObject {
 	}
 
 	g.sidebar = g.makeSideBar()
+	if vaultErr != nil {
+		dialog.NewError(vaultErr, *g.Window).Show()
+	}
 	baseView := NewHSplit(g.sidebar, g.doctabs)
 	baseView.Offset = 0.22
 
@@ -143,7 +170,7 @@ func MakeGUI(window *fyne.Window, version string) fyne.CanvasObject {
 
 	footer := container.NewThemeOverride(container.NewBorder(footerSeperator, nil, envSwitcher, footerContent, nil), &footerTheme{})
 
-	return container.NewBorder(nil, footer, nil, nil, baseView)
+	return container.NewStack(container.NewBorder(nil, footer, nil, nil, baseView), g.varTip.layer)
 }
 
 // activeTab resolves the currently selected DocTabs item to its tab entry.
@@ -194,11 +221,18 @@ func (g *gui) dispatchShortcut(s fyne.Shortcut) bool {
 // appEntry is a widget.Entry that keeps app-wide Ctrl shortcuts working
 // while it has focus: the driver delivers shortcuts ONLY to the focused
 // widget, so a plain Entry would swallow Ctrl+T/W/Enter/F.
-// ponytail: only the URL bar and body editors use it; swap the remaining
-// widget.NewEntry sites if users miss shortcuts elsewhere.
+// It is also the entry for text that gets {{var}} substitution: hovering
+// one shows the values (see varTip), a badge flags unresolved ones and
+// typing "{{" offers names (see checkVars, suggestVars).
+// ponytail: the URL bar, body editors, auth fields and key/value row
+// values use it; swap the remaining widget.NewEntry sites if users miss
+// shortcuts elsewhere.
 type appEntry struct {
 	widget.Entry
-	g *gui
+	g     *gui
+	badge *varBadge // the ActionItem, unless laid out beside (withBadge)
+
+	badgeOutside bool
 
 	// onPasteCurl intercepts a pasted "curl ..." command; set only on the
 	// URL bar. Return true to consume the paste. Ctrl+V only — the
@@ -209,9 +243,44 @@ type appEntry struct {
 func (g *gui) newAppEntry() *appEntry {
 	e := &appEntry{g: g}
 	e.ExtendBaseWidget(e)
+	g.trackVars(e)
 	return e
 }
 
+// CreateRenderer adds the badge now: Entry reads ActionItem only here, and
+// withBadge may have been called since newAppEntry.
+func (e *appEntry) CreateRenderer() fyne.WidgetRenderer {
+	if e.ActionItem == nil && !e.badgeOutside {
+		e.ActionItem = e.badge
+	}
+	e.checkVars()
+	return e.Entry.CreateRenderer()
+}
+
+func (e *appEntry) SetText(text string) {
+	e.Entry.SetText(text)
+	e.checkVars()
+}
+
+func (e *appEntry) TypedKey(key *fyne.KeyEvent) {
+	e.Entry.TypedKey(key)
+	e.checkVars()
+}
+
+func (e *appEntry) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	e.checkVars()
+	if r == '{' && strings.HasSuffix(e.textBeforeCursor(), "{{") {
+		e.suggestVars()
+	}
+}
+
+var _ desktop.Hoverable = (*appEntry)(nil)
+
+func (e *appEntry) MouseIn(*desktop.MouseEvent)    { e.g.showVarTip(e) }
+func (e *appEntry) MouseMoved(*desktop.MouseEvent) {}
+func (e *appEntry) MouseOut()                      { e.g.hideVarTip() }
+
 func (e *appEntry) TypedShortcut(s fyne.Shortcut) {
 	if ps, ok := s.(*fyne.ShortcutPaste); ok && e.onPasteCurl != nil {
 		if cmd := strings.TrimSpace(ps.Clipboard.Content()); strings.HasPrefix(cmd, "curl ") {
@@ -220,10 +289,15 @@ func (e *appEntry) TypedShortcut(s fyne.Shortcut) {
 			}
 		}
 	}
+	if cs, ok := s.(*desktop.CustomShortcut); ok && cs.KeyName == fyne.KeySpace && cs.Modifier == fyne.KeyModifierControl {
+		e.suggestVars()
+		return
+	}
 	if e.g.dispatchShortcut(s) {
 		return
 	}
 	e.Entry.TypedShortcut(s)
+	e.checkVars() // paste, cut, undo
 }
 
 // closeTab is both the DocTabs close-intercept and the Ctrl+W handler:
@@ -242,13 +316,24 @@ func (g *gui) closeTab(ti *container.TabItem) {
 
 	// Clean Up
 	if deletable != "" {
+		delete(g.sendLiteral, deletable)
 		g.tabs[deletable].bindings.body.RemoveListener(g.tabs[deletable].bodyListner)
 		g.tabs[deletable].bindings.body = nil
 		g.tabs[deletable].bindings.headers = nil
+		g.tabs[deletable].bindings.sorted = nil
 		g.tabs[deletable].bindings.cookies = nil
+		g.tabs[deletable].bindings.tls = nil
+		g.tabs[deletable].bindings.jwts = nil
 		g.tabs[deletable].bindings.status = nil
 		g.tabs[deletable].bindings.timings = nil
 		g.tabs[deletable].bindings.time = nil
+		g.tabs[deletable].bindings.progress = nil
+		g.tabs[deletable].bindings.file = nil
+		g.tabs[deletable].bindings.proto = nil
+		g.tabs[deletable].bindings.attempts = nil
+		g.tabs[deletable].bindings.redirects = nil
+		g.tabs[deletable].bindings.wire = nil
+		g.tabs[deletable].bindings.rawLog = nil
 		g.tabs[deletable].bodyListner = nil
 		g.tabs[deletable].bindings = nil
 		g.tabs[deletable].collection = nil
@@ -345,14 +430,7 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 	requestType.SetSelected(request.Method)
 
 	var makeRequest *widget.Button
-	makeRequest = widget.NewButton("Send", func() {
-		if makeRequest.Text == "Cancel" {
-			go func() {
-				g.cancelRequest()
-			}()
-			return
-		}
-
+	send := func() {
 		// Create a cancelable context
 		g.requestCtx, g.cancelRequest = context.WithCancel(context.Background())
 
@@ -360,12 +438,25 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 		makeRequest.Importance = widget.DangerImportance
 		makeRequest.Refresh()
 
+		// Live transfer progress for the response panel's bar
+		ctx := g.requestCtx
+		var progress binding.Untyped
+		if t := g.tabs[request.ID]; t != nil && t.bindings.progress != nil {
+			progress = t.bindings.progress
+			ctx = core.WithProgress(ctx, func(p core.Progress) {
+				progress.Set(p)
+			})
+		}
+
 		go func(ctx context.Context) {
 			defer fyne.Do(func() {
 				makeRequest.SetText("Send")
 				makeRequest.Importance = widget.HighImportance
 				makeRequest.Refresh()
 				g.cancelRequest = nil
+				if progress != nil {
+					progress.Set(core.Progress{})
+				}
 			})
 
 			res, err := request.SendRequest(ctx)
@@ -395,17 +486,14 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 			}
 
 			// Cap what we keep: the binding holds the body for the tab's
-			// whole lifetime, and copy/raw only ever need this much.
-			// ponytail: 2MB cap; stream-to-file if full downloads matter.
+			// whole lifetime, and copy/raw only ever need this much. Full
+			// bodies of any size go through download mode instead.
 			const maxRetainedBody = 2 << 20
 			if len(res.Body) > maxRetainedBody {
-				res.Body = safeCut(res.Body, maxRetainedBody) + "\n\n... [Truncated: kept the first 2 MB of " + res.Size + "]"
+				res.Body = safeCut(res.Body, maxRetainedBody) + "\n\n... [Truncated: kept the first 2 MB of " + res.SizeText() + "]"
 			}
 
-			var headers []string
-			for name, value := range res.Headers {
-				headers = append(headers, name+"||"+value)
-			}
+			headers := headerRows(res)
 
 			var cookies []string
 			for _, c := range res.Cookies {
@@ -416,12 +504,21 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 			// Headers before body: the body listener reads Content-Type
 			// from the headers binding to pick syntax highlighting.
 			bindings.headers.Set(headers)
+			bindings.sorted.Set(res.Sorted)
 			bindings.cookies.Set(cookies)
+			bindings.tls.Set(tlsRows(res.TLS))
+			bindings.jwts.Set(jwtSources(headers, res.Body))
+			bindings.file.Set(res.File)
+			bindings.wire.Set(wireHead(res.Wire, maxRetainedBody))
+			bindings.rawLog.Set(res.RawLog)
 			bindings.body.Set(res.Body)
-			bindings.size.Set(res.Size)
+			bindings.size.Set(res.SizeText())
 			bindings.status.Set(res.Status)
+			bindings.proto.Set(protoSummary(res.Proto, res.TLS))
 			bindings.time.Set(res.Duration.Abs().String())
 			bindings.timings.Set(res.Timings)
+			bindings.attempts.Set(attemptRows(res.Attempts))
+			bindings.redirects.Set(redirectChain(res))
 
 			res.Body = ""
 
@@ -438,6 +535,12 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 			// every successful send — send is this app's "save" gesture.
 			g.syncCollectionEntry(request)
 
+			fyne.Do(func() {
+				if err := g.saveCookies(); err != nil {
+					dialog.NewError(err, *g.Window).Show()
+				}
+			})
+
 			// Updating the List
 			g.requestHistory = core.ListHistory()
 
@@ -448,7 +551,18 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 				// Comes to the top of the list which has a index of 0
 				g.requestList.Select(0)
 			})
-		}(g.requestCtx)
+		}(ctx)
+	}
+
+	makeRequest = widget.NewButton("Send", func() {
+		if makeRequest.Text == "Cancel" {
+			go func() {
+				g.cancelRequest()
+			}()
+			return
+		}
+
+		g.confirmUnresolved(request, send)
 	})
 
 	makeRequest.Importance = widget.HighImportance // Using it for button to have the theme color
@@ -466,13 +580,18 @@ func (g *gui) makeTab(request *core.Request) *container.TabItem {
 	})
 	addToColBtn.Importance = widget.LowImportance
 
-	// One visually fused bar: method + URL + save-to-collection + Send
+	fetchAllBtn := widget.NewButtonWithIcon("", theme.MediaFastForwardIcon(), func() {
+		g.confirmUnresolved(request, func() { g.fetchAllDialog(request) })
+	})
+	fetchAllBtn.Importance = widget.LowImportance
+
+	// One visually fused bar: method + URL + save-to-collection + fetch-all + Send
 	// share a rounded background
 	urlBarBg := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
 	urlBarBg.CornerRadius = 6
 	requestAction := container.NewPadded(container.NewStack(
 		urlBarBg,
-		container.NewBorder(nil, nil, requestType, container.NewHBox(addToColBtn, makeRequest), g.urlInput),
+		container.NewBorder(nil, nil, requestType, container.NewHBox(addToColBtn, fetchAllBtn, makeRequest), g.urlInput),
 	))
 
 	requestResponseContainer := container.NewStack(requestUI, response)
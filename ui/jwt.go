@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// jwtExpiryWarn is how close to exp a token counts as "expiring soon".
+const jwtExpiryWarn = 5 * time.Minute
+
+// jwtTimeRows renders iat/nbf/exp as "key||value" rows with a relative
+// hint, plus a Status row summarising validity at now.
+func jwtTimeRows(d *core.DecodedJWT, now time.Time) []string {
+	var rows []string
+	add := func(name string, t time.Time) {
+		if t.IsZero() {
+			return
+		}
+		rows = append(rows, name+"||"+t.Local().Format("2006-01-02 15:04:05 MST")+" ("+relativeTo(t, now)+")")
+	}
+	add("Issued At", d.IssuedAt)
+	add("Not Before", d.NotBefore)
+	add("Expires At", d.ExpiresAt)
+
+	switch {
+	case d.Expired(now):
+		rows = append(rows, "Status||Expired "+relativeTo(d.ExpiresAt, now))
+	case !d.NotBefore.IsZero() && now.Before(d.NotBefore):
+		rows = append(rows, "Status||Not yet valid")
+	case !d.ExpiresAt.IsZero() && d.ExpiresAt.Sub(now) < jwtExpiryWarn:
+		rows = append(rows, "Status||Expires "+relativeTo(d.ExpiresAt, now))
+	case !d.ExpiresAt.IsZero():
+		rows = append(rows, "Status||Valid")
+	default:
+		rows = append(rows, "Status||No expiry (exp not set)")
+	}
+
+	return rows
+}
+
+// relativeTo phrases t against now: "in 4m", "2h ago".
+func relativeTo(t, now time.Time) string {
+	d := t.Sub(now).Round(time.Second)
+	if d >= 0 {
+		return "in " + d.String()
+	}
+	return (-d).String() + " ago"
+}
+
+// jsonGrid shows v as highlighted, indented JSON.
+func jsonGrid(v any) *widget.TextGrid {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	grid := widget.NewTextGrid()
+	if rows := highlightGridRows(string(data), "json"); rows != nil {
+		grid.Rows = rows
+	} else {
+		grid.SetText(string(data))
+	}
+	return grid
+}
+
+// jwtDialog decodes token and shows header, claims and validity, with an
+// optional signature check against a secret, PEM key or JWKS.
+func (g *gui) jwtDialog(token string) {
+	d, err := core.DecodeJWT(token)
+	if err != nil {
+		dialog.NewError(err, *g.Window).Show()
+		return
+	}
+
+	timeRows := binding.NewStringList()
+	timeRows.Set(jwtTimeRows(d, time.Now()))
+
+	status := widget.NewLabel("")
+	status.Importance = widget.SuccessImportance
+	switch {
+	case d.Expired(time.Now()):
+		status.SetText("This token has expired.")
+		status.Importance = widget.DangerImportance
+	case !d.ExpiresAt.IsZero() && time.Until(d.ExpiresAt) < jwtExpiryWarn:
+		status.SetText("This token expires " + relativeTo(d.ExpiresAt, time.Now()) + ".")
+		status.Importance = widget.WarningImportance
+	default:
+		status.Hide()
+	}
+
+	verifyKey := widget.NewEntry()
+	verifyKey.MultiLine = true
+	verifyKey.SetMinRowsVisible(3)
+	verifyKey.TextStyle.Monospace = true
+	verifyKey.SetPlaceHolder("HMAC secret, PEM public key / certificate, JWKS JSON, or a path to one")
+
+	verifyResult := widget.NewLabel("")
+	verifyBtn := widget.NewButtonWithIcon("Verify Signature", theme.ConfirmIcon(), func() {
+		if err := core.VerifyJWT(d.Raw, core.ApplyEnv(verifyKey.Text)); err != nil {
+			verifyResult.SetText(err.Error())
+			verifyResult.Importance = widget.DangerImportance
+		} else {
+			verifyResult.SetText("Signature verified")
+			verifyResult.Importance = widget.SuccessImportance
+		}
+		verifyResult.Refresh()
+	})
+
+	pickKey := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close()
+			verifyKey.SetText(rc.URI().Path())
+		}, *g.Window)
+	})
+	pickKey.Importance = widget.LowImportance
+
+	gridBox := func(grid *widget.TextGrid) fyne.CanvasObject {
+		bg := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+		bg.CornerRadius = 6
+		return container.NewStack(bg, container.NewPadded(grid))
+	}
+
+	timesTable := keyValueTable(timeRows)
+	tableSpace := canvas.NewRectangle(nil)
+	tableSpace.SetMinSize(fyne.NewSize(0, 160))
+
+	content := container.NewVScroll(container.NewVBox(
+		status,
+		sectionHeader("Header"),
+		gridBox(jsonGrid(d.Header)),
+		sectionHeader("Claims"),
+		gridBox(jsonGrid(d.Claims)),
+		sectionHeader("Validity"),
+		container.NewStack(tableSpace, timesTable),
+		sectionHeader("Verify"),
+		container.NewBorder(nil, nil, nil, pickKey, verifyKey),
+		container.NewBorder(nil, nil, verifyBtn, nil, verifyResult),
+	))
+
+	dlg := dialog.NewCustom("JWT Decoder", "Close", content, *g.Window)
+	dlg.Resize(fyne.NewSize(640, 560))
+	dlg.Show()
+}
+
+// jwtSources lists the JWTs in a response: header values first (by header
+// name), then the body. Rows are "source||token".
+func jwtSources(headers []string, body string) []string {
+	const maxTokens = 20
+
+	var rows []string
+	for _, h := range headers {
+		k, v, _ := strings.Cut(h, "||")
+		for _, tok := range core.FindJWTs(v, maxTokens) {
+			rows = append(rows, k+"||"+tok)
+		}
+	}
+	for _, tok := range core.FindJWTs(body, maxTokens) {
+		rows = append(rows, "Body||"+tok)
+	}
+
+	if len(rows) > maxTokens {
+		rows = rows[:maxTokens]
+	}
+	return rows
+}
+
+// jwtList is the response view's JWT tab: one row per token found, with a
+// button into the decoder.
+func (g *gui) jwtList(tokens binding.StringList) fyne.CanvasObject {
+	rows, _ := tokens.Get()
+
+	list := widget.NewList(
+		func() int {
+			return len(rows)
+		},
+		func() fyne.CanvasObject {
+			source := widget.NewLabel("Authorization")
+			source.TextStyle.Bold = true
+			token := widget.NewLabel("token")
+			token.Truncation = fyne.TextTruncateEllipsis
+			decode := widget.NewButton("Decode", nil)
+			decode.Importance = widget.LowImportance
+
+			return container.NewBorder(nil, nil, source, decode, token)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			source, token, _ := strings.Cut(rows[i], "||")
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(token)
+			row.Objects[1].(*widget.Label).SetText(source)
+			row.Objects[2].(*widget.Button).OnTapped = func() {
+				g.jwtDialog(token)
+			}
+		},
+	)
+
+	empty := widget.NewLabel("No JWTs found in the response headers or body.")
+	empty.Importance = widget.LowImportance
+
+	tokens.AddListener(binding.NewDataListener(func() {
+		rows, _ = tokens.Get()
+		if len(rows) == 0 {
+			empty.Show()
+		} else {
+			empty.Hide()
+		}
+		list.Refresh()
+	}))
+
+	return container.NewStack(list, container.NewCenter(empty))
+}
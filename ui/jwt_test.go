@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vardanabhanot/myapi/core"
+)
+
+func TestJWTTimeRows(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	status := func(d *core.DecodedJWT) string {
+		rows := jwtTimeRows(d, now)
+		_, v, _ := strings.Cut(rows[len(rows)-1], "||")
+		return v
+	}
+
+	if got := status(&core.DecodedJWT{ExpiresAt: now.Add(-time.Minute)}); got != "Expired 1m0s ago" {
+		t.Errorf("expired: %q", got)
+	}
+	if got := status(&core.DecodedJWT{ExpiresAt: now.Add(2 * time.Minute)}); got != "Expires in 2m0s" {
+		t.Errorf("expiring soon: %q", got)
+	}
+	if got := status(&core.DecodedJWT{ExpiresAt: now.Add(time.Hour)}); got != "Valid" {
+		t.Errorf("valid: %q", got)
+	}
+	if got := status(&core.DecodedJWT{NotBefore: now.Add(time.Hour)}); got != "Not yet valid" {
+		t.Errorf("nbf: %q", got)
+	}
+	if got := status(&core.DecodedJWT{}); !strings.HasPrefix(got, "No expiry") {
+		t.Errorf("no exp: %q", got)
+	}
+}
+
+func TestJWTSources(t *testing.T) {
+	token, err := (&core.Auth{JWTKey: "k"}).SignJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := jwtSources([]string{"Authorization||Bearer " + token, "Content-Type||application/json"}, `{"t":"`+token+`"}`)
+	if len(rows) != 2 || rows[0] != "Authorization||"+token || rows[1] != "Body||"+token {
+		t.Fatalf("rows: %v", rows)
+	}
+}
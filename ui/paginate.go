@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// paginationForm edits how "fetch all pages" finds the next page. The
+// JSON path shows for the body modes, the cursor parameter for cursors.
+func (g *gui) paginationForm(p *core.Pagination, onChange func()) fyne.CanvasObject {
+	entry := func(value, placeholder string, set func(string)) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetPlaceHolder(placeholder)
+		e.SetText(value)
+		e.OnChanged = func(s string) {
+			set(s)
+			onChange()
+		}
+		return e
+	}
+	nextPath := entry(p.NextPath, "links.next or meta.cursor", func(s string) { p.NextPath = s })
+	cursorParam := entry(p.CursorParam, "cursor", func(s string) { p.CursorParam = s })
+	itemsPath := entry(p.ItemsPath, "Empty: the body, or its only array field", func(s string) { p.ItemsPath = s })
+	var pagesText string
+	if p.MaxPages > 0 {
+		pagesText = strconv.Itoa(p.MaxPages)
+	}
+	maxPages := entry(pagesText, "20", func(s string) { p.MaxPages, _ = strconv.Atoi(s) }) // invalid/empty → 0 → default
+
+	nextPathItem := widget.NewFormItem("Next JSON path", nextPath)
+	cursorItem := widget.NewFormItem("Cursor parameter", cursorParam)
+	form := widget.NewForm(nextPathItem, cursorItem,
+		widget.NewFormItem("Items JSON path", itemsPath),
+		widget.NewFormItem("Max pages", maxPages),
+	)
+
+	showFields := func(mode string) {
+		if mode == core.PageLink {
+			nextPath.Disable()
+		} else {
+			nextPath.Enable()
+		}
+		if mode == core.PageCursor {
+			cursorParam.Enable()
+		} else {
+			cursorParam.Disable()
+		}
+	}
+
+	mode := widget.NewSelect([]string{core.PageLink, core.PageNextURL, core.PageCursor}, nil)
+	mode.SetSelected(cmp.Or(p.Mode, core.PageLink))
+	showFields(mode.Selected)
+	mode.OnChanged = func(s string) {
+		p.Mode = s
+		if s == core.PageLink {
+			p.Mode = "" // the default, so untouched requests save nothing
+		}
+		showFields(s)
+		onChange()
+	}
+
+	hint := widget.NewLabel("Fetch all pages (beside Send) follows the next page until there is none or the limit is hit, and merges every page's JSON items into one array.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Next page from"), nil, mode),
+		form,
+		hint,
+	)
+}
+
+// pageRows lists fetched pages as "key||value" rows: "#2 · 200 OK" then
+// the timing, item count and URL.
+func pageRows(pages []core.Page) []string {
+	var rows []string
+	for i, p := range pages {
+		key := fmt.Sprintf("#%d · %s", i+1, cmp.Or(p.Status, "Failed"))
+		value := fmt.Sprintf("%s · %d items · %s", p.Duration.Round(time.Millisecond), p.Items, p.URL)
+		if p.Status == "" {
+			value = p.URL
+		}
+		if p.Err != "" {
+			value += " — " + p.Err
+		}
+		rows = append(rows, key+"||"+value)
+	}
+	return rows
+}
+
+// fetchAllDialog runs FetchAll for request, listing pages as they land,
+// then shows the merged items. Closing the dialog cancels the walk.
+func (g *gui) fetchAllDialog(request *core.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var pages []core.Page
+	rows := binding.NewStringList()
+	status := widget.NewLabel("Fetching page 1…")
+	status.Wrapping = fyne.TextWrapWord
+	busy := widget.NewProgressBarInfinite()
+
+	itemsGrid := widget.NewTextGrid()
+	itemsGrid.ShowLineNumbers = true
+	itemsGrid.Scroll = fyne.ScrollBoth
+	var items string // unwrapped, for copy and save
+
+	saveBtn := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		if items == "" {
+			return
+		}
+		fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if _, err := writer.Write([]byte(items)); err != nil {
+				dialog.NewError(err, *g.Window).Show()
+			}
+		}, *g.Window)
+		fileSave.SetFileName("all-pages.json")
+		fileSave.Show()
+	})
+	saveBtn.Importance = widget.LowImportance
+
+	itemsTab := container.NewTabItem("Items", container.NewBorder(
+		container.NewBorder(nil, nil, nil, container.NewHBox(copyFeedbackButton(func() string { return items }), saveBtn)),
+		nil, nil, nil,
+		itemsGrid,
+	))
+	tabs := container.NewAppTabs(container.NewTabItem("Pages", keyValueTable(rows)), itemsTab)
+
+	d := dialog.NewCustom("Fetch All Pages", "Close", container.NewBorder(container.NewVBox(status, busy), nil, nil, nil, tabs), *g.Window)
+	d.SetOnClosed(cancel)
+	d.Resize(fyne.NewSize(820, 520))
+	d.Show()
+
+	go func() {
+		result, err := request.FetchAll(ctx, func(p core.Page) {
+			pages = append(pages, p)
+			rows.Set(pageRows(pages))
+			fyne.Do(func() { status.SetText(fmt.Sprintf("Fetching page %d…", len(pages)+1)) })
+		})
+
+		fyne.Do(func() {
+			busy.Stop()
+			busy.Hide()
+			if err != nil {
+				status.SetText("Failed: " + err.Error())
+				return
+			}
+
+			total := 0
+			for _, p := range result.Pages {
+				total += p.Items
+			}
+			status.SetText(fmt.Sprintf("%d items from %d pages — %s", total, len(result.Pages), result.Stop))
+
+			items = result.Items
+			if r := highlightGridRows(items, "json"); r != nil {
+				itemsGrid.Rows = r
+				itemsGrid.Refresh()
+			} else {
+				itemsGrid.SetText(softWrap(items))
+			}
+			tabs.Select(itemsTab)
+		})
+
+		// The first page went to history like a send
+		g.requestHistory = core.ListHistory()
+		fyne.Do(g.requestList.Refresh)
+	}()
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vardanabhanot/myapi/core"
+)
+
+func TestPageRows(t *testing.T) {
+	rows := pageRows([]core.Page{
+		{URL: "https://a.test/items", Status: "200 OK", Items: 20, Duration: 1234567 * time.Nanosecond},
+		{URL: "https://a.test/items?page=2", Status: "503 Service Unavailable", Duration: 5 * time.Millisecond, Err: "answered 503 Service Unavailable"},
+		{URL: "https://a.test/items?page=3", Err: "connection refused"},
+	})
+	want := []string{
+		"#1 · 200 OK||1ms · 20 items · https://a.test/items",
+		"#2 · 503 Service Unavailable||5ms · 0 items · https://a.test/items?page=2 — answered 503 Service Unavailable",
+		"#3 · Failed||https://a.test/items?page=3 — connection refused",
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("rows:\n%q\nwant\n%q", rows, want)
+	}
+}
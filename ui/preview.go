@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// previewPanel shows request as it will go out — placeholders resolved,
+// generated headers in, secrets masked unless unticked. refresh rebuilds
+// it from the request's current state.
+func (g *gui) previewPanel(request *core.Request) (panel fyne.CanvasObject, refresh func()) {
+	text := widget.NewTextGrid()
+	text.ShowLineNumbers = true
+	text.Scroll = fyne.ScrollBoth
+	var raw string // unwrapped, for copying
+
+	maskCheck := widget.NewCheck("Mask secrets", nil)
+	maskCheck.SetChecked(true)
+
+	refresh = func() {
+		var err error
+		if raw, err = request.Preview(maskCheck.Checked, g.secretValues()); err != nil {
+			raw = "Can't build the request: " + err.Error()
+		}
+		text.SetText(softWrap(raw))
+	}
+	maskCheck.OnChanged = func(bool) { refresh() }
+
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), refresh)
+	refreshBtn.Importance = widget.LowImportance
+
+	bg := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+	bg.CornerRadius = 6
+
+	panel = container.NewPadded(container.NewBorder(
+		container.NewBorder(nil, nil, maskCheck, container.NewHBox(refreshBtn, copyFeedbackButton(func() string { return raw }))),
+		nil, nil, nil,
+		container.NewStack(bg, container.NewPadded(text)),
+	))
+	return panel, refresh
+}
+
+// secretValues lists the secret variables' values in every scope.
+func (g *gui) secretValues() []string {
+	var env *[]core.FormType
+	if active := g.envStore.ActiveEnv(); active != nil {
+		env = active.Variables
+	}
+	return core.SecretValues(g.envStore.Globals, env, g.sessionVars)
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// applyProxy publishes the proxy for requests without their own: the
+// active environment's when it sets one, else the global one.
+func (g *gui) applyProxy() {
+	if env := g.envStore.ActiveEnv(); env != nil && env.Proxy.IsSet() {
+		core.SetProxy(env.Proxy)
+		return
+	}
+
+	core.SetProxy(g.globalProxy)
+}
+
+// proxyForm edits a ProxyConfig in place. fallback names what an empty
+// URL defers to; onChange runs after every edit.
+func (g *gui) proxyForm(p *core.ProxyConfig, fallback string, onChange func()) fyne.CanvasObject {
+	entry := func(value, placeholder string, set func(string)) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetPlaceHolder(placeholder)
+		e.SetText(value)
+		e.OnChanged = func(s string) {
+			set(s)
+			onChange()
+		}
+		return e
+	}
+
+	proxyURL := entry(p.URL, "Empty: "+fallback, func(s string) { p.URL = s })
+	user := entry(p.User, "Optional", func(s string) { p.User = s })
+	bypass := entry(p.Bypass, "localhost, .internal.example.com, 10.0.0.0/8", func(s string) { p.Bypass = s })
+
+	pass := widget.NewPasswordEntry()
+	pass.SetPlaceHolder("Optional, {{var}} keeps it in the vault")
+	pass.SetText(p.Pass)
+	pass.OnChanged = func(s string) {
+		p.Pass = s
+		onChange()
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Proxy URL", proxyURL),
+		widget.NewFormItem("Username", user),
+		widget.NewFormItem("Password", pass),
+		widget.NewFormItem("Bypass", bypass),
+	)
+
+	direct := widget.NewCheck("No proxy: connect directly, ignoring "+fallback, func(b bool) {
+		p.Direct = b
+		if b {
+			form.Hide()
+		} else {
+			form.Show()
+		}
+		onChange()
+	})
+	direct.SetChecked(p.Direct)
+	if p.Direct {
+		form.Hide()
+	}
+
+	hint := widget.NewLabel("http://, https:// or socks5://host:port. Bypass takes hosts, .domains and CIDRs, comma-separated.")
+	hint.Importance = widget.LowImportance
+	hint.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(direct, form, hint)
+}
+
+// globalProxyDialog edits the proxy every environment and request falls
+// back to.
+func (g *gui) globalProxyDialog() {
+	p := g.globalProxy
+
+	d := dialog.NewCustomConfirm("Global Proxy", "Save", "Cancel", g.proxyForm(&p, "the system proxy (HTTP_PROXY)", func() {}), func(ok bool) {
+		if !ok {
+			return
+		}
+
+		g.globalProxy = p
+		g.applyProxy()
+
+		if err := core.SaveGlobalProxy(p); err != nil {
+			dialog.NewError(err, *g.Window).Show()
+		}
+	}, *g.Window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// envProxyDialog edits an environment's proxy; the env dialog persists it.
+func (g *gui) envProxyDialog(env *core.Environment) {
+	p := env.Proxy
+
+	d := dialog.NewCustomConfirm("Proxy — "+env.Name, "Done", "Cancel", g.proxyForm(&p, "the global proxy", func() {}), func(ok bool) {
+		if ok {
+			env.Proxy = p
+		}
+	}, *g.Window)
+	d.Resize(fyne.NewSize(520, 0))
+	d.Show()
+}
+
+// globalProxyButton opens globalProxyDialog; the request Settings tab and
+// the environment dialog both offer it.
+func (g *gui) globalProxyButton() *widget.Button {
+	b := widget.NewButtonWithIcon("Global Proxy", theme.SettingsIcon(), g.globalProxyDialog)
+	b.Importance = widget.LowImportance
+	return b
+}
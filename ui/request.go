@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"cmp"
 	"image/color"
 	"log"
 	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -18,10 +21,11 @@ import (
 )
 
 type bodyOptHolder struct {
-	json fyne.CanvasObject
-	xml  fyne.CanvasObject
-	text fyne.CanvasObject
-	form fyne.CanvasObject
+	json   fyne.CanvasObject
+	xml    fyne.CanvasObject
+	text   fyne.CanvasObject
+	form   fyne.CanvasObject
+	binary fyne.CanvasObject
 }
 
 func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
@@ -32,8 +36,18 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 	bindings.time = binding.BindString(&resTime)
 	bindings.body = binding.BindString(&bodyResponse)
 	bindings.headers = binding.NewStringList()
+	bindings.sorted = binding.NewBool()
 	bindings.cookies = binding.NewStringList()
+	bindings.tls = binding.NewStringList()
+	bindings.jwts = binding.NewStringList()
 	bindings.timings = binding.NewUntyped()
+	bindings.progress = binding.NewUntyped()
+	bindings.file = binding.NewString()
+	bindings.proto = binding.NewString()
+	bindings.attempts = binding.NewStringList()
+	bindings.redirects = binding.NewUntyped()
+	bindings.wire = binding.NewUntyped()
+	bindings.rawLog = binding.NewString()
 
 	// Query options
 	if request.QueryParams == nil {
@@ -65,7 +79,6 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		// Default Header Options
 		*request.Headers = append(*request.Headers, core.FormType{Key: "Accept", Value: "*/*", Checked: true})
 		*request.Headers = append(*request.Headers, core.FormType{Key: "User-Agent", Value: "MyAPI/" + appversion, Checked: true})
-		//*request.Headers = append(*request.Headers, core.FormType{Key: "Accept-Encoding", Value: "gzip, deflate, br", Checked: true})
 		*request.Headers = append(*request.Headers, core.FormType{Key: "Connection", Value: "keep-alive", Checked: true})
 	}
 
@@ -96,9 +109,9 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 	authViews := map[string]fyne.CanvasObject{}
 	authViews["None"] = container.NewVBox(widget.NewLabel("No Authentication Selected"))
 
-	basicUsername := widget.NewEntry()
+	basicUsername := g.newAppEntry()
 	basicUsername.SetPlaceHolder("Username")
-	basicPassword := widget.NewEntry()
+	basicPassword := g.newAppEntry()
 	basicPassword.SetPlaceHolder("Password")
 	basicPassword.Password = true
 	basicHeading := sectionHeader("Basic Authentication")
@@ -126,7 +139,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		nil,
 		container.NewVBox(
 			container.NewBorder(nil, nil, widget.NewLabel("Username"), nil, basicUsername),
-			container.NewBorder(nil, nil, widget.NewLabel("Password"), nil, basicPassword),
+			container.NewBorder(nil, nil, widget.NewLabel("Password"), nil, basicPassword.withBadge()),
 		),
 	)
 
@@ -143,7 +156,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 	}
 
 	bearerHeading := sectionHeader("Bearer Authentication")
-	bearerTokenArea := widget.NewEntry()
+	bearerTokenArea := g.newAppEntry()
 	bearerTokenArea.MultiLine = true
 	bearerTokenArea.SetMinRowsVisible(5)
 	bearerTokenArea.Scroll = fyne.ScrollVerticalOnly
@@ -158,26 +171,31 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		request.Auth.BearerAuth = s
 	}
 
+	bearerDecode := widget.NewButtonWithIcon("Decode", theme.SearchIcon(), func() {
+		g.jwtDialog(core.ApplyEnv(bearerTokenArea.Text))
+	})
+	bearerDecode.Importance = widget.LowImportance
+
 	authViews["Bearer"] = container.NewBorder(
-		bearerHeading,
+		container.NewBorder(nil, nil, bearerHeading, bearerDecode),
 		nil,
 		nil,
 		nil,
 		container.NewVBox(
-			bearerTokenArea,
+			bearerTokenArea.withBadge(),
 			container.NewBorder(nil, nil, widget.NewLabel("Token Prefix"), nil, bearerPrefix),
 		),
 	)
 
 	// API Key
-	apiKeyName := widget.NewEntry()
+	apiKeyName := g.newAppEntry()
 	apiKeyName.SetPlaceHolder("X-API-Key")
 	apiKeyName.SetText(request.Auth.APIKeyName)
 	apiKeyName.OnChanged = func(s string) {
 		request.Auth.APIKeyName = s
 	}
 
-	apiKeyValue := widget.NewEntry()
+	apiKeyValue := g.newAppEntry()
 	apiKeyValue.SetPlaceHolder("Value")
 	apiKeyValue.SetText(request.Auth.APIKeyValue)
 	apiKeyValue.OnChanged = func(s string) {
@@ -200,33 +218,33 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		nil,
 		container.NewVBox(
 			container.NewBorder(nil, nil, widget.NewLabel("Key"), nil, apiKeyName),
-			container.NewBorder(nil, nil, widget.NewLabel("Value"), nil, apiKeyValue),
+			container.NewBorder(nil, nil, widget.NewLabel("Value"), nil, apiKeyValue.withBadge()),
 			container.NewBorder(nil, nil, widget.NewLabel("Add to"), nil, apiKeyIn),
 		),
 	)
 
 	// OAuth2 (client credentials); token fetched and cached at send time
-	oauthTokenURL := widget.NewEntry()
+	oauthTokenURL := g.newAppEntry()
 	oauthTokenURL.SetPlaceHolder("https://auth.example.com/oauth/token")
 	oauthTokenURL.SetText(request.Auth.OAuthTokenURL)
 	oauthTokenURL.OnChanged = func(s string) {
 		request.Auth.OAuthTokenURL = s
 	}
 
-	oauthClientID := widget.NewEntry()
+	oauthClientID := g.newAppEntry()
 	oauthClientID.SetText(request.Auth.OAuthClientID)
 	oauthClientID.OnChanged = func(s string) {
 		request.Auth.OAuthClientID = s
 	}
 
-	oauthClientSecret := widget.NewEntry()
+	oauthClientSecret := g.newAppEntry()
 	oauthClientSecret.Password = true
 	oauthClientSecret.SetText(request.Auth.OAuthClientSecret)
 	oauthClientSecret.OnChanged = func(s string) {
 		request.Auth.OAuthClientSecret = s
 	}
 
-	oauthScope := widget.NewEntry()
+	oauthScope := g.newAppEntry()
 	oauthScope.SetPlaceHolder("Optional, space separated")
 	oauthScope.SetText(request.Auth.OAuthScope)
 	oauthScope.OnChanged = func(s string) {
@@ -241,11 +259,121 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		container.NewVBox(
 			container.NewBorder(nil, nil, widget.NewLabel("Token URL"), nil, oauthTokenURL),
 			container.NewBorder(nil, nil, widget.NewLabel("Client ID"), nil, oauthClientID),
-			container.NewBorder(nil, nil, widget.NewLabel("Client Secret"), nil, oauthClientSecret),
+			container.NewBorder(nil, nil, widget.NewLabel("Client Secret"), nil, oauthClientSecret.withBadge()),
 			container.NewBorder(nil, nil, widget.NewLabel("Scope"), nil, oauthScope),
 		),
 	)
 
+	// JWT: signed fresh on every send; exp/iat/nbf/auth_time, and any named
+	// time claims, may be "now+5m" etc.
+	jwtAlg := widget.NewSelect(core.JWTAlgorithms, func(s string) {
+		request.Auth.JWTAlgorithm = s
+	})
+	if request.Auth.JWTAlgorithm != "" {
+		jwtAlg.SetSelected(request.Auth.JWTAlgorithm)
+	} else {
+		jwtAlg.SetSelected("HS256")
+	}
+
+	jwtKey := g.newAppEntry()
+	jwtKey.MultiLine = true
+	jwtKey.SetMinRowsVisible(3)
+	jwtKey.TextStyle.Monospace = true
+	jwtKey.SetPlaceHolder("HS256 secret, or PEM private key / path to one for RS256 and ES256")
+	jwtKey.SetText(request.Auth.JWTKey)
+	jwtKey.OnChanged = func(s string) {
+		request.Auth.JWTKey = s
+	}
+
+	jwtHeader := g.newAppEntry()
+	jwtHeader.MultiLine = true
+	jwtHeader.SetMinRowsVisible(2)
+	jwtHeader.TextStyle.Monospace = true
+	jwtHeader.SetPlaceHolder(`{"kid": "key-1"}`)
+	jwtHeader.SetText(request.Auth.JWTHeader)
+	jwtHeader.OnChanged = func(s string) {
+		request.Auth.JWTHeader = s
+	}
+
+	jwtClaims := g.newAppEntry()
+	jwtClaims.MultiLine = true
+	jwtClaims.SetMinRowsVisible(4)
+	jwtClaims.TextStyle.Monospace = true
+	jwtClaims.SetPlaceHolder(`{"sub": "{{userId}}", "iat": "now", "exp": "now+5m"}`)
+	jwtClaims.SetText(request.Auth.JWTClaims)
+	jwtClaims.OnChanged = func(s string) {
+		request.Auth.JWTClaims = s
+	}
+
+	jwtTimeClaims := widget.NewEntry()
+	jwtTimeClaims.SetPlaceHolder("Besides exp, nbf, iat, auth_time: refresh_at, …")
+	jwtTimeClaims.SetText(request.Auth.JWTTimeClaims)
+	jwtTimeClaims.OnChanged = func(s string) {
+		request.Auth.JWTTimeClaims = s
+	}
+
+	jwtName := g.newAppEntry()
+	jwtName.SetText(request.Auth.JWTName)
+	jwtName.OnChanged = func(s string) {
+		request.Auth.JWTName = s
+	}
+
+	jwtPrefix := widget.NewEntry()
+	jwtPrefix.SetPlaceHolder("Bearer")
+	jwtPrefix.SetText(request.Auth.JWTPrefix)
+	jwtPrefix.OnChanged = func(s string) {
+		request.Auth.JWTPrefix = s
+	}
+	jwtPrefixRow := container.NewBorder(nil, nil, widget.NewLabel("Prefix"), nil, jwtPrefix)
+
+	jwtIn := widget.NewSelect([]string{"Header", "Query"}, func(s string) {
+		request.Auth.JWTIn = s
+		if s == "Query" {
+			jwtName.SetPlaceHolder("access_token")
+			jwtPrefixRow.Hide()
+		} else {
+			jwtName.SetPlaceHolder("Authorization")
+			jwtPrefixRow.Show()
+		}
+	})
+	if request.Auth.JWTIn != "" {
+		jwtIn.SetSelected(request.Auth.JWTIn)
+	} else {
+		jwtIn.SetSelected("Header")
+	}
+
+	jwtPreview := widget.NewButtonWithIcon("Preview", theme.SearchIcon(), func() {
+		token, err := request.Auth.SignJWT()
+		if err != nil {
+			dialog.NewError(err, *g.Window).Show()
+			return
+		}
+		g.jwtDialog(token)
+	})
+	jwtPreview.Importance = widget.LowImportance
+
+	authViews["JWT"] = container.NewBorder(
+		container.NewBorder(nil, nil, sectionHeader("JWT — signed on every send"), jwtPreview),
+		nil,
+		nil,
+		nil,
+		container.NewVScroll(container.NewVBox(
+			container.NewBorder(nil, nil, widget.NewLabel("Algorithm"), nil, jwtAlg),
+			widget.NewLabel("Key"),
+			jwtKey.withBadge(),
+			widget.NewLabel("Header"),
+			jwtHeader,
+			widget.NewLabel("Claims"),
+			jwtClaims,
+			container.NewBorder(nil, nil, widget.NewLabel("Time claims"), nil, jwtTimeClaims),
+			container.NewGridWithColumns(2,
+				container.NewBorder(nil, nil, widget.NewLabel("Add to"), nil, jwtIn),
+				container.NewBorder(nil, nil, widget.NewLabel("Name"), nil, jwtName),
+			),
+			jwtPrefixRow,
+		)),
+	)
+
 	authOptionView := container.NewStack()
 	for _, view := range authViews {
 		view.Hide()
@@ -253,7 +381,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 	}
 
 	// TODO:: Will need to implement AWS as well here
-	authOptions := widget.NewRadioGroup([]string{"None", "Basic", "Bearer", "API Key", "OAuth2"}, func(value string) {
+	authOptions := widget.NewRadioGroup([]string{"None", "Basic", "Bearer", "API Key", "OAuth2", "JWT"}, func(value string) {
 		request.AuthType = value
 
 		for name, view := range authViews {
@@ -274,8 +402,24 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		authOptions.SetSelected("None")
 	}
 
+	// Secret credentials are masked here and kept in the vault instead of
+	// history and collections; Basic password and client secret always are.
+	maskable := []*appEntry{bearerTokenArea, apiKeyValue, jwtKey}
+	maskCredentials := func(secret bool) {
+		for _, e := range maskable {
+			e.Password = secret
+			e.Refresh()
+		}
+	}
+
+	authSecret := widget.NewCheck("Keep credentials in the vault, out of history and collections", func(b bool) {
+		request.Auth.Secret = b
+		maskCredentials(b)
+	})
+	authSecret.SetChecked(request.Auth.Secret)
+
 	authContainer := container.NewPadded(
-		container.NewBorder(authOptions, nil, nil, nil, authOptionView),
+		container.NewBorder(authOptions, authSecret, nil, nil, authOptionView),
 	)
 
 	// Body Options
@@ -325,7 +469,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		*request.Body.Form = append(*request.Body.Form, core.FormType{Checked: true})
 	}
 
-	formFieldsBlock := g.formBlock(request.Body.Form)
+	formFieldsBlock := g.formBlock(request.Body.Form, false)
 
 	formHeading := sectionHeader("Form Fields")
 
@@ -351,35 +495,48 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		formContainer,
 	)
 
+	// Binary: one file streamed as the whole body
+	binaryType := widget.NewEntry()
+	binaryType.SetPlaceHolder("Guessed from the file extension")
+	binaryType.SetText(request.Body.BinaryType)
+	binaryType.OnChanged = func(s string) {
+		request.Body.BinaryType = s
+	}
+
+	binaryHint := widget.NewLabel("The file is read at send time, so it can be any size. {{variables}} work in the path.")
+	binaryHint.Importance = widget.LowImportance
+	binaryHint.Wrapping = fyne.TextWrapWord
+
+	bodyOptIns.binary = container.NewVBox(
+		g.pathEntry("File", request.Body.Binary, "/path/to/file or {{var}}", func(s string) {
+			request.Body.Binary = s
+		}),
+		container.NewBorder(nil, nil, widget.NewLabel("Content-Type"), nil, binaryType),
+		binaryHint,
+	)
+
 	// "Form" is multipart; "URL Encoded" shares the same key/value rows and
 	// only changes how the body is encoded at send time.
-	bodyOptions := widget.NewRadioGroup([]string{"JSON", "Form", "URL Encoded", "XML", "Text"}, func(value string) {
+	bodyOptions := widget.NewRadioGroup([]string{"JSON", "Form", "URL Encoded", "XML", "Text", "Binary"}, func(value string) {
 		request.BodyType = value
 
+		bodyOptIns.json.Hide()
+		bodyOptIns.xml.Hide()
+		bodyOptIns.text.Hide()
+		bodyOptIns.form.Hide()
+		bodyOptIns.binary.Hide()
+
 		switch value {
 		case "JSON":
 			bodyOptIns.json.Show()
-			bodyOptIns.xml.Hide()
-			bodyOptIns.text.Hide()
-			bodyOptIns.form.Hide()
-
 		case "Form", "URL Encoded":
 			bodyOptIns.form.Show()
-			bodyOptIns.json.Hide()
-			bodyOptIns.xml.Hide()
-			bodyOptIns.text.Hide()
-
 		case "XML":
 			bodyOptIns.xml.Show()
-			bodyOptIns.json.Hide()
-			bodyOptIns.text.Hide()
-			bodyOptIns.form.Hide()
-
 		case "Text":
 			bodyOptIns.text.Show()
-			bodyOptIns.json.Hide()
-			bodyOptIns.xml.Hide()
-			bodyOptIns.form.Hide()
+		case "Binary":
+			bodyOptIns.binary.Show()
 		}
 	})
 
@@ -394,6 +551,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		bodyOptIns.xml,
 		bodyOptIns.text,
 		bodyOptIns.form,
+		bodyOptIns.binary,
 	)
 
 	bodyContainer := container.NewPadded(
@@ -412,11 +570,41 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		request.IsDirty = true
 	}
 
+	maxRedirectsEntry := widget.NewEntry()
+	maxRedirectsEntry.SetPlaceHolder("10")
+	if request.Settings.MaxRedirects > 0 {
+		maxRedirectsEntry.SetText(strconv.Itoa(request.Settings.MaxRedirects))
+	}
+	maxRedirectsEntry.OnChanged = func(s string) {
+		request.Settings.MaxRedirects, _ = strconv.Atoi(s) // invalid/empty → 0 → default
+		request.IsDirty = true
+	}
+
+	keepAuthCheck := widget.NewCheck("Keep Authorization when redirected to another host", nil)
+	keepAuthCheck.SetChecked(request.Settings.KeepAuthOnRedirect)
+	keepAuthCheck.OnChanged = func(b bool) {
+		request.Settings.KeepAuthOnRedirect = b
+		request.IsDirty = true
+	}
+
+	redirectOptions := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Max redirects"), nil, maxRedirectsEntry),
+		keepAuthCheck,
+	)
+	if request.Settings.NoFollowRedirects {
+		redirectOptions.Hide()
+	}
+
 	redirectCheck := widget.NewCheck("Don't follow redirects", nil)
 	redirectCheck.SetChecked(request.Settings.NoFollowRedirects)
 	redirectCheck.OnChanged = func(b bool) {
 		request.Settings.NoFollowRedirects = b
 		request.IsDirty = true
+		if b {
+			redirectOptions.Hide()
+		} else {
+			redirectOptions.Show()
+		}
 	}
 
 	tlsCheck := widget.NewCheck("Skip TLS certificate verification", nil)
@@ -426,12 +614,179 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 		request.IsDirty = true
 	}
 
-	settingsContainer := container.NewPadded(container.NewVBox(
+	strictCheck := widget.NewCheck("Fail the send on unresolved {{variables}} instead of sending them literally", nil)
+	strictCheck.SetChecked(request.Settings.StrictVars)
+	strictCheck.OnChanged = func(b bool) {
+		request.Settings.StrictVars = b
+		request.IsDirty = true
+	}
+
+	// Protocol: "Auto" stores as "" so old requests keep negotiating
+	const protoAuto = "Auto (HTTP/2 when offered)"
+	protocolSelect := widget.NewSelect([]string{protoAuto, core.ProtoHTTP1, core.ProtoHTTP2, core.ProtoHTTP3}, nil)
+	protocolSelect.SetSelected(cmp.Or(request.Settings.Protocol, protoAuto))
+	protocolSelect.OnChanged = func(s string) {
+		if s == protoAuto {
+			s = ""
+		}
+		request.Settings.Protocol = s
+		request.IsDirty = true
+	}
+	protocolHint := widget.NewLabel("HTTP/2 on an http:// URL uses h2c with prior knowledge; HTTP/3 needs https://.")
+	protocolHint.Importance = widget.LowImportance
+	protocolHint.Wrapping = fyne.TextWrapWord
+
+	// Proxy: empty falls back to the environment's, then the global one
+	proxyForm := g.proxyForm(&request.Settings.Proxy, "the environment or global proxy", func() {
+		request.IsDirty = true
+	})
+
+	// DNS: host overrides and resolver, layered over the environment's
+	dnsForm := g.dnsForm(&request.Settings.DNS, func() {
+		request.IsDirty = true
+	})
+
+	// Connections: pooled and kept alive across sends unless this request
+	// asks for a fresh one. Empty limits keep Go's defaults.
+	intEntry := func(value int, placeholder string, set func(int)) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetPlaceHolder(placeholder)
+		if value > 0 {
+			e.SetText(strconv.Itoa(value))
+		}
+		e.OnChanged = func(s string) {
+			n, _ := strconv.Atoi(s) // invalid/empty → 0 → default
+			set(n)
+			request.IsDirty = true
+		}
+		return e
+	}
+	maxIdleEntry := intEntry(request.Settings.MaxIdlePerHost, "2", func(n int) { request.Settings.MaxIdlePerHost = n })
+	idleTimeoutEntry := intEntry(request.Settings.IdleTimeoutSec, "90", func(n int) { request.Settings.IdleTimeoutSec = n })
+
+	poolForm := widget.NewForm(
+		widget.NewFormItem("Idle connections per host", maxIdleEntry),
+		widget.NewFormItem("Idle timeout (seconds)", idleTimeoutEntry),
+	)
+	freshCheck := widget.NewCheck("Always open a fresh connection (no keep-alive reuse)", nil)
+	freshCheck.SetChecked(request.Settings.FreshConnection)
+	if request.Settings.FreshConnection {
+		poolForm.Hide()
+	}
+	freshCheck.OnChanged = func(b bool) {
+		request.Settings.FreshConnection = b
+		request.IsDirty = true
+		if b {
+			poolForm.Hide()
+		} else {
+			poolForm.Show()
+		}
+	}
+
+	// Retries: attempts counts the first try, so 0 or 1 sends once
+	retry := &request.Settings.Retry
+	attemptsEntry := intEntry(retry.MaxAttempts, "1", func(n int) { retry.MaxAttempts = n })
+	baseDelayEntry := intEntry(retry.BaseDelayMs, "500", func(n int) { retry.BaseDelayMs = n })
+	maxDelayEntry := intEntry(retry.MaxDelayMs, "30000", func(n int) { retry.MaxDelayMs = n })
+
+	statusesEntry := widget.NewEntry()
+	statusesEntry.SetPlaceHolder("408, 429, 500, 502-504")
+	statusesEntry.SetText(retry.Statuses)
+	statusesEntry.OnChanged = func(s string) {
+		retry.Statuses = s
+		request.IsDirty = true
+	}
+
+	retryErrorsCheck := widget.NewCheck("Also retry connection errors and timeouts", nil)
+	retryErrorsCheck.SetChecked(!retry.StatusOnly)
+	retryErrorsCheck.OnChanged = func(b bool) {
+		retry.StatusOnly = !b
+		request.IsDirty = true
+	}
+
+	retryForm := widget.NewForm(
+		widget.NewFormItem("Max attempts", attemptsEntry),
+		widget.NewFormItem("Retry on statuses", statusesEntry),
+		widget.NewFormItem("First delay (ms)", baseDelayEntry),
+		widget.NewFormItem("Max delay (ms)", maxDelayEntry),
+	)
+	retryHint := widget.NewLabel("The delay doubles per retry with random jitter; a Retry-After header replaces it. Both stop at the max delay.")
+	retryHint.Importance = widget.LowImportance
+	retryHint.Wrapping = fyne.TextWrapWord
+
+	closeConnsBtn := widget.NewButtonWithIcon("Close Idle Connections", theme.ViewRefreshIcon(), core.CloseIdleConnections)
+	closeConnsBtn.Importance = widget.LowImportance
+
+	// Client certificate for mutual TLS; when left empty the host store
+	// (shared across requests) is consulted at send time.
+	certForm := g.certForm(&request.Settings.ClientCert, func() {
+		request.IsDirty = true
+	})
+
+	hostCertsBtn := widget.NewButtonWithIcon("Host Certificates", theme.SettingsIcon(), g.hostCertsDialog)
+	hostCertsBtn.Importance = widget.LowImportance
+
+	paginationForm := g.paginationForm(&request.Settings.Pagination, func() {
+		request.IsDirty = true
+	})
+
+	// Download mode: the body streams to a file instead of into memory.
+	downloadPath := g.savePathEntry("Save to", request.Settings.DownloadPath, "Empty: temp folder, named after the URL", func(s string) {
+		request.Settings.DownloadPath = s
+		request.IsDirty = true
+	})
+
+	resumeCheck := widget.NewCheck("Resume a partial file with a Range request", nil)
+	resumeCheck.SetChecked(request.Settings.ResumeDownload)
+	resumeCheck.OnChanged = func(b bool) {
+		request.Settings.ResumeDownload = b
+		request.IsDirty = true
+	}
+
+	downloadOptions := container.NewVBox(downloadPath, resumeCheck)
+	downloadCheck := widget.NewCheck("Stream the response body to a file", nil)
+	downloadCheck.OnChanged = func(b bool) {
+		request.Settings.Download = b
+		request.IsDirty = true
+		if b {
+			downloadOptions.Show()
+		} else {
+			downloadOptions.Hide()
+		}
+	}
+	downloadCheck.SetChecked(request.Settings.Download)
+	if !request.Settings.Download {
+		downloadOptions.Hide()
+	}
+
+	settingsContainer := container.NewPadded(container.NewVScroll(container.NewVBox(
 		sectionHeader("Request Settings"),
 		container.NewBorder(nil, nil, widget.NewLabel("Timeout (seconds)"), nil, timeoutEntry),
 		redirectCheck,
+		redirectOptions,
 		tlsCheck,
-	))
+		strictCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Protocol"), nil, protocolSelect),
+		protocolHint,
+		container.NewBorder(nil, nil, sectionHeader("Proxy"), g.globalProxyButton()),
+		proxyForm,
+		sectionHeader("Retries"),
+		retryForm,
+		retryErrorsCheck,
+		retryHint,
+		sectionHeader("Hosts & DNS"),
+		dnsForm,
+		container.NewBorder(nil, nil, sectionHeader("Connections"), closeConnsBtn),
+		freshCheck,
+		poolForm,
+		container.NewBorder(nil, nil, sectionHeader("Client Certificate"), hostCertsBtn),
+		certForm,
+		sectionHeader("Download"),
+		downloadCheck,
+		downloadOptions,
+		sectionHeader("Pagination"),
+		paginationForm,
+	)))
 
 	// Code Gen drawer
 	var codePreviewContainer *fyne.Container
@@ -465,37 +820,56 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 			container.NewPadded(codePreview)),
 	))
 
-	codeGenTitle := sectionHeader("Code Generator")
+	// Drawers on the right edge, hidden by default, one open at a time; the
+	// spacer fixes their width
+	newDrawer := func(title string, content fyne.CanvasObject) *fyne.Container {
+		spacer := canvas.NewRectangle(color.Transparent)
+		spacer.SetMinSize(fyne.NewSize(380, 0))
+		drawer := container.NewStack(
+			spacer,
+			container.NewBorder(nil, nil, widget.NewSeparator(), nil,
+				container.NewBorder(container.NewPadded(sectionHeader(title)), nil, nil, nil, content),
+			),
+		)
+		drawer.Hide()
+		return drawer
+	}
+	codeContainer := newDrawer("Code Generator", codePreviewContainer)
 
-	// Drawer on the right edge, hidden by default; the spacer fixes its width
-	drawerSpacer := canvas.NewRectangle(color.Transparent)
-	drawerSpacer.SetMinSize(fyne.NewSize(380, 0))
-	codeContainer := container.NewStack(
-		drawerSpacer,
-		container.NewBorder(nil, nil, widget.NewSeparator(), nil,
-			container.NewBorder(container.NewPadded(codeGenTitle), nil, nil, nil, codePreviewContainer),
-		),
-	)
-	codeContainer.Hide()
+	// Preview drawer: the resolved request exactly as Send builds it
+	previewContent, refreshPreview := g.previewPanel(request)
+	previewContainer := newDrawer("Request Preview", previewContent)
 
 	var requestArea *fyne.Container
-	var codeIconTappable *widget.Button
-	codeIconTappable = widget.NewButtonWithIcon("", theme.NewThemedResource(resourceCodeSvg), func() {
-		if codeContainer.Visible() {
-			codeContainer.Hide()
-			codeIconTappable.Importance = widget.LowImportance
-		} else {
-			// Regenerate so the drawer reflects the current request state
-			languageSelect.OnChanged(languageSelect.Selected)
-			codeContainer.Show()
-			codeIconTappable.Importance = widget.MediumImportance
+	var codeIconTappable, previewBtn *widget.Button
+	toggleDrawer := func(drawer *fyne.Container, refresh func()) {
+		show := !drawer.Visible()
+		for d, btn := range map[*fyne.Container]*widget.Button{codeContainer: codeIconTappable, previewContainer: previewBtn} {
+			if show && d == drawer {
+				// Regenerate so the drawer reflects the current request state
+				refresh()
+				d.Show()
+				btn.Importance = widget.MediumImportance
+			} else {
+				d.Hide()
+				btn.Importance = widget.LowImportance
+			}
+			btn.Refresh()
 		}
-		codeIconTappable.Refresh()
 		requestArea.Refresh()
+	}
+
+	codeIconTappable = widget.NewButtonWithIcon("", theme.NewThemedResource(resourceCodeSvg), func() {
+		toggleDrawer(codeContainer, func() { languageSelect.OnChanged(languageSelect.Selected) })
 	})
 	codeIconTappable.Importance = widget.LowImportance
 
-	requestArea = container.NewBorder(nil, nil, nil, codeContainer, container.NewStack(
+	previewBtn = widget.NewButtonWithIcon("", theme.VisibilityIcon(), func() {
+		toggleDrawer(previewContainer, refreshPreview)
+	})
+	previewBtn.Importance = widget.LowImportance
+
+	requestArea = container.NewBorder(nil, nil, nil, container.NewStack(codeContainer, previewContainer), container.NewStack(
 		container.NewAppTabs(
 			container.NewTabItem("Query", queryContainer),
 			container.NewTabItem("Headers", headerContainer),
@@ -503,7 +877,7 @@ func (g *gui) makeRequestUI(request *core.Request) fyne.CanvasObject {
 			container.NewTabItem("Body", bodyContainer),
 			container.NewTabItem("Settings", settingsContainer),
 		),
-		container.NewBorder(container.NewBorder(nil, nil, nil, codeIconTappable), nil, nil, nil),
+		container.NewBorder(container.NewBorder(nil, nil, nil, container.NewHBox(previewBtn, codeIconTappable)), nil, nil, nil),
 	))
 
 	return requestArea
@@ -515,9 +889,9 @@ func (g *gui) queryBlock(queries *[]core.FormType) fyne.CanvasObject {
 	list = widget.NewList(func() int {
 		return len(*queries)
 	}, func() fyne.CanvasObject {
-		parameterEntry := widget.NewEntry()
+		parameterEntry := g.newAppEntry()
 		parameterEntry.SetPlaceHolder("Parameter")
-		valueEntry := widget.NewEntry()
+		valueEntry := g.newAppEntry()
 		valueEntry.SetPlaceHolder("Value")
 
 		return container.NewBorder(nil, nil,
@@ -564,7 +938,7 @@ func (g *gui) queryBlock(queries *[]core.FormType) fyne.CanvasObject {
 
 		entryCtx, _ := ctx.Objects[0].(*fyne.Container)
 
-		parameter := entryCtx.Objects[0].(*widget.Entry)
+		parameter := entryCtx.Objects[0].(*appEntry)
 		parameter.OnChanged = nil
 		parameter.SetText((*queries)[lii].Key)
 		parameter.OnChanged = func(s string) {
@@ -583,7 +957,7 @@ func (g *gui) queryBlock(queries *[]core.FormType) fyne.CanvasObject {
 
 			g.updateURL(queries)
 		}
-		value := entryCtx.Objects[1].(*widget.Entry)
+		value := entryCtx.Objects[1].(*appEntry)
 		value.OnChanged = nil
 		value.SetText((*queries)[lii].Value)
 		value.OnChanged = func(s string) {
@@ -669,9 +1043,9 @@ func (g *gui) headerBlock(headers *[]core.FormType) fyne.CanvasObject {
 	list = widget.NewList(func() int {
 		return len(*headers)
 	}, func() fyne.CanvasObject {
-		parameterEntry := widget.NewEntry()
+		parameterEntry := g.newAppEntry()
 		parameterEntry.SetPlaceHolder("Header")
-		valueEntry := widget.NewEntry()
+		valueEntry := g.newAppEntry()
 		valueEntry.SetPlaceHolder("Value")
 
 		return container.NewBorder(nil, nil,
@@ -707,7 +1081,7 @@ func (g *gui) headerBlock(headers *[]core.FormType) fyne.CanvasObject {
 
 		entryCtx, _ := ctx.Objects[0].(*fyne.Container)
 
-		parameter := entryCtx.Objects[0].(*widget.Entry)
+		parameter := entryCtx.Objects[0].(*appEntry)
 		parameter.OnChanged = nil
 		parameter.SetText((*headers)[lii].Key)
 		parameter.OnChanged = func(s string) {
@@ -717,7 +1091,7 @@ func (g *gui) headerBlock(headers *[]core.FormType) fyne.CanvasObject {
 			}
 		}
 
-		value := entryCtx.Objects[1].(*widget.Entry)
+		value := entryCtx.Objects[1].(*appEntry)
 		value.OnChanged = nil
 		value.SetText((*headers)[lii].Value)
 		value.OnChanged = func(s string) {
@@ -731,15 +1105,18 @@ func (g *gui) headerBlock(headers *[]core.FormType) fyne.CanvasObject {
 	return list
 }
 
-func (g *gui) formBlock(fields *[]core.FormType) fyne.CanvasObject {
+// formBlock edits key/value rows. Body form rows get a file toggle;
+// environment variables (secrets) get a secret toggle instead, which masks
+// the value and keeps it in the vault.
+func (g *gui) formBlock(fields *[]core.FormType, secrets bool) fyne.CanvasObject {
 
 	var list *widget.List
 	list = widget.NewList(func() int {
 		return len(*fields)
 	}, func() fyne.CanvasObject {
-		parameterEntry := widget.NewEntry()
+		parameterEntry := g.newAppEntry()
 		parameterEntry.SetPlaceHolder("Key")
-		valueEntry := widget.NewEntry()
+		valueEntry := g.newAppEntry()
 		valueEntry.SetPlaceHolder("Value")
 
 		return container.NewBorder(nil, nil,
@@ -764,32 +1141,31 @@ func (g *gui) formBlock(fields *[]core.FormType) fyne.CanvasObject {
 
 		btns := ctx.Objects[2].(*fyne.Container)
 
-		// File toggle: pick a file to make this a file row (value = path),
-		// tap again to clear back to a text field. Only "Form" (multipart)
-		// sends file rows; URL Encoded skips them at send time.
-		fileBtn := btns.Objects[0].(*widget.Button)
-		if (*fields)[lii].IsFile {
-			fileBtn.SetIcon(theme.CancelIcon())
+		// Secret toggle (environment variables): masks the value and keeps
+		// it in the vault instead of environments.json.
+		toggleBtn := btns.Objects[0].(*widget.Button)
+		if secrets {
+			if (*fields)[lii].Secret {
+				toggleBtn.SetIcon(theme.VisibilityOffIcon())
+			} else {
+				toggleBtn.SetIcon(theme.VisibilityIcon())
+			}
+			toggleBtn.OnTapped = func() {
+				(*fields)[lii].Secret = !(*fields)[lii].Secret
+				list.Refresh()
+			}
 		} else {
-			fileBtn.SetIcon(theme.FileIcon())
-		}
-		fileBtn.OnTapped = func() {
+			// Part menu: attach files to make this a file row, set the
+			// part's Content-Type. Only "Form" (multipart) sends file rows;
+			// URL Encoded skips them at send time.
 			if (*fields)[lii].IsFile {
-				(*fields)[lii].IsFile = false
-				(*fields)[lii].Value = ""
-				list.Refresh()
-				return
+				toggleBtn.SetIcon(theme.MoreHorizontalIcon())
+			} else {
+				toggleBtn.SetIcon(theme.FileIcon())
+			}
+			toggleBtn.OnTapped = func() {
+				g.formPartMenu(&(*fields)[lii], list.Refresh, toggleBtn)
 			}
-
-			dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
-				if err != nil || rc == nil {
-					return
-				}
-				rc.Close() // only the path is needed; SendRequest opens it fresh
-				(*fields)[lii].IsFile = true
-				(*fields)[lii].Value = rc.URI().Path()
-				list.Refresh()
-			}, *g.Window)
 		}
 
 		btn := btns.Objects[1].(*widget.Button)
@@ -808,7 +1184,7 @@ func (g *gui) formBlock(fields *[]core.FormType) fyne.CanvasObject {
 
 		entryCtx, _ := ctx.Objects[0].(*fyne.Container)
 
-		parameter := entryCtx.Objects[0].(*widget.Entry)
+		parameter := entryCtx.Objects[0].(*appEntry)
 		parameter.OnChanged = nil
 		parameter.SetText((*fields)[lii].Key)
 		parameter.OnChanged = func(s string) {
@@ -818,14 +1194,16 @@ func (g *gui) formBlock(fields *[]core.FormType) fyne.CanvasObject {
 			}
 		}
 
-		value := entryCtx.Objects[1].(*widget.Entry)
+		value := entryCtx.Objects[1].(*appEntry)
 		value.OnChanged = nil
-		value.SetText((*fields)[lii].Value)
-		// File rows show the picked path read-only; recycled rows must be
+		value.Password = (*fields)[lii].Secret
+		// File rows show the picked files read-only; recycled rows must be
 		// re-enabled explicitly.
 		if (*fields)[lii].IsFile {
+			value.SetText(fileRowLabel((*fields)[lii]))
 			value.Disable()
 		} else {
+			value.SetText((*fields)[lii].Value)
 			value.Enable()
 		}
 		value.OnChanged = func(s string) {
@@ -838,3 +1216,74 @@ func (g *gui) formBlock(fields *[]core.FormType) fyne.CanvasObject {
 
 	return list
 }
+
+// fileRowLabel is what a file row shows in place of its paths:
+// "a.bin, b.json · image/png".
+func fileRowLabel(f core.FormType) string {
+	var names []string
+	for _, p := range f.Files() {
+		names = append(names, filepath.Base(p))
+	}
+
+	label := strings.Join(names, ", ")
+	if f.ContentType != "" {
+		label += " · " + f.ContentType
+	}
+	return label
+}
+
+// formPartMenu pops up a multipart row's options under anchor. The picked
+// files are only recorded as paths; SendRequest streams them at send time.
+func (g *gui) formPartMenu(row *core.FormType, refresh func(), anchor fyne.CanvasObject) {
+	pickFile := func() {
+		dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+			if err != nil || rc == nil {
+				return
+			}
+			rc.Close()
+
+			if row.IsFile && row.Value != "" {
+				row.Value += "\n" + rc.URI().Path()
+			} else {
+				row.IsFile, row.Value = true, rc.URI().Path()
+			}
+			refresh()
+		}, *g.Window)
+	}
+
+	contentType := func() {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("application/octet-stream")
+		entry.SetText(row.ContentType)
+
+		dialog.NewForm("Part Content-Type", "Save", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Content-Type", entry),
+		}, func(ok bool) {
+			if ok {
+				row.ContentType = strings.TrimSpace(entry.Text)
+				refresh()
+			}
+		}, *g.Window).Show()
+	}
+
+	var items []*fyne.MenuItem
+	if row.IsFile {
+		items = append(items,
+			fyne.NewMenuItem("Add File…", pickFile),
+			fyne.NewMenuItem("Content Type…", contentType),
+			fyne.NewMenuItem("Remove Files", func() {
+				row.IsFile, row.Value = false, ""
+				refresh()
+			}),
+		)
+	} else {
+		items = append(items,
+			fyne.NewMenuItem("Attach File…", pickFile),
+			fyne.NewMenuItem("Content Type…", contentType),
+		)
+	}
+
+	c := fyne.CurrentApp().Driver().CanvasForObject(anchor)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(anchor).AddXY(0, anchor.Size().Height)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), c, pos)
+}
@@ -42,3 +42,10 @@ func TestSyncQueryParams(t *testing.T) {
 		t.Errorf("want 4 rows, got %d: %+v", len(rows), rows)
 	}
 }
+
+func TestFileRowLabel(t *testing.T) {
+	row := core.FormType{IsFile: true, Value: "/tmp/a.bin\n\n/home/me/b.json\n", ContentType: "application/pdf"}
+	if got := fileRowLabel(row); got != "a.bin, b.json · application/pdf" {
+		t.Fatalf("label = %q", got)
+	}
+}
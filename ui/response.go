@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"cmp"
+	"encoding/hex"
+	"fmt"
 	"image/color"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -14,6 +18,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/vardanabhanot/myapi/core"
@@ -22,7 +27,8 @@ import (
 // saveFileName suggests a download name: the URL's last path segment, with
 // an extension from Content-Type when the segment doesn't already carry one.
 // Deliberate switch instead of mime.ExtensionsByType: on Windows that reads
-// the registry and can return junk like ".bat" for text/plain.
+// the registry and can return junk like ".bat" for text/plain. headers are
+// headerRows: the first Content-Type counts, a trailer never does.
 func saveFileName(rawURL string, headers []string) string {
 	name := "response"
 	if u, err := url.Parse(rawURL); err == nil {
@@ -118,6 +124,155 @@ func bodyKind(contentType, body string) string {
 	return "text"
 }
 
+// protoSummary is the line beside the status pill: the negotiated protocol,
+// then TLS version and cipher suite when there was a handshake.
+func protoSummary(proto string, info *core.TLSInfo) string {
+	parts := []string{proto}
+	if info != nil {
+		parts = append(parts, info.Version, info.CipherSuite)
+	}
+	return strings.Join(slices.DeleteFunc(parts, func(s string) bool { return s == "" }), " · ")
+}
+
+// attemptRows lists a retried send's tries as "key||value" rows for
+// keyValueTable; nil when the first try stuck.
+func attemptRows(attempts []core.Attempt) []string {
+	var rows []string
+	for i, a := range attempts {
+		key := fmt.Sprintf("#%d · %s", i+1, cmp.Or(a.Status, "Failed"))
+
+		var parts []string
+		if a.Err != "" {
+			parts = append(parts, a.Err)
+		}
+		if t := a.Timings; t.Total > 0 {
+			parts = append(parts, "Total "+t.Total.Round(time.Millisecond).String())
+			if t.TTFB > 0 {
+				parts = append(parts, "TTFB "+t.TTFB.Round(time.Millisecond).String())
+			}
+			if t.Reused {
+				parts = append(parts, "reused connection")
+			}
+		}
+		if a.Delay > 0 {
+			parts = append(parts, "then waited "+a.Delay.Round(time.Millisecond).String())
+		}
+
+		rows = append(rows, key+"||"+strings.Join(parts, " · "))
+	}
+	return rows
+}
+
+// wireHead caps the raw bytes a tab keeps; nil stays nil.
+func wireHead(wire []byte, n int) []byte {
+	if len(wire) > n {
+		return wire[:n:n]
+	}
+	return wire
+}
+
+// wireDump renders still-encoded bytes as a hex dump, capped well below
+// the text display limit: every byte costs about four cells.
+func wireDump(wire []byte) string {
+	const maxDumpBytes = 32 << 10
+	if len(wire) <= maxDumpBytes {
+		return hex.Dump(wire)
+	}
+	return hex.Dump(wire[:maxDumpBytes]) + fmt.Sprintf("\n... %d more bytes not shown. Save writes the decoded body.", len(wire)-maxDumpBytes)
+}
+
+// redirectChain is the hops a send followed plus the final response as the
+// last hop; nil when nothing redirected.
+func redirectChain(res *core.Response) []core.Hop {
+	if len(res.Redirects) == 0 {
+		return nil
+	}
+	return append(slices.Clone(res.Redirects), core.Hop{
+		URL:     res.URL,
+		Status:  res.Status,
+		Headers: res.Headers,
+		Timings: res.Timings,
+	})
+}
+
+// headerRows lists res's headers as "key||value" rows, one per value so
+// repeated headers stay separate, then its trailers marked as such.
+func headerRows(res *core.Response) []string {
+	rows := make([]string, 0, len(res.Headers)+len(res.Trailers))
+	for _, f := range res.Headers {
+		rows = append(rows, f.Name+"||"+f.Value)
+	}
+	for _, f := range res.Trailers {
+		rows = append(rows, f.Name+" (trailer)||"+f.Value)
+	}
+	return rows
+}
+
+// hopTitle is a chain row: "1. 301 Moved Permanently · 42ms — http://…".
+func hopTitle(i int, hop core.Hop) string {
+	return fmt.Sprintf("%d. %s · %s — %s", i+1, hop.Status, hop.Timings.Total.Round(time.Millisecond), hop.URL)
+}
+
+// redirectDialog lists the chain, each hop expanding to its headers.
+func (g *gui) redirectDialog(chain []core.Hop) {
+	acc := widget.NewAccordion()
+	for i, hop := range chain {
+		rows := container.New(layout.NewFormLayout())
+		for _, f := range hop.Headers {
+			key := widget.NewLabel(f.Name)
+			key.TextStyle.Bold = true
+			value := widget.NewLabel(f.Value)
+			value.Wrapping = fyne.TextWrapBreak
+			rows.Add(key)
+			rows.Add(value)
+		}
+		acc.Append(widget.NewAccordionItem(hopTitle(i, hop), rows))
+	}
+
+	d := dialog.NewCustom("Redirect Chain", "Close", container.NewVScroll(acc), *g.Window)
+	d.Resize(fyne.NewSize(720, 420))
+	d.Show()
+}
+
+// tlsRows flattens a response's TLS details into "key||value" rows for
+// keyValueTable. Plain http gets a single explanatory row.
+func tlsRows(info *core.TLSInfo) []string {
+	if info == nil {
+		return []string{"TLS||Not used (plain HTTP)"}
+	}
+
+	rows := []string{
+		"Protocol||" + info.Version,
+		"Cipher Suite||" + info.CipherSuite,
+	}
+
+	cert := func(prefix string, c core.CertInfo) {
+		rows = append(rows,
+			prefix+" Subject||"+c.Subject,
+			prefix+" Issuer||"+c.Issuer,
+			prefix+" Valid||"+c.NotBefore.Format(time.DateOnly)+" → "+c.NotAfter.Format(time.DateOnly),
+		)
+		if len(c.DNSNames) > 0 {
+			rows = append(rows, prefix+" Names||"+strings.Join(c.DNSNames, ", "))
+		}
+	}
+
+	if len(info.ServerCerts) > 0 {
+		cert("Server", info.ServerCerts[0])
+		for _, c := range info.ServerCerts[1:] {
+			rows = append(rows, "Chain||"+c.Subject)
+		}
+	}
+
+	if info.ClientCert != nil {
+		cert("Client", *info.ClientCert)
+	} else {
+		rows = append(rows, "Client Certificate||None sent")
+	}
+
+	return rows
+}
+
 // copyFeedbackButton is the one copy-to-clipboard control: icon button that
 // copies getText's result and flashes a confirm icon for 2s.
 func copyFeedbackButton(getText func() string) *widget.Button {
@@ -135,6 +290,51 @@ func copyFeedbackButton(getText func() string) *widget.Button {
 	return b
 }
 
+// rawLogTab shows the last send's round trips as they went out and came
+// back — redirects and retries included — with copy and save, which keep
+// the captured bytes exact.
+func (g *gui) rawLogTab(rawLog binding.String) fyne.CanvasObject {
+	grid := widget.NewTextGrid()
+	grid.Scroll = fyne.ScrollBoth
+	grid.ShowLineNumbers = true
+	rawLog.AddListener(binding.NewDataListener(func() {
+		s, _ := rawLog.Get()
+		grid.SetText(softWrap(strings.ReplaceAll(s, "\r\n", "\n"))) // the grid would draw the CRs
+	}))
+
+	logText := func() string {
+		s, _ := rawLog.Get() // not grid.Text(): that contains soft-wrap newlines, and lost the CRs
+		return s
+	}
+
+	saveBtn := widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		text := logText()
+		if text == "" {
+			return
+		}
+
+		fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			if _, err := writer.Write([]byte(text)); err != nil {
+				dialog.NewError(err, *g.Window).Show()
+			}
+		}, *g.Window)
+		fileSave.SetFileName("raw-log.txt")
+		fileSave.Show()
+	})
+	saveBtn.Importance = widget.LowImportance
+
+	return container.NewBorder(
+		container.NewBorder(nil, nil, nil, container.NewHBox(copyFeedbackButton(logText), saveBtn)),
+		nil, nil, nil,
+		grid,
+	)
+}
+
 // safeCut truncates s to at most max bytes without splitting a rune.
 func safeCut(s string, max int) string {
 	if len(s) <= max {
@@ -241,7 +441,22 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 
 	headerMap, _ := bindings.headers.Get() // render() reads Content-Type from it
 	headerTable := keyValueTable(bindings.headers)
+	// Go doesn't keep the order between names; only an HTTP/1 connection
+	// the wire log captured gives it back, so say when it's missing.
+	sortedNote := widget.NewLabel("Sorted by name: the order received isn't available over HTTP/2, HTTP/3 or HTTPS through a proxy")
+	sortedNote.Importance = widget.LowImportance
+	sortedNote.Hide()
+	bindings.sorted.AddListener(binding.NewDataListener(func() {
+		if sorted, _ := bindings.sorted.Get(); sorted && bindings.headers.Length() > 0 {
+			sortedNote.Show()
+		} else {
+			sortedNote.Hide()
+		}
+	}))
 	cookieTable := keyValueTable(bindings.cookies)
+	manageCookies := widget.NewButtonWithIcon("Cookie Jar", theme.StorageIcon(), g.cookiesDialog)
+	manageCookies.Importance = widget.LowImportance
+	tlsTable := keyValueTable(bindings.tls)
 
 	copyIcon := copyFeedbackButton(func() string {
 		original, _ := bindings.body.Get() // not responseTab.Text(): that contains soft-wrap newlines
@@ -249,9 +464,14 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 	})
 	copyIcon.Hide()
 
-	// Save-to-file. ponytail: writes the retained body (2MB cap, 4MB read
-	// cap) — stream-to-file download is a later feature.
+	// Save-to-file: the retained body (2MB cap, 4MB read cap), or in
+	// download mode a copy of the complete downloaded file.
 	saveIcon := widget.NewButtonWithIcon("", theme.DownloadIcon(), func() {
+		if file, _ := bindings.file.Get(); file != "" {
+			g.copyFileTo(file, saveFileName(request.URL, headerMap))
+			return
+		}
+
 		body, _ := bindings.body.Get()
 		if body == "" {
 			return
@@ -316,6 +536,49 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 		statusPill.Refresh()
 	}))
 
+	protoLabel := widget.NewLabelWithData(bindings.proto)
+	protoLabel.Importance = widget.LowImportance
+
+	// Shown only when the send was redirected; opens the chain
+	redirectsBtn := widget.NewButtonWithIcon("", theme.MailForwardIcon(), func() {
+		v, _ := bindings.redirects.Get()
+		if chain, ok := v.([]core.Hop); ok && len(chain) > 0 {
+			g.redirectDialog(chain)
+		}
+	})
+	redirectsBtn.Importance = widget.LowImportance
+	redirectsBtn.Hide()
+	bindings.redirects.AddListener(binding.NewDataListener(func() {
+		v, _ := bindings.redirects.Get()
+		if chain, _ := v.([]core.Hop); len(chain) > 0 {
+			label := fmt.Sprintf("%d redirects", len(chain)-1)
+			if len(chain) == 2 {
+				label = "1 redirect"
+			}
+			redirectsBtn.SetText(label)
+			redirectsBtn.Show()
+		} else {
+			redirectsBtn.Hide()
+		}
+	}))
+
+	// Shown only after a retried send; lists every try
+	attemptsBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		d := dialog.NewCustom("Attempts", "Close", keyValueTable(bindings.attempts), *g.Window)
+		d.Resize(fyne.NewSize(640, 320))
+		d.Show()
+	})
+	attemptsBtn.Importance = widget.LowImportance
+	attemptsBtn.Hide()
+	bindings.attempts.AddListener(binding.NewDataListener(func() {
+		if n := bindings.attempts.Length(); n > 0 {
+			attemptsBtn.SetText(fmt.Sprintf("%d attempts", n))
+			attemptsBtn.Show()
+		} else {
+			attemptsBtn.Hide()
+		}
+	}))
+
 	// No ThemeOverride wrapper: each ThemeOverride mints a fresh font-cache
 	// scope on every apply/refresh and Fyne never evicts it, so wrapping
 	// refreshing widgets leaks font faces (the response area refreshes on
@@ -326,8 +589,11 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Response", container.NewStack(responseTab, imageHolder)),
-		container.NewTabItem("Headers", headerTable),
-		container.NewTabItem("Cookies", cookieTable),
+		container.NewTabItem("Headers", container.NewBorder(sortedNote, nil, nil, nil, headerTable)),
+		container.NewTabItem("Cookies", container.NewBorder(nil, container.NewBorder(nil, nil, nil, manageCookies), nil, nil, cookieTable)),
+		container.NewTabItem("TLS", tlsTable),
+		container.NewTabItem("JWT", g.jwtList(bindings.jwts)),
+		container.NewTabItem("Raw", g.rawLogTab(bindings.rawLog)),
 	)
 
 	bindings.headers.AddListener(binding.NewDataListener(func() {
@@ -354,6 +620,8 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 
 	showRaw := false
 	var rawToggle *widget.Button
+	showWire := false
+	var wireToggle *widget.Button
 
 	render := func() {
 		responseBodyString, _ := bindings.body.Get()
@@ -370,6 +638,23 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 		responsePlaceholder.Hide()
 		tabs.Show()
 
+		// Compressed responses can show the bytes as they came off the wire
+		v, _ := bindings.wire.Get()
+		wire, _ := v.([]byte)
+		if len(wire) == 0 {
+			wireToggle.Hide()
+		} else {
+			wireToggle.Show()
+			if showWire {
+				imageHolder.Objects = nil
+				imageHolder.Hide()
+				responseTab.Show()
+				responseTab.SetText(wireDump(wire))
+				search.contentChanged()
+				return
+			}
+		}
+
 		var contentType string
 		for _, h := range headerMap {
 			if k, v, ok := strings.Cut(h, "||"); ok && strings.EqualFold(k, "Content-Type") {
@@ -396,7 +681,12 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 		}
 
 		if kind == "image" {
+			// Download mode keeps only the head in the body; the file has
+			// the whole image.
 			img := canvas.NewImageFromResource(fyne.NewStaticResource("response", []byte(responseBodyString)))
+			if file, _ := bindings.file.Get(); file != "" {
+				img = canvas.NewImageFromFile(file)
+			}
 			img.FillMode = canvas.ImageFillContain
 			imageHolder.Objects = []fyne.CanvasObject{img}
 			imageHolder.Show()
@@ -461,6 +751,19 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 	rawToggle.Importance = widget.LowImportance
 	rawToggle.Hide()
 
+	wireToggle = widget.NewButton("Compressed", func() {
+		showWire = !showWire
+		if showWire {
+			wireToggle.Importance = widget.HighImportance
+		} else {
+			wireToggle.Importance = widget.LowImportance
+		}
+		wireToggle.Refresh()
+		render()
+	})
+	wireToggle.Importance = widget.LowImportance
+	wireToggle.Hide()
+
 	g.tabs[request.ID].bodyListner = binding.NewDataListener(render)
 	bindings.body.AddListener(g.tabs[request.ID].bodyListner)
 
@@ -512,13 +815,16 @@ func (g *gui) makeResponseUI(request *core.Request) fyne.CanvasObject {
 	toolbar := container.NewBorder(nil, nil, nil,
 		container.NewHBox(
 			container.NewCenter(statusPill),
+			protoLabel,
+			attemptsBtn,
+			redirectsBtn,
 			timeLabel,
 			widget.NewLabelWithData(bindings.size),
-			rawToggle, wsToggle, searchIcon, copyIcon, saveIcon, collapseBtn,
+			rawToggle, wireToggle, wsToggle, searchIcon, copyIcon, saveIcon, collapseBtn,
 		),
 	)
 
-	rc = NewResponseContainer(container.NewBorder(search.bar, nil, nil, nil, container.NewStack(
+	rc = NewResponseContainer(container.NewBorder(search.bar, g.transferBar(bindings.progress, bindings.file), nil, nil, container.NewStack(
 		stackedTabs,
 		container.NewBorder(toolbar, nil, nil, nil,
 			waterfallAnchor,
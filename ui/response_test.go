@@ -1,9 +1,13 @@
 package ui
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
+
+	"github.com/vardanabhanot/myapi/core"
 )
 
 func TestSafeCut(t *testing.T) {
@@ -59,3 +63,85 @@ func TestSoftWrap(t *testing.T) {
 		}
 	}
 }
+
+func TestProtoSummary(t *testing.T) {
+	info := &core.TLSInfo{Version: "TLS 1.3", CipherSuite: "TLS_AES_128_GCM_SHA256"}
+	if got := protoSummary("HTTP/2.0", info); got != "HTTP/2.0 · TLS 1.3 · TLS_AES_128_GCM_SHA256" {
+		t.Errorf("tls: %q", got)
+	}
+	if got := protoSummary("HTTP/1.1", nil); got != "HTTP/1.1" {
+		t.Errorf("plain http: %q", got)
+	}
+}
+
+func TestAttemptRows(t *testing.T) {
+	if rows := attemptRows(nil); rows != nil {
+		t.Fatalf("no retries should give no rows: %q", rows)
+	}
+
+	rows := attemptRows([]core.Attempt{
+		{Err: "connection refused", Delay: 500 * time.Millisecond},
+		{Status: "503 Service Unavailable", Timings: core.Timings{Total: 120 * time.Millisecond, TTFB: 100 * time.Millisecond}, Delay: time.Second},
+		{Status: "200 OK", Timings: core.Timings{Total: 80 * time.Millisecond, Reused: true}},
+	})
+	want := []string{
+		"#1 · Failed||connection refused · then waited 500ms",
+		"#2 · 503 Service Unavailable||Total 120ms · TTFB 100ms · then waited 1s",
+		"#3 · 200 OK||Total 80ms · reused connection",
+	}
+	if !slices.Equal(rows, want) {
+		t.Fatalf("rows:\n%q\nwant\n%q", rows, want)
+	}
+}
+
+func TestRedirectChain(t *testing.T) {
+	if chain := redirectChain(&core.Response{Status: "200 OK"}); chain != nil {
+		t.Fatalf("no redirects should give no chain: %+v", chain)
+	}
+
+	res := &core.Response{
+		URL:     "https://b.test/end",
+		Status:  "200 OK",
+		Timings: core.Timings{Total: 20 * time.Millisecond},
+		Redirects: []core.Hop{
+			{URL: "http://a.test/", Status: "301 Moved Permanently", Timings: core.Timings{Total: 42 * time.Millisecond}},
+		},
+	}
+	chain := redirectChain(res)
+	if len(chain) != 2 || chain[1].URL != res.URL {
+		t.Fatalf("chain: %+v", chain)
+	}
+	if got := hopTitle(0, chain[0]); got != "1. 301 Moved Permanently · 42ms — http://a.test/" {
+		t.Errorf("hop title: %q", got)
+	}
+	if got := hopTitle(1, chain[1]); got != "2. 200 OK · 20ms — https://b.test/end" {
+		t.Errorf("final title: %q", got)
+	}
+}
+
+func TestHeaderRows(t *testing.T) {
+	res := &core.Response{
+		Headers:  core.Header{{Name: "Set-Cookie", Value: "a=1"}, {Name: "Set-Cookie", Value: "b=2"}},
+		Trailers: core.Header{{Name: "X-Checksum", Value: "abc"}},
+	}
+	want := []string{"Set-Cookie||a=1", "Set-Cookie||b=2", "X-Checksum (trailer)||abc"}
+	if got := headerRows(res); !slices.Equal(got, want) {
+		t.Fatalf("rows: %q", got)
+	}
+}
+
+func TestWireDump(t *testing.T) {
+	if wireHead(nil, 10) != nil {
+		t.Fatal("nil wire should stay nil")
+	}
+	if got := wireHead(make([]byte, 20), 10); len(got) != 10 || cap(got) != 10 {
+		t.Fatalf("wireHead: len=%d cap=%d", len(got), cap(got))
+	}
+
+	if got := wireDump([]byte{0x1f, 0x8b}); !strings.HasPrefix(got, "00000000  1f 8b") {
+		t.Errorf("dump: %q", got)
+	}
+	if got := wireDump(make([]byte, 40<<10)); !strings.Contains(got, "8192 more bytes not shown") {
+		t.Errorf("long dump should note the cut: %q", got[len(got)-80:])
+	}
+}
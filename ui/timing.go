@@ -85,6 +85,14 @@ func waterfall(t core.Timings) fyne.CanvasObject {
 	scale := barArea / float32(t.Total)
 
 	rows := []fyne.CanvasObject{}
+	if t.Reused {
+		// No DNS/Connect/TLS happened: one row says why instead of three
+		// empty bars
+		phases = phases[3:]
+		note := widget.NewLabel("Reused keep-alive connection")
+		note.Importance = widget.LowImportance
+		rows = append(rows, widget.NewLabel("Connection"), note, widget.NewLabel(""))
+	}
 	for _, p := range phases {
 		if p.dur < 0 {
 			p.dur = 0
@@ -112,6 +120,12 @@ func waterfall(t core.Timings) fyne.CanvasObject {
 		)
 	}
 
+	if t.RemoteAddr != "" {
+		addr := widget.NewLabel(t.RemoteAddr)
+		addr.Importance = widget.LowImportance
+		rows = append(rows, widget.NewLabel("Remote"), addr, widget.NewLabel(""))
+	}
+
 	total := widget.NewLabel("Total")
 	total.TextStyle.Bold = true
 	totalDur := widget.NewLabel(t.Total.Round(time.Millisecond / 10).String())
@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"weak"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// maxSuggestions caps the {{ completion menu: a PopUpMenu can't scroll.
+const maxSuggestions = 20
+
+var (
+	varsResolvedIcon   = theme.NewSuccessThemedResource(theme.ConfirmIcon())
+	varsUnresolvedIcon = theme.NewErrorThemedResource(theme.ErrorIcon())
+)
+
+// varBadge is an entry's placeholder check mark. Hovering a red one lists
+// every placeholder that won't resolve.
+type varBadge struct {
+	widget.Icon
+	g       *gui
+	missing []string // from the last checkVars
+}
+
+func newVarBadge(g *gui) *varBadge {
+	b := &varBadge{g: g}
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+var _ desktop.Hoverable = (*varBadge)(nil)
+
+func (b *varBadge) MouseIn(*desktop.MouseEvent)    { b.g.showTip(b, unresolvedLines(b.missing)) }
+func (b *varBadge) MouseMoved(*desktop.MouseEvent) {}
+func (b *varBadge) MouseOut()                      { b.g.hideVarTip() }
+
+// checkVars flags e's placeholders in its badge: nothing without any, a
+// green check when they all resolve, a red mark when some won't — hovering
+// the mark says which.
+// ponytail: one badge per entry; colouring each {{var}} in place needs a
+// custom text renderer, as Entry draws its text in one style.
+func (e *appEntry) checkVars() {
+	e.badge.missing = core.UnresolvedIn(e.Text)
+
+	var icon fyne.Resource
+	switch {
+	case len(core.Placeholders(e.Text)) == 0:
+	case len(e.badge.missing) == 0:
+		icon = varsResolvedIcon
+	default:
+		icon = varsUnresolvedIcon
+	}
+
+	if e.badge.Resource != icon {
+		e.badge.SetResource(icon)
+	}
+}
+
+// withBadge lays e out with its badge beside it rather than inside, for
+// entries that are or may become password ones: their ActionItem is the
+// reveal toggle.
+func (e *appEntry) withBadge() fyne.CanvasObject {
+	e.badgeOutside = true
+	return container.NewBorder(nil, nil, nil, e.badge, e)
+}
+
+// unresolvedLines lists missing's placeholders once each, capped for a
+// tip or dialog.
+func unresolvedLines(missing []string) []string {
+	missing = slices.Clone(missing)
+	slices.Sort(missing)
+	missing = slices.Compact(missing)
+	if len(missing) > maxTipLines {
+		missing = append(missing[:maxTipLines], "…")
+	}
+	return missing
+}
+
+// recheckVars redraws every live entry's badge after the variables change.
+// Entries are held weakly so closed tabs' ones can still be collected.
+func (g *gui) recheckVars() {
+	live := g.varEntries[:0]
+	for _, p := range g.varEntries {
+		if e := p.Value(); e != nil {
+			e.checkVars()
+			live = append(live, p)
+		}
+	}
+	clear(g.varEntries[len(live):])
+	g.varEntries = live
+}
+
+func (g *gui) trackVars(e *appEntry) {
+	e.badge = newVarBadge(g)
+	g.varEntries = append(g.varEntries, weak.Make(e))
+}
+
+// textBeforeCursor is e's text up to the cursor.
+func (e *appEntry) textBeforeCursor() string {
+	r := []rune(e.Text)
+	return string(r[:min(e.CursorTextOffset(), len(r))])
+}
+
+// suggestVars offers the variables and built-ins completing the "{{name"
+// being typed at the cursor, on typing "{{" or Ctrl+Space. The menu takes
+// the keyboard; picking one types the rest of the placeholder.
+func (e *appEntry) suggestVars() {
+	c := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if c == nil || e.Password {
+		return
+	}
+
+	partial, ok := openPlaceholder(e.textBeforeCursor())
+	if !ok {
+		return
+	}
+	names := completeVars(partial, core.VarNames(), core.FuncNames())
+	if len(names) == 0 {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	for i, name := range names {
+		if i == maxSuggestions {
+			items = append(items, &fyne.MenuItem{Label: "…", Disabled: true})
+			break
+		}
+		if i > 0 && strings.HasPrefix(name, "$") && !strings.HasPrefix(names[i-1], "$") {
+			items = append(items, fyne.NewMenuItemSeparator())
+		}
+		items = append(items, fyne.NewMenuItem(name, func() {
+			c.Focus(e)
+			// Retyped whole: the match ignores case
+			for range []rune(partial) {
+				e.Entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyBackspace})
+			}
+			for _, r := range name + "}}" {
+				e.Entry.TypedRune(r)
+			}
+			e.checkVars()
+		}))
+	}
+
+	menu := widget.NewPopUpMenu(fyne.NewMenu("", items...), c)
+	menu.OnDismiss = func() {
+		menu.Hide()
+		c.Focus(e)
+	}
+
+	// Under the cursor; CursorPosition ignores scrolling, so keep it inside
+	pos := e.CursorPosition()
+	pos.X = min(pos.X, e.Size().Width)
+	pos.Y = min(pos.Y+fyne.MeasureText("M", theme.TextSize(), e.TextStyle).Height+theme.InnerPadding(), e.Size().Height)
+	menu.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(e).Add(pos))
+}
+
+// openPlaceholder returns the name typed so far after the last unclosed
+// "{{" in before, if it ends inside one.
+func openPlaceholder(before string) (partial string, ok bool) {
+	i := strings.LastIndex(before, "{{")
+	if i < 0 {
+		return "", false
+	}
+
+	partial = strings.TrimLeft(before[i+2:], " \t")
+	if strings.ContainsAny(partial, "{} \t\r\n") {
+		return "", false
+	}
+	return partial, true
+}
+
+// completeVars lists the vars, then the "$func" funcs, starting with
+// partial, ignoring case. A partial starting with "$" only matches funcs.
+func completeVars(partial string, vars, funcs []string) []string {
+	var names []string
+	prefix := strings.ToLower(partial)
+	for _, list := range [][]string{vars, funcs} {
+		for _, name := range list {
+			if strings.HasPrefix(strings.ToLower(name), prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// confirmUnresolved runs send, asking first when request would go out with
+// placeholders left literal. Strict mode fails the send itself instead, and
+// "Don't ask again" lasts as long as the tab.
+func (g *gui) confirmUnresolved(request *core.Request, send func()) {
+	if request.Settings.StrictVars || g.sendLiteral[request.ID] {
+		send()
+		return
+	}
+
+	missing := request.Unresolved()
+	if len(missing) == 0 {
+		send()
+		return
+	}
+	missing = unresolvedLines(missing)
+
+	msg := widget.NewLabel("These would be sent literally:\n\n" + strings.Join(missing, "\n"))
+	dontAsk := widget.NewCheck("Don't ask again for this tab", nil)
+
+	dialog.NewCustomConfirm("Unresolved Variables", "Send Anyway", "Cancel", container.NewVBox(msg, dontAsk), func(ok bool) {
+		if !ok {
+			return
+		}
+		if dontAsk.Checked {
+			if g.sendLiteral == nil {
+				g.sendLiteral = map[string]bool{}
+			}
+			g.sendLiteral[request.ID] = true
+		}
+		send()
+	}, *g.Window).Show()
+}
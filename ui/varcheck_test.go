@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+func TestVarCompletion(t *testing.T) {
+	for _, tc := range []struct {
+		before, partial string
+		ok              bool
+	}{
+		{"{{", "", true},
+		{"https://{{ho", "ho", true},
+		{"{{  $u", "$u", true},
+		{"{{host}}/", "", false},
+		{"{{$randomInt 1 ", "", false},
+		{"{{a\nb", "", false},
+		{"plain", "", false},
+	} {
+		partial, ok := openPlaceholder(tc.before)
+		if partial != tc.partial || ok != tc.ok {
+			t.Errorf("openPlaceholder(%q) = %q, %v", tc.before, partial, ok)
+		}
+	}
+
+	vars := []string{"baseUrl", "host", "Hostname"}
+	funcs := []string{"$base64", "$uuid"}
+	for partial, want := range map[string][]string{
+		"":   {"baseUrl", "host", "Hostname", "$base64", "$uuid"},
+		"ho": {"host", "Hostname"},
+		"ba": {"baseUrl"},
+		"$b": {"$base64"},
+		"x":  nil,
+	} {
+		if got := completeVars(partial, vars, funcs); !slices.Equal(got, want) {
+			t.Errorf("completeVars(%q) = %q, want %q", partial, got, want)
+		}
+	}
+}
+
+func TestVarBadge(t *testing.T) {
+	test.NewApp()
+	core.SetActiveVars(map[string]string{"host": "api.example.com"})
+	defer core.SetActiveVars(nil)
+
+	g := &gui{}
+	e := g.newAppEntry()
+	for text, want := range map[string]any{
+		"https://example.com":        nil,
+		"https://{{host}}/{{$uuid}}": varsResolvedIcon,
+		"https://{{host}}/{{path}}":  varsUnresolvedIcon,
+	} {
+		e.SetText(text)
+		if e.badge.Resource != want {
+			t.Errorf("%q: badge %v", text, e.badge.Resource)
+		}
+	}
+
+	// Defining the variable clears the mark without touching the entry
+	e.SetText("https://{{host}}/{{path}}")
+	core.SetActiveVars(map[string]string{"host": "api.example.com", "path": "v1"})
+	g.recheckVars()
+	if e.badge.Resource != varsResolvedIcon {
+		t.Errorf("after defining path: badge %v", e.badge.Resource)
+	}
+
+	// Password entries are checked too, and the mark lists each miss
+	e.Password = true
+	e.SetText("{{host}}:{{pass}}@{{user}}/{{pass}}")
+	if e.badge.Resource != varsUnresolvedIcon {
+		t.Errorf("password entry: badge %v", e.badge.Resource)
+	}
+	if got := unresolvedLines(e.badge.missing); len(got) != 2 || !strings.Contains(got[0], "pass") || !strings.Contains(got[1], "user") {
+		t.Errorf("unresolved: %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// varTip is the card shown while hovering an entry with {{var}}s: each
+// one's value and the scope it came from. Like the timing panel it lives
+// in a Stack over the window rather than a widget.PopUp, whose full-canvas
+// overlay would steal the hover and make it flicker.
+type varTip struct {
+	layer *fyne.Container // no layout: the card is placed by hand
+	card  *fyne.Container
+	text  *widget.Label
+}
+
+const maxTipLines = 8
+
+func newVarTip() *varTip {
+	bg := canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	bg.CornerRadius = 6
+	bg.StrokeColor = theme.Color(theme.ColorNameSeparator)
+	bg.StrokeWidth = 1
+
+	t := &varTip{text: widget.NewLabel("")}
+	t.card = container.NewStack(bg, t.text)
+	t.card.Hide()
+	t.layer = container.NewWithoutLayout(t.card)
+	return t
+}
+
+// showVarTip places the tip under entry, or hides it when entry has no
+// placeholders to explain.
+func (g *gui) showVarTip(entry *appEntry) {
+	if entry.Password {
+		g.hideVarTip()
+		return
+	}
+	g.showTip(entry, varTipLines(entry.Text, core.LookupVar, g.varSecret, g.secretValues()))
+}
+
+// showTip places the tip's lines under anchor; no lines hides it.
+func (g *gui) showTip(anchor fyne.CanvasObject, lines []string) {
+	if len(lines) == 0 {
+		g.hideVarTip()
+		return
+	}
+
+	t := g.varTip
+	t.text.SetText(strings.Join(lines, "\n"))
+
+	d := fyne.CurrentApp().Driver()
+	pos := d.AbsolutePositionForObject(anchor).Subtract(d.AbsolutePositionForObject(t.layer))
+	pos.Y += anchor.Size().Height + theme.Padding()
+
+	size := t.card.MinSize()
+	pos.X = max(0, min(pos.X, t.layer.Size().Width-size.Width))
+	// No room below: above the entry instead
+	if pos.Y+size.Height > t.layer.Size().Height {
+		pos.Y -= anchor.Size().Height + size.Height + 2*theme.Padding()
+	}
+
+	t.card.Resize(size)
+	t.card.Move(pos)
+	t.card.Show()
+}
+
+func (g *gui) hideVarTip() {
+	g.varTip.card.Hide()
+}
+
+// varSecret reports whether key's row in scope is a secret one, whose value
+// the tip masks.
+func (g *gui) varSecret(key string, scope core.Scope) bool {
+	var rows *[]core.FormType
+	switch scope {
+	case core.ScopeGlobal:
+		rows = g.envStore.Globals
+	case core.ScopeEnv:
+		if env := g.envStore.ActiveEnv(); env != nil {
+			rows = env.Variables
+		}
+	case core.ScopeSession:
+		rows = g.sessionVars
+	}
+	if rows == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(*rows, func(v core.FormType) bool {
+		return v.Checked && v.Key == key && v.Secret
+	})
+}
+
+// varTipLines describes each distinct placeholder in text: "{{host}} =
+// https://api.example.com (Environment)", secrets masked — a secret
+// variable whole, and any of secrets inside another's expanded value —
+// then whatever inside the value couldn't be resolved.
+func varTipLines(text string, lookup func(string) (core.VarInfo, bool), secret func(string, core.Scope) bool, secrets []string) []string {
+	var lines []string
+	seen := map[string]bool{}
+
+	for _, expr := range core.Placeholders(text) {
+		if seen[expr] {
+			continue
+		}
+		seen[expr] = true
+
+		if len(lines) >= maxTipLines {
+			lines = append(lines, "…")
+			break
+		}
+
+		if strings.HasPrefix(expr, "$") {
+			lines = append(lines, fmt.Sprintf("{{%s}}: built-in, filled in at send time", expr))
+			continue
+		}
+
+		info, ok := lookup(expr)
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("{{%s}}: not defined", expr))
+			continue
+		case secret(expr, info.Scope):
+			lines = append(lines, fmt.Sprintf("{{%s}} = •••••• (%s)", expr, info.Scope))
+		default:
+			value := strings.ReplaceAll(core.MaskSecrets(info.Value, secrets), "\n", " ")
+			if r := []rune(value); len(r) > 60 {
+				value = string(r[:60]) + "…"
+			}
+			lines = append(lines, fmt.Sprintf("{{%s}} = %s (%s)", expr, value, info.Scope))
+		}
+
+		for _, p := range info.Problems {
+			lines = append(lines, "    ⚠ "+p)
+		}
+	}
+
+	return lines
+}
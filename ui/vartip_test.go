@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/vardanabhanot/myapi/core"
+)
+
+func TestVarTipLines(t *testing.T) {
+	vars := map[string]core.VarInfo{
+		"host":  {Value: "https://api.example.com", Scope: core.ScopeEnv},
+		"token": {Value: "sk-123", Scope: core.ScopeGlobal},
+		"note":  {Value: "line one\nline two", Scope: core.ScopeSession},
+		"auth":  {Value: "Bearer sk-123", Scope: core.ScopeEnv}, // auth = Bearer {{token}}
+		"loop":  {Value: "{{loop}}", Scope: core.ScopeEnv, Problems: []string{"{{loop}} is circular: loop → loop"}},
+	}
+	lookup := func(key string) (core.VarInfo, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+	secret := func(key string, _ core.Scope) bool { return key == "token" }
+
+	got := varTipLines("{{host}}/{{ host }}?t={{token}}&n={{note}}&m={{missing}}&id={{$uuid}}&l={{loop}}&a={{auth}}", lookup, secret, []string{"sk-123"})
+	want := []string{
+		"{{host}} = https://api.example.com (Environment)",
+		"{{token}} = •••••• (Global)",
+		"{{note}} = line one line two (Session)",
+		"{{missing}}: not defined",
+		"{{$uuid}}: built-in, filled in at send time",
+		"{{loop}} = {{loop}} (Environment)",
+		"    ⚠ {{loop}} is circular: loop → loop",
+		"{{auth}} = Bearer •••••• (Environment)",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+
+	if got := varTipLines("no placeholders", lookup, secret, nil); got != nil {
+		t.Fatalf("plain text: %q", got)
+	}
+}
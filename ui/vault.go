@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/vardanabhanot/myapi/core"
+)
+
+// vaultStatus describes where secrets are kept, for the vault dialog.
+func vaultStatus(mode string, locked bool, err error) string {
+	switch {
+	case err != nil:
+		return "Locked. Secret variables and credentials stay blank: " + err.Error()
+	case locked:
+		return "Locked. Secret variables and credentials stay blank until you enter the master password."
+	case mode == core.VaultPassword:
+		return "Secrets are encrypted with your master password."
+	default:
+		return "Secrets are encrypted with a key held by the system keyring."
+	}
+}
+
+// vaultDialog unlocks the vault, or sets/removes its master password.
+func (g *gui) vaultDialog() {
+	status := widget.NewLabel(vaultStatus(core.VaultMode(), core.VaultLocked(), core.VaultError()))
+	status.Wrapping = fyne.TextWrapWord
+
+	password := widget.NewPasswordEntry()
+	confirm := widget.NewPasswordEntry()
+	confirm.SetPlaceHolder("Confirm")
+
+	var d *dialog.CustomDialog
+	var content *fyne.Container
+
+	if core.VaultLocked() && core.VaultMode() == core.VaultKeyring {
+		// Nothing to type: the keyring key is unreadable. Retry once it's
+		// been restored.
+		retryBtn := widget.NewButton("Retry", func() {
+			if err := core.UnlockVault(""); err != nil {
+				dialog.NewError(err, *g.Window).Show()
+				return
+			}
+			g.fillSecrets()
+			d.Hide()
+		})
+		retryBtn.Importance = widget.HighImportance
+
+		content = container.NewVBox(status, container.NewBorder(nil, nil, nil, retryBtn))
+	} else if core.VaultLocked() {
+		password.SetPlaceHolder("Master password")
+		unlock := func() {
+			if err := core.UnlockVault(password.Text); err != nil {
+				dialog.NewError(err, *g.Window).Show()
+				return
+			}
+			g.fillSecrets()
+			d.Hide()
+		}
+		password.OnSubmitted = func(string) { unlock() }
+
+		unlockBtn := widget.NewButton("Unlock", unlock)
+		unlockBtn.Importance = widget.HighImportance
+
+		content = container.NewVBox(status, password, container.NewBorder(nil, nil, nil, unlockBtn))
+	} else {
+		password.SetPlaceHolder("New master password")
+
+		setBtn := widget.NewButton("Set Master Password", func() {
+			if password.Text == "" || password.Text != confirm.Text {
+				dialog.NewError(errors.New("passwords are empty or don't match"), *g.Window).Show()
+				return
+			}
+			if err := core.SetVaultPassword(password.Text); err != nil {
+				dialog.NewError(err, *g.Window).Show()
+				return
+			}
+			d.Hide()
+		})
+		setBtn.Importance = widget.HighImportance
+
+		buttons := container.NewHBox(setBtn)
+		if core.VaultMode() == core.VaultPassword {
+			removeBtn := widget.NewButton("Use Keyring Instead", func() {
+				if err := core.SetVaultPassword(""); err != nil {
+					dialog.NewError(err, *g.Window).Show()
+					return
+				}
+				d.Hide()
+			})
+			buttons.Add(removeBtn)
+		}
+
+		content = container.NewVBox(status, password, confirm, container.NewBorder(nil, nil, nil, buttons))
+	}
+
+	d = dialog.NewCustom("Secrets Vault", "Close", content, *g.Window)
+	d.Resize(fyne.NewSize(440, 0))
+	d.Show()
+}
+
+// fillSecrets refills secrets loaded while the vault was locked. Open tabs
+// keep what their fields show; reopening picks the secrets up.
+func (g *gui) fillSecrets() {
+	g.envStore.FillSecrets()
+	g.applyVars()
+
+	g.certStore.FillSecrets()
+	core.SetHostCerts(g.certStore.Hosts)
+	if g.globalProxy.Pass == "" {
+		g.globalProxy.Pass = core.LoadGlobalProxy().Pass
+	}
+	g.applyProxy() // the environment's password may be back too
+
+	for _, c := range g.collections {
+		for _, r := range c.Requests {
+			r.FillSecrets()
+		}
+	}
+}